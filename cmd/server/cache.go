@@ -0,0 +1,44 @@
+package main
+
+import (
+	"graph-task-service/internal/config"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/repository/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newTaskCache puts the cache selected by cfg.Cache.Backend in front of repo.
+// opts carries the unit of work hooks of the repository. The returned
+// cleanup closes the Redis client.
+func newTaskCache(
+	cfg *config.Config,
+	repo domain.TaskRepository,
+	opts cache.Options,
+) (domain.TaskRepository, func(), error) {
+
+	var (
+		store   cache.Store
+		cleanup = func() {}
+	)
+
+	switch cfg.Cache.Backend {
+	case "memory":
+		store = cache.NewLRU(cfg.Cache.Size)
+	case "redis":
+		redisOpts, err := redis.ParseURL(cfg.Cache.RedisURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		client := redis.NewClient(redisOpts)
+		store = cache.NewRedis(client, cfg.Cache.KeyPrefix)
+		cleanup = func() { client.Close() }
+	default:
+		return repo, cleanup, nil
+	}
+
+	opts.TTL = cfg.Cache.TTL
+	opts.ListTTL = cfg.Cache.ListTTL
+
+	return cache.NewTaskRepository(repo, store, opts), cleanup, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graph-task-service/internal/config"
+	"graph-task-service/internal/repository/postgres"
+	"graph-task-service/migration"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `usage: server [flags] [command]
+
+commands:
+  (none)              run the HTTP server
+  config print        print the effective configuration, secrets masked
+  migrate up          apply all pending migrations
+  migrate down [N]    revert the last N migrations (default 1)
+  migrate redo        revert and re-apply the last migration
+  migrate status      list migrations and whether they are applied
+  export [flags]      write tasks as csv, jsonl or xlsx; export -h lists flags
+  import -from T FILE import a jira, trello or github export; import -h lists flags
+
+Flags override environment variables, which override the YAML file given
+with -config or CONFIG_FILE. Run with -h to list every flag.`
+
+// runCommand handles the subcommands that run instead of the server.
+func runCommand(cfg *config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(cfg, args[1:], os.Stdout)
+	case "export":
+		return runExport(cfg, args[1:], os.Stdout)
+	case "import":
+		return runImport(cfg, args[1:], os.Stdout)
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+// runConfig prints the configuration even when it is invalid, then reports
+// what Validate found.
+func runConfig(cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New(usage)
+	}
+
+	if err := config.Print(out, cfg); err != nil {
+		return err
+	}
+
+	return cfg.Validate()
+}
+
+func runMigrate(cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	if !cfg.Database.UsesPostgres() {
+		return fmt.Errorf("the %s driver creates its schema on start; migrations need a Postgres driver", cfg.Database.Driver)
+	}
+
+	db, err := postgres.NewDB(context.Background(), cfg.Database.URL, dbConfig(cfg))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := postgres.NewMigrator(db, migration.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Fprintf(out, "applied %03d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "no pending migrations")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Fprintf(out, "reverted %03d_%s\n", m.Version, m.Name)
+		}
+		return err
+
+	case "redo":
+		redone, err := migrator.Redo(ctx)
+		if redone != nil {
+			fmt.Fprintf(out, "redone %03d_%s\n", redone.Version, redone.Name)
+		}
+		return err
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printMigrationStatus(out, status)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], usage)
+	}
+}
+
+func printMigrationStatus(out io.Writer, status []postgres.MigrationStatus) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+
+	for _, s := range status {
+		state, appliedAt := "pending", ""
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		if s.Modified {
+			state = "modified"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"graph-task-service/internal/config"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/export"
+	"io"
+	"os"
+	"time"
+)
+
+// runExport writes the tasks matching the flags to a file or stdout, reading
+// the database directly rather than through a running server.
+func runExport(cfg *config.Config, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+
+	var (
+		format      = fs.String("format", "csv", "file format: csv, jsonl or xlsx")
+		columns     = fs.String("columns", "", "comma separated columns (default all)")
+		tz          = fs.String("tz", "UTC", "IANA time zone of timestamps")
+		status      = fs.String("status", "", "only tasks with this status")
+		assignee    = fs.String("assignee", "", "only tasks assigned to this user")
+		watcher     = fs.String("watcher", "", "only tasks watched by this user")
+		participant = fs.String("participant", "", "only tasks this user is assigned to or watching")
+		limit       = fs.Int("limit", 0, "maximum number of tasks (default all)")
+		offset      = fs.Int("offset", 0, "number of tasks to skip")
+		output      = fs.String("o", "", "output file (default stdout)")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	f, err := export.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	cols, err := export.ParseColumns(*columns)
+	if err != nil {
+		return err
+	}
+
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fmt.Errorf("invalid time zone %q", *tz)
+	}
+
+	if *limit < 0 || *offset < 0 {
+		return errors.New("limit and offset must not be negative")
+	}
+
+	filter := domain.TaskFilter{Limit: *limit, Offset: *offset}
+	if *status != "" {
+		s := domain.TaskStatus(*status)
+		if !domain.IsValidStatus(s) {
+			return fmt.Errorf("invalid status %q", *status)
+		}
+		filter.Status = &s
+	}
+	if *assignee != "" {
+		filter.Assignee = assignee
+	}
+	if *watcher != "" {
+		filter.Watcher = watcher
+	}
+	if *participant != "" {
+		filter.Participant = participant
+	}
+
+	ctx := context.Background()
+
+	store, err := openStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer store.close()
+
+	if *output == "" {
+		return writeExport(ctx, store.tasks, filter, stdout, f, export.Options{Columns: cols, Location: loc})
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := writeExport(ctx, store.tasks, filter, file, f, export.Options{Columns: cols, Location: loc}); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func writeExport(
+	ctx context.Context,
+	repo domain.TaskRepository,
+	filter domain.TaskFilter,
+	out io.Writer,
+	format export.Format,
+	opts export.Options,
+) error {
+
+	w, err := export.NewWriter(out, format, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := domain.StreamTasks(ctx, repo, filter, w.Write); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
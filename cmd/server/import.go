@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"graph-task-service/internal/config"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/importer"
+	"graph-task-service/internal/repository/cache"
+	"graph-task-service/internal/repository/postgres"
+	"graph-task-service/internal/service"
+	"io"
+	"os"
+)
+
+// runImport imports the JSON export of another tracker into the database.
+// Running it again with a newer export updates the tasks it created.
+func runImport(cfg *config.Config, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+
+	var (
+		from      = fs.String("from", "", "tracker the file was exported from: jira, trello or github")
+		statusMap = fs.String("status-map", "", `status mappings, e.g. "In Review=in_progress,Won't Do=done"`)
+		dryRun    = fs.Bool("dry-run", false, "report what would change without changing it")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: server import -from jira|trello|github [-status-map MAP] [-dry-run] FILE")
+	}
+
+	tracker, err := importer.ParseTracker(*from)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := importer.ParseStatusMap(*statusMap)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rows, err := importer.ReadTracker(file, tracker, statuses)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	store, err := openStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer store.close()
+
+	// Tasks cached by running servers in a shared cache are invalidated.
+	opts := cache.Options{}
+	if store.tx != nil {
+		opts.InTx = postgres.InTx
+		opts.AfterCommit = postgres.AfterCommit
+	}
+
+	tasks, closeCache, err := newTaskCache(cfg, store.tasks, opts)
+	if err != nil {
+		return err
+	}
+	defer closeCache()
+
+	result, err := service.NewSyncService(tasks, store.refs, store.tx).SyncTasks(ctx, string(tracker), rows, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	return printSyncResult(stdout, result)
+}
+
+func printSyncResult(out io.Writer, result *domain.SyncResult) error {
+	for _, e := range result.Errors {
+		fmt.Fprintf(out, "item %d: %s\n", e.Line, e.Message)
+	}
+
+	if result.Invalid > 0 {
+		return fmt.Errorf("%d of %d items are invalid, nothing was imported", result.Invalid, result.Items)
+	}
+
+	verb := "imported"
+	if result.DryRun {
+		verb = "would import"
+	}
+
+	_, err := fmt.Fprintf(out, "%s %d items: %d created, %d updated, %d unchanged\n",
+		verb, result.Items, result.Created, result.Updated, result.Unchanged)
+	return err
+}
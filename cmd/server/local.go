@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"graph-task-service/internal/config"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/handler/graphql"
+	"graph-task-service/internal/handler/grpc"
+	"graph-task-service/internal/handler/http"
+	"graph-task-service/internal/health"
+	"graph-task-service/internal/observability"
+	"graph-task-service/internal/repository/cache"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/repository/sqlite"
+	"graph-task-service/internal/router"
+	"graph-task-service/internal/service"
+	"log/slog"
+)
+
+// runLocal serves the task API from the memory or sqlite driver, so the
+// service runs without Postgres. Webhooks, idempotency keys, the outbox and
+// event streams, including the WatchTasks RPC and GraphQL subscriptions,
+// need Postgres and are left out.
+func runLocal(cfg *config.Config, logger *slog.Logger) error {
+	metrics := observability.NewMetrics()
+	healthRegistry := health.NewRegistry(cfg.Health.CheckTimeout)
+
+	var taskRepo domain.TaskRepository
+
+	switch cfg.Database.Driver {
+	case "sqlite":
+		db, err := sqlite.Open(context.Background(), cfg.Database.SQLitePath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		metrics.RegisterDB(db, "tasks")
+		healthRegistry.Register("database", db.PingContext)
+		taskRepo = sqlite.NewTaskRepository(db)
+	default:
+		taskRepo = memory.NewTaskRepository()
+	}
+
+	if counter, ok := taskRepo.(domain.TaskCounter); ok {
+		metrics.RegisterTaskCounter(counter)
+	}
+
+	taskRepo, closeCache, err := newTaskCache(cfg, taskRepo, cache.Options{Metrics: metrics})
+	if err != nil {
+		return err
+	}
+	defer closeCache()
+
+	slog.Warn("running without Postgres: webhooks, idempotency keys and event streams are disabled",
+		"driver", cfg.Database.Driver)
+
+	taskService := observability.InstrumentTaskService(
+		service.NewTaskService(taskRepo, nil),
+		metrics,
+	)
+
+	importService := service.NewImportService(taskRepo, memory.NewImportJobRepository(), nil, cfg.Import.Workers, cfg.Import.Queue)
+
+	r := router.New(
+		http.NewTaskHandler(taskService),
+		http.NewImportHandler(importService),
+		nil,
+		nil,
+		graphql.NewHandler(taskService, nil, nil, graphqlOptions(cfg)),
+		http.NewHealthHandler(healthRegistry),
+		nil,
+		metrics,
+		logger,
+		routerOptions(cfg),
+	)
+
+	srv := newServer(cfg, r)
+	srv.BeforeShutdown(healthRegistry.Shutdown)
+
+	serveErr := runServer(cfg, srv, newGRPCServer(cfg, grpc.NewTaskServer(taskService, nil, nil), logger))
+
+	closeImports(cfg, importService)
+
+	if serveErr != nil {
+		return serveErr
+	}
+
+	slog.Info("server stopped")
+	return nil
+}
@@ -1,45 +1,449 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"graph-task-service/internal/config"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/handler/graphql"
+	"graph-task-service/internal/handler/grpc"
 	"graph-task-service/internal/handler/http"
+	"graph-task-service/internal/health"
+	"graph-task-service/internal/logging"
+	"graph-task-service/internal/middelware"
+	"graph-task-service/internal/observability"
+	"graph-task-service/internal/outbox"
+	"graph-task-service/internal/repository/cache"
 	"graph-task-service/internal/repository/postgres"
 	"graph-task-service/internal/router"
+	"graph-task-service/internal/server"
 	"graph-task-service/internal/service"
-	"log"
+	"graph-task-service/internal/stream"
+	"graph-task-service/internal/webhook"
+	"graph-task-service/migration"
+	"log/slog"
+	"net"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
+	gogrpc "google.golang.org/grpc"
 )
 
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("no .env file found, using system envs")
+	if err := run(); err != nil {
+		slog.Error("exiting", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run returns instead of exiting, so that what it deferred, such as closing
+// the database and flushing traces, also runs when it fails.
+func run() error {
+	envErr := godotenv.Load()
+
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintf(os.Stderr, "%s\n\nflags:\n", usage)
+		config.PrintFlags(os.Stderr)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load configuration: %w", err)
+	}
+
+	if len(args) > 0 && args[0] == "config" {
+		if err := runConfig(cfg, args[1:], os.Stdout); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+		return nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// The export command may write its file to stdout.
+	logOut := os.Stdout
+	if len(args) > 0 && args[0] == "export" {
+		logOut = os.Stderr
 	}
 
-	cfg := config.Load()
+	logger, err := logging.New(cfg.Log.Level, logOut)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	slog.SetDefault(logger)
+
+	if envErr != nil {
+		slog.Info("no .env file found, using system envs")
+	}
 
-	if cfg.DBURL == "" {
-		log.Fatal("DATABASE_URL is required")
+	if len(args) > 0 {
+		if err := runCommand(cfg, args); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		return nil
 	}
 
-	db, err := postgres.NewDB(cfg.DBURL)
+	shutdownTracing, err := observability.SetupTracing(context.Background(), observability.TracingConfig{
+		ServiceName:  cfg.Tracing.ServiceName,
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPProtocol: cfg.Tracing.OTLPProtocol,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPInsecure: cfg.Tracing.OTLPInsecure,
+		Sampler:      cfg.Tracing.Sampler,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	}, os.Stdout)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("setup tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	if !cfg.Database.UsesPostgres() {
+		if err := runLocal(cfg, logger); err != nil {
+			return fmt.Errorf("run server: %w", err)
+		}
+		return nil
+	}
+
+	var (
+		db   *sql.DB
+		pool *pgxpool.Pool
+	)
+	if cfg.Database.Driver == "pgxpool" {
+		pool, err = postgres.NewPool(context.Background(), cfg.Database.URL, dbConfig(cfg))
+		if err != nil {
+			return fmt.Errorf("connect database: %w", err)
+		}
+		defer pool.Close()
+
+		// The other repositories share the native pool through database/sql.
+		db = stdlib.OpenDBFromPool(pool)
+	} else {
+		db, err = postgres.NewDB(context.Background(), cfg.Database.URL, dbConfig(cfg))
+		if err != nil {
+			return fmt.Errorf("connect database: %w", err)
+		}
 	}
 	defer db.Close()
 
-	if err := postgres.RunMigrations(db); err != nil {
-		log.Fatal(err)
+	migrator, err := postgres.NewMigrator(db, migration.FS)
+	if err != nil {
+		return fmt.Errorf("load migrations: %w", err)
+	}
+
+	if cfg.Features.RunMigrations {
+		if _, err := migrator.Up(context.Background()); err != nil {
+			return fmt.Errorf("run migrations: %w", err)
+		}
 	}
 
-	taskRepo := postgres.NewTaskRepository(db)
-	taskService := service.NewTaskService(taskRepo)
+	baseTaskRepo := postgres.NewTaskRepository(db)
+	if pool != nil {
+		baseTaskRepo = postgres.NewPgxTaskRepository(pool)
+	}
+
+	retryPolicy := postgres.RetryPolicy{
+		MaxAttempts: cfg.Database.RetryAttempts,
+		Backoff:     cfg.Database.RetryBackoff,
+	}
+
+	metrics := observability.NewMetrics()
+	metrics.RegisterDB(db, "tasks")
+	metrics.RegisterTaskCounter(postgres.NewTaskStats(db))
+
+	taskRepo, closeCache, err := newTaskCache(
+		cfg,
+		postgres.NewRetryingTaskRepository(baseTaskRepo, retryPolicy),
+		cache.Options{
+			InTx:        postgres.InTx,
+			AfterCommit: postgres.AfterCommit,
+			Metrics:     metrics,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("create task cache: %w", err)
+	}
+	defer closeCache()
+
+	txManager := postgres.NewTxManager(db, domain.IsolationLevel(cfg.Database.IsolationLevel), retryPolicy)
+	webhookRepo := postgres.NewWebhookRepository(db)
+	deliveryRepo := postgres.NewWebhookDeliveryRepository(db)
+	outboxRepo := postgres.NewOutboxRepository(db)
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
+
+	dispatcher := webhook.NewDispatcher(webhookRepo, deliveryRepo)
+
+	publisher, closePublisher, err := newEventPublisher(cfg, dispatcher)
+	if err != nil {
+		return fmt.Errorf("create event publisher: %w", err)
+	}
+	defer closePublisher()
+
+	taskService := observability.InstrumentTaskService(
+		service.NewTaskService(taskRepo, txManager),
+		metrics,
+	)
+	webhookService := service.NewWebhookService(webhookRepo, deliveryRepo)
+	importService := service.NewImportService(
+		taskRepo,
+		postgres.NewImportJobRepository(db),
+		txManager,
+		cfg.Import.Workers,
+		cfg.Import.Queue,
+	)
+
 	taskHandler := http.NewTaskHandler(taskService)
+	webhookHandler := http.NewWebhookHandler(webhookService)
+
+	// Background workers outlive the HTTP server so events written by the
+	// last requests are still relayed; they stop before the database closes.
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	goWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workersCtx)
+		}()
+	}
+
+	eventFeed := postgres.NewEventFeed(db, cfg.Database.URL)
+	hub := stream.NewHub(cfg.Events.StreamBuffer)
+	goWorker(func(ctx context.Context) {
+		if err := eventFeed.Listen(ctx, hub.Broadcast); err != nil && ctx.Err() == nil {
+			slog.Error("event feed stopped", "error", err)
+		}
+	})
+	streamHandler := http.NewStreamHandler(hub, eventFeed)
+
+	healthRegistry := health.NewRegistry(cfg.Health.CheckTimeout)
+	healthRegistry.Register("database", postgres.PingCheck(db))
+	healthRegistry.Register("migrations", postgres.MigrationCheck(db, migrator.Latest()))
+	if pool != nil {
+		healthRegistry.Register("db_pool", postgres.PgxPoolSaturationCheck(pool, cfg.Health.PoolSaturationThreshold))
+	} else {
+		healthRegistry.Register("db_pool", postgres.PoolSaturationCheck(db, cfg.Health.PoolSaturationThreshold))
+	}
+	healthHandler := http.NewHealthHandler(healthRegistry)
+
+	workerCfg := webhook.DefaultWorkerConfig()
+	workerCfg.PollInterval = cfg.Workers.WebhookPollInterval
+	workerCfg.MaxAttempts = cfg.Workers.WebhookMaxAttempts
+	workerCfg.Timeout = cfg.Workers.WebhookTimeout
+	workerCfg.Lease = cfg.Workers.WebhookLease
+
+	goWorker(webhook.NewWorker(webhookRepo, deliveryRepo, workerCfg).Run)
+
+	relayCfg := outbox.DefaultRelayConfig()
+	relayCfg.PollInterval = cfg.Workers.OutboxPollInterval
+
+	goWorker(outbox.NewRelay(outboxRepo, publisher, relayCfg).Run)
+
+	goWorker(func(ctx context.Context) {
+		purgeIdempotencyKeys(ctx, idempotencyRepo, cfg.Workers.IdempotencyPurgeInterval)
+	})
+
+	goWorker(func(ctx context.Context) {
+		failAbandonedImports(ctx, importService, time.Minute)
+	})
+
+	r := router.New(
+		taskHandler,
+		http.NewImportHandler(importService),
+		webhookHandler,
+		streamHandler,
+		graphql.NewHandler(taskService, hub, eventFeed, graphqlOptions(cfg)),
+		healthHandler,
+		middelware.Idempotency(idempotencyRepo, cfg.Idempotency.TTL, cfg.Idempotency.Lease),
+		metrics,
+		logger,
+		routerOptions(cfg),
+	)
+
+	srv := newServer(cfg, r)
+	srv.BeforeShutdown(healthRegistry.Shutdown)
+	srv.RegisterOnShutdown(hub.Close)
+
+	// Closing the hub ends the WatchTasks streams, which GracefulStop would
+	// otherwise wait on until the shutdown timeout.
+	serveErr := runServer(cfg, srv, newGRPCServer(cfg, grpc.NewTaskServer(taskService, hub, eventFeed), logger), hub.Close)
+
+	closeImports(cfg, importService)
+
+	stopWorkers()
+	workers.Wait()
+
+	if serveErr != nil {
+		return fmt.Errorf("run server: %w", serveErr)
+	}
+
+	slog.Info("server stopped")
+	return nil
+}
+
+func routerOptions(cfg *config.Config) router.Options {
+	return router.Options{
+		MaxBodyBytes:   cfg.Server.MaxBodyBytes,
+		MaxImportBytes: cfg.Import.MaxBytes,
+		UserHeader:     cfg.Auth.UserHeader,
+		RequireUser:    cfg.Auth.Required,
+		EnableSwagger:  cfg.Features.Swagger,
+	}
+}
+
+func graphqlOptions(cfg *config.Config) graphql.Options {
+	return graphql.Options{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+	}
+}
+
+func newServer(cfg *config.Config, h nethttp.Handler) *server.Server {
+	return server.New(h, serverConfig(cfg))
+}
+
+func serverConfig(cfg *config.Config) server.Config {
+	return server.Config{
+		Addr:              ":" + cfg.Server.Port,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		ShutdownDelay:     cfg.Server.ShutdownDelay,
+		ShutdownTimeout:   cfg.Server.ShutdownTimeout,
+	}
+}
+
+// newGRPCServer returns the gRPC server of tasks, or nil when
+// server.grpc_port is empty.
+func newGRPCServer(cfg *config.Config, tasks *grpc.TaskServer, logger *slog.Logger) *gogrpc.Server {
+	if cfg.Server.GRPCPort == "" {
+		return nil
+	}
+
+	return grpc.NewServer(tasks, grpc.Options{
+		UserHeader:  cfg.Auth.UserHeader,
+		RequireUser: cfg.Auth.Required,
+		Logger:      logger,
+	})
+}
+
+// runServer serves until SIGINT or SIGTERM, over gRPC as well unless
+// grpcSrv is nil. onGRPCShutdown runs before the gRPC server stops. It
+// returns why either server could not start or failed.
+func runServer(
+	cfg *config.Config,
+	srv *server.Server,
+	grpcSrv *gogrpc.Server,
+	onGRPCShutdown ...func(),
+) error {
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var grpcDone sync.WaitGroup
+	if grpcSrv != nil {
+		ln, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+		if err != nil {
+			return fmt.Errorf("listen grpc: %w", err)
+		}
+
+		grpcDone.Add(1)
+		go func() {
+			defer grpcDone.Done()
+
+			slog.Info("grpc server running", "port", cfg.Server.GRPCPort)
+			if err := server.ServeGRPC(ctx, grpcSrv, ln, serverConfig(cfg), onGRPCShutdown...); err != nil {
+				slog.Error("grpc server stopped", "error", err)
+			}
+		}()
+	}
+
+	slog.Info("server running", "port", cfg.Server.Port, "driver", cfg.Database.Driver)
+	err := srv.Run(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		// Requests outlived the shutdown timeout; the server still stopped.
+		slog.Warn("server stopped", "error", err)
+		err = nil
+	}
+
+	// The gRPC server also stops when the HTTP server failed to start.
+	stop()
+	grpcDone.Wait()
+
+	return err
+}
+
+// closeImports gives running imports the shutdown timeout to finish; the
+// rest are cancelled and recorded as failed.
+func closeImports(cfg *config.Config, imports service.ImportService) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := imports.Close(ctx); err != nil {
+		slog.Warn("cancelled running imports", "error", err)
+	}
+}
+
+func dbConfig(cfg *config.Config) postgres.DBConfig {
+	return postgres.DBConfig{
+		MaxOpenConns:     cfg.Database.MaxOpenConns,
+		MaxIdleConns:     cfg.Database.MaxIdleConns,
+		ConnMaxLifetime:  cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.Database.ConnMaxIdleTime,
+		StatementTimeout: cfg.Database.StatementTimeout,
+		ConnectAttempts:  cfg.Database.ConnectAttempts,
+		ConnectBackoff:   cfg.Database.ConnectBackoff,
+	}
+}
+
+// failAbandonedImports fails the import jobs of stopped instances, once at
+// startup and then every interval.
+func failAbandonedImports(ctx context.Context, imports service.ImportService, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		n, err := imports.FailAbandoned(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "fail abandoned imports", "error", err)
+		}
+		if n > 0 {
+			slog.WarnContext(ctx, "failed abandoned imports", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
 
-	r := router.New(taskHandler)
+func purgeIdempotencyKeys(ctx context.Context, repo domain.IdempotencyRepository, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
 
-	log.Printf("server running on :%s\n", cfg.AppPort)
-	if err := r.Run(":" + cfg.AppPort); err != nil {
-		log.Fatal(err)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := repo.DeleteExpired(ctx); err != nil {
+				slog.ErrorContext(ctx, "purge idempotency keys", "error", err)
+			}
+		}
 	}
 }
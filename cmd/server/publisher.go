@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"graph-task-service/internal/config"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/outbox"
+	"graph-task-service/internal/webhook"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/segmentio/kafka-go"
+)
+
+// newEventPublisher builds the publisher the outbox relay feeds, according to
+// cfg.Events.Publishers. The returned cleanup closes broker connections.
+func newEventPublisher(
+	cfg *config.Config,
+	dispatcher *webhook.Dispatcher,
+) (domain.EventPublisher, func(), error) {
+
+	var (
+		publishers outbox.MultiPublisher
+		closers    []func()
+	)
+
+	cleanup := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	for _, name := range cfg.Events.Publishers {
+		switch name {
+		case "memory":
+			publishers = append(publishers, outbox.NewMemoryPublisher())
+		case "webhook":
+			publishers = append(publishers, dispatcher)
+		case "nats":
+			conn, err := nats.Connect(cfg.Events.NATSURL)
+			if err != nil {
+				cleanup()
+				return nil, nil, fmt.Errorf("connect to nats: %w", err)
+			}
+			closers = append(closers, conn.Close)
+			js, err := jetstream.New(conn)
+			if err != nil {
+				cleanup()
+				return nil, nil, fmt.Errorf("open nats jetstream: %w", err)
+			}
+			publishers = append(publishers, outbox.NewNATSPublisher(js, cfg.Events.NATSSubject))
+		case "kafka":
+			writer := &kafka.Writer{
+				Addr:         kafka.TCP(cfg.Events.KafkaBrokers...),
+				Topic:        cfg.Events.KafkaTopic,
+				Balancer:     &kafka.Hash{},
+				RequiredAcks: kafka.RequireAll,
+			}
+			closers = append(closers, func() { _ = writer.Close() })
+			publishers = append(publishers, outbox.NewKafkaPublisher(writer))
+		default:
+			cleanup()
+			return nil, nil, fmt.Errorf("unknown event publisher %q", name)
+		}
+	}
+
+	return publishers, cleanup, nil
+}
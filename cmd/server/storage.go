@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/config"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/repository/postgres"
+	"graph-task-service/internal/repository/sqlite"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// storage is the configured database opened for a one-off command.
+type storage struct {
+	tasks domain.TaskRepository
+	refs  domain.ExternalRefRepository
+	// tx is nil for SQLite, whose repositories do not join units of work.
+	tx    domain.TxManager
+	close func()
+}
+
+// openStorage opens the configured database for a one-off command. The
+// memory driver holds no tasks outside a running server.
+func openStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	retryPolicy := postgres.RetryPolicy{
+		MaxAttempts: cfg.Database.RetryAttempts,
+		Backoff:     cfg.Database.RetryBackoff,
+	}
+	level := domain.IsolationLevel(cfg.Database.IsolationLevel)
+
+	switch cfg.Database.Driver {
+	case "pgxpool":
+		pool, err := postgres.NewPool(ctx, cfg.Database.URL, dbConfig(cfg))
+		if err != nil {
+			return nil, err
+		}
+		db := stdlib.OpenDBFromPool(pool)
+
+		return &storage{
+			tasks: postgres.NewPgxTaskRepository(pool),
+			refs:  postgres.NewExternalRefRepository(db),
+			tx:    postgres.NewTxManager(db, level, retryPolicy),
+			close: func() {
+				db.Close()
+				pool.Close()
+			},
+		}, nil
+
+	case "sqlite":
+		db, err := sqlite.Open(ctx, cfg.Database.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+
+		return &storage{
+			tasks: sqlite.NewTaskRepository(db),
+			refs:  sqlite.NewExternalRefRepository(db),
+			close: func() { db.Close() },
+		}, nil
+
+	case "memory":
+		return nil, errors.New("the memory driver keeps tasks only while the server runs")
+
+	default:
+		db, err := postgres.NewDB(ctx, cfg.Database.URL, dbConfig(cfg))
+		if err != nil {
+			return nil, err
+		}
+
+		return &storage{
+			tasks: postgres.NewTaskRepository(db),
+			refs:  postgres.NewExternalRefRepository(db),
+			tx:    postgres.NewTxManager(db, level, retryPolicy),
+			close: func() { db.Close() },
+		}, nil
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// board is the state of the board view: one column of tasks per status, in
+// the order of statuses, and the selected card.
+type board struct {
+	columns [][]Task
+	col     int
+	// row is the selected card of each column, so moving between columns
+	// returns to where the user was.
+	row []int
+	// message reports the outcome of the last action.
+	message string
+}
+
+func newBoard(tasks []Task) *board {
+	b := &board{row: make([]int, len(statuses))}
+	b.load(tasks)
+	return b
+}
+
+// load replaces the tasks, keeping the selection on the same task where it
+// still exists.
+func (b *board) load(tasks []Task) {
+	selected := b.selected()
+
+	b.columns = make([][]Task, len(statuses))
+	for _, t := range tasks {
+		i := statusColumn(t.Status)
+		if i < 0 {
+			continue
+		}
+		b.columns[i] = append(b.columns[i], t)
+	}
+
+	if selected != nil {
+		b.focus(selected.ID)
+	}
+	b.clamp()
+}
+
+func statusColumn(status string) int {
+	for i, s := range statuses {
+		if s == status {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *board) selected() *Task {
+	if b.col >= len(b.columns) {
+		return nil
+	}
+	column := b.columns[b.col]
+	if len(column) == 0 {
+		return nil
+	}
+	return &column[b.row[b.col]]
+}
+
+// focus selects the task with id.
+func (b *board) focus(id string) {
+	for c, column := range b.columns {
+		for r, t := range column {
+			if t.ID == id {
+				b.col, b.row[c] = c, r
+				return
+			}
+		}
+	}
+}
+
+func (b *board) clamp() {
+	for c, column := range b.columns {
+		b.row[c] = max(0, min(b.row[c], len(column)-1))
+	}
+}
+
+func (b *board) moveCursor(dcol, drow int) {
+	b.col = max(0, min(b.col+dcol, len(statuses)-1))
+	b.row[b.col] += drow
+	b.clamp()
+}
+
+// render draws the board into a screen of width by height cells. Lines end
+// in "\r\n" as the terminal is in raw mode.
+func (b *board) render(width, height int, help string) string {
+	const gap = 2
+
+	colWidth := max((width-gap*(len(statuses)-1))/len(statuses), 8)
+	// Header, rule, and the message and help lines at the bottom.
+	visible := max(height-4, 1)
+
+	var s strings.Builder
+	s.WriteString("\x1b[H\x1b[2J")
+
+	headers := make([]string, len(statuses))
+	for i, status := range statuses {
+		headers[i] = fmt.Sprintf("%s (%d)", strings.ToUpper(strings.ReplaceAll(status, "_", " ")), len(b.columns[i]))
+	}
+	b.writeRow(&s, headers, colWidth, gap, -1)
+
+	rules := make([]string, len(statuses))
+	for i := range rules {
+		rules[i] = strings.Repeat("─", colWidth)
+	}
+	b.writeRow(&s, rules, colWidth, gap, -1)
+
+	// Each column scrolls on its own to keep its selected card in view.
+	offsets := make([]int, len(statuses))
+	for c := range statuses {
+		offsets[c] = max(0, b.row[c]-visible+1)
+	}
+
+	for line := 0; line < visible; line++ {
+		cells := make([]string, len(statuses))
+		highlight := -1
+
+		for c, column := range b.columns {
+			r := offsets[c] + line
+			if r >= len(column) {
+				continue
+			}
+			cells[c] = cardLabel(column[r])
+			if c == b.col && r == b.row[c] {
+				highlight = c
+			}
+		}
+		b.writeRow(&s, cells, colWidth, gap, highlight)
+	}
+
+	s.WriteString(truncate(b.message, width) + "\r\n")
+	s.WriteString("\x1b[2m" + truncate(help, width) + "\x1b[0m")
+
+	return s.String()
+}
+
+func (b *board) writeRow(s *strings.Builder, cells []string, width, gap, highlight int) {
+	for i, cell := range cells {
+		if i > 0 {
+			s.WriteString(strings.Repeat(" ", gap))
+		}
+
+		cell = truncate(cell, width)
+		cell += strings.Repeat(" ", width-len([]rune(cell)))
+		if i == highlight {
+			cell = "\x1b[7m" + cell + "\x1b[0m"
+		}
+		s.WriteString(cell)
+	}
+	s.WriteString("\r\n")
+}
+
+func cardLabel(t Task) string {
+	if len(t.Assignees) == 0 {
+		return t.Title
+	}
+	return t.Title + " @" + strings.Join(t.Assignees, ",")
+}
+
+// printBoard writes the board as plain lists, for output that is not a
+// terminal.
+func printBoard(out io.Writer, b *board) error {
+	for i, status := range statuses {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "%s (%d)\n", strings.ToUpper(strings.ReplaceAll(status, "_", " ")), len(b.columns[i]))
+		for _, t := range b.columns[i] {
+			fmt.Fprintf(out, "  %s  %s\n", t.ID, cardLabel(t))
+		}
+	}
+	return nil
+}
+
+const boardHelp = "←/→ h/l column  ↑/↓ j/k card  </> move card  r refresh  q quit"
+
+func setupBoard(fs *flag.FlagSet) runFunc {
+	var f ListFilter
+	addFilterFlags(fs, &f)
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) > 0 {
+			return fmt.Errorf("unexpected argument %q", args[0])
+		}
+		if err := checkFilter(f); err != nil {
+			return err
+		}
+		if a.output != outputTable {
+			return errors.New("the board has no -o; use list for json or yaml")
+		}
+
+		tasks, err := a.client.listAllTasks(ctx, f)
+		if err != nil {
+			return err
+		}
+		b := newBoard(tasks)
+
+		in, inOK := a.stdin.(*os.File)
+		out, outOK := a.stdout.(*os.File)
+		if !inOK || !outOK || !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+			return printBoard(a.stdout, b)
+		}
+
+		return runBoard(ctx, a.client, f, b, in, out)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+type boardAction int
+
+const (
+	actionNone boardAction = iota
+	actionQuit
+	actionRefresh
+	actionMoveBack
+	actionMoveForward
+)
+
+// handle applies a key to the board and returns what the caller must do.
+func (b *board) handle(key string) boardAction {
+	switch key {
+	case "q", "ctrl-c", "ctrl-d", "esc":
+		return actionQuit
+	case "left", "h":
+		b.moveCursor(-1, 0)
+	case "right", "l":
+		b.moveCursor(1, 0)
+	case "up", "k":
+		b.moveCursor(0, -1)
+	case "down", "j":
+		b.moveCursor(0, 1)
+	case "r":
+		return actionRefresh
+	case "<", ",":
+		if b.selected() != nil && b.col > 0 {
+			return actionMoveBack
+		}
+	case ">", ".":
+		if b.selected() != nil && b.col < len(statuses)-1 {
+			return actionMoveForward
+		}
+	}
+	return actionNone
+}
+
+// parseKeys decodes what the terminal sent in raw mode into key names:
+// arrows, control keys and printable characters.
+func parseKeys(buf []byte) []string {
+	var keys []string
+
+	for len(buf) > 0 {
+		switch {
+		case len(buf) >= 3 && buf[0] == 0x1b && (buf[1] == '[' || buf[1] == 'O'):
+			switch buf[2] {
+			case 'A':
+				keys = append(keys, "up")
+			case 'B':
+				keys = append(keys, "down")
+			case 'C':
+				keys = append(keys, "right")
+			case 'D':
+				keys = append(keys, "left")
+			}
+			buf = buf[3:]
+		case buf[0] == 0x1b:
+			keys = append(keys, "esc")
+			buf = buf[1:]
+		case buf[0] == 3:
+			keys = append(keys, "ctrl-c")
+			buf = buf[1:]
+		case buf[0] == 4:
+			keys = append(keys, "ctrl-d")
+			buf = buf[1:]
+		default:
+			keys = append(keys, string(buf[0]))
+			buf = buf[1:]
+		}
+	}
+
+	return keys
+}
+
+// runBoard shows b on the terminal until the user quits. Moving a card
+// updates the status of its task and reloads the board, so changes made by
+// others show up too.
+func runBoard(
+	ctx context.Context,
+	c *client,
+	f ListFilter,
+	b *board,
+	in, out *os.File,
+) error {
+
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(in.Fd()), state)
+
+	// Alternate screen without a cursor, restored on the way out.
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan []string)
+	go readKeys(in, keys)
+
+	reload := func(focus string) {
+		tasks, err := c.listAllTasks(ctx, f)
+		if err != nil {
+			b.message = "refresh failed: " + err.Error()
+			return
+		}
+		b.load(tasks)
+		if focus != "" {
+			b.focus(focus)
+		}
+	}
+
+	for {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil || width == 0 || height == 0 {
+			width, height = 80, 24
+		}
+		fmt.Fprint(out, b.render(width, height, boardHelp))
+
+		var pressed []string
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			pressed = k
+		}
+
+		for _, key := range pressed {
+			action := b.handle(key)
+
+			switch action {
+			case actionQuit:
+				return nil
+			case actionRefresh:
+				b.message = ""
+				reload("")
+			case actionMoveBack, actionMoveForward:
+				task := b.selected()
+				to := b.col + 1
+				if action == actionMoveBack {
+					to = b.col - 1
+				}
+
+				if _, err := c.updateStatus(ctx, task.ID, statuses[to]); err != nil {
+					b.message = "move failed: " + err.Error()
+					continue
+				}
+				b.message = fmt.Sprintf("moved %q to %s", task.Title, statuses[to])
+				reload(task.ID)
+			}
+		}
+	}
+}
+
+func readKeys(in io.Reader, keys chan<- []string) {
+	defer close(keys)
+
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			keys <- parseKeys(buf[:n])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var boardTasks = []Task{
+	{ID: "1", Title: "plan", Status: "todo", Assignees: []string{"alice"}},
+	{ID: "2", Title: "build", Status: "in_progress"},
+	{ID: "3", Title: "spec", Status: "todo"},
+	{ID: "4", Title: "ship", Status: "done"},
+}
+
+func TestBoard_Navigation(t *testing.T) {
+	b := newBoard(boardTasks)
+	require.Len(t, b.columns, 3)
+	assert.Len(t, b.columns[0], 2)
+	assert.Equal(t, "1", b.selected().ID)
+
+	// The cursor stops at the edges.
+	assert.Equal(t, actionNone, b.handle("up"))
+	assert.Equal(t, actionNone, b.handle("j"))
+	assert.Equal(t, actionNone, b.handle("down"))
+	assert.Equal(t, "3", b.selected().ID)
+	assert.Equal(t, actionNone, b.handle("<"), "todo is the first column")
+	b.handle("h")
+	assert.Equal(t, "3", b.selected().ID)
+
+	b.handle("right")
+	b.handle("l")
+	assert.Equal(t, "4", b.selected().ID)
+	assert.Equal(t, actionNone, b.handle(">"), "done is the last column")
+	assert.Equal(t, actionMoveBack, b.handle(","))
+
+	// Each column remembers its card.
+	b.handle("left")
+	b.handle("left")
+	assert.Equal(t, "3", b.selected().ID)
+
+	assert.Equal(t, actionMoveForward, b.handle("."))
+	assert.Equal(t, actionRefresh, b.handle("r"))
+	assert.Equal(t, actionQuit, b.handle("ctrl-c"))
+}
+
+func TestBoard_LoadKeepsSelection(t *testing.T) {
+	b := newBoard(boardTasks)
+	b.focus("3")
+
+	moved := append([]Task{}, boardTasks...)
+	moved[2].Status = "in_progress"
+	b.load(moved)
+	assert.Equal(t, "3", b.selected().ID, "the selection follows the task to its new column")
+
+	b.load(nil)
+	assert.Nil(t, b.selected())
+	assert.Equal(t, actionNone, b.handle(">"))
+}
+
+func TestBoard_Render(t *testing.T) {
+	b := newBoard(boardTasks)
+
+	screen := b.render(60, 6, "help")
+	lines := strings.Split(screen, "\r\n")
+	require.Len(t, lines, 6)
+
+	assert.Contains(t, lines[0], "TODO (2)")
+	assert.Contains(t, lines[0], "IN PROGRESS (1)")
+	assert.Contains(t, lines[2], "\x1b[7mplan @alice ")
+	assert.Contains(t, lines[2], "build")
+	assert.Contains(t, lines[2], "ship")
+
+	// Two card rows fit; the second todo card scrolls into view.
+	b.handle("down")
+	lines = strings.Split(b.render(60, 5, "help"), "\r\n")
+	assert.Contains(t, lines[2], "\x1b[7mspec")
+}
+
+func TestParseKeys(t *testing.T) {
+	assert.Equal(t,
+		[]string{"up", "down", "right", "left", "q", "esc", "ctrl-c", ">"},
+		parseKeys([]byte("\x1b[A\x1b[B\x1bOC\x1b[Dq\x1b\x03>")),
+	)
+}
+
+func TestBoardCommand_PlainOutput(t *testing.T) {
+	newTestServer(t)
+
+	createTask(t, "plan", "-assignee", "alice")
+	createTask(t, "ship", "-status", "done")
+
+	var stdout, stderr bytes.Buffer
+	code := run(t.Context(), []string{"board"}, strings.NewReader(""), &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+
+	out := stdout.String()
+	assert.Contains(t, out, "TODO (1)\n")
+	assert.Contains(t, out, "plan @alice\n")
+	assert.Contains(t, out, "IN PROGRESS (0)\n")
+	assert.Contains(t, out, "DONE (1)\n")
+}
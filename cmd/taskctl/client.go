@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	api "graph-task-service/pkg/client"
+)
+
+// Task is a task as taskctl prints it, in the layout of the API.
+type Task struct {
+	ID        string   `json:"id" yaml:"id"`
+	Title     string   `json:"title" yaml:"title"`
+	Status    string   `json:"status" yaml:"status"`
+	Assignees []string `json:"assignees" yaml:"assignees"`
+	Watchers  []string `json:"watchers" yaml:"watchers"`
+	CreatedAt string   `json:"created_at" yaml:"created_at"`
+	UpdatedAt string   `json:"updated_at" yaml:"updated_at"`
+}
+
+func taskFromAPI(t *api.Task) Task {
+	return Task{
+		ID:        t.ID,
+		Title:     t.Title,
+		Status:    string(t.Status),
+		Assignees: t.Assignees,
+		Watchers:  t.Watchers,
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: t.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ListFilter mirrors the query parameters of GET /tasks.
+type ListFilter struct {
+	Status   string
+	Assignee string
+	Watcher  string
+	Limit    int
+	Offset   int
+	// Mine lists the tasks of the profile's user through /me/tasks, which
+	// ignores Assignee and Watcher.
+	Mine bool
+}
+
+func (f ListFilter) params() api.ListTasksParams {
+	return api.ListTasksParams{
+		Status:   api.Status(f.Status),
+		Assignee: f.Assignee,
+		Watcher:  f.Watcher,
+		Limit:    f.Limit,
+		Offset:   f.Offset,
+	}
+}
+
+type createRequest struct {
+	Title     string
+	Status    *string
+	Assignees []string
+	Watchers  []string
+}
+
+// client calls the task API of a profile through the Go client.
+type client struct {
+	api *api.Client
+}
+
+func newClient(p Profile) (*client, error) {
+	c, err := api.New(p.Server, api.Options{
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Token:      p.Token,
+		User:       p.User,
+		UserHeader: p.UserHeader,
+		UserAgent:  "taskctl",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &client{api: c}, nil
+}
+
+func (c *client) createTask(ctx context.Context, req createRequest) (*Task, error) {
+	body := api.CreateTaskRequest{Title: req.Title, Assignees: req.Assignees, Watchers: req.Watchers}
+	if req.Status != nil {
+		body.Status = api.Status(*req.Status)
+	}
+	return toTask(c.api.CreateTask(ctx, body))
+}
+
+func (c *client) getTask(ctx context.Context, id string) (*Task, error) {
+	return toTask(c.api.GetTask(ctx, id))
+}
+
+func (c *client) listTasks(ctx context.Context, f ListFilter) ([]Task, error) {
+	var (
+		page []api.Task
+		err  error
+	)
+	if f.Mine {
+		page, err = c.api.ListMyTasks(ctx, f.params())
+	} else {
+		page, err = c.api.ListTasks(ctx, f.params())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(page))
+	for i := range page {
+		tasks = append(tasks, taskFromAPI(&page[i]))
+	}
+	return tasks, nil
+}
+
+// listAllTasks pages through every task matching f, ignoring its Limit.
+func (c *client) listAllTasks(ctx context.Context, f ListFilter) ([]Task, error) {
+	params := f.params()
+	params.Limit = 0
+
+	all := c.api.Tasks(ctx, params)
+	if f.Mine {
+		all = c.api.MyTasks(ctx, params)
+	}
+
+	var tasks []Task
+	for t, err := range all {
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, taskFromAPI(&t))
+	}
+	return tasks, nil
+}
+
+func (c *client) updateStatus(ctx context.Context, id, status string) (*Task, error) {
+	return toTask(c.api.UpdateTaskStatus(ctx, id, api.Status(status)))
+}
+
+func (c *client) deleteTask(ctx context.Context, id string) error {
+	return c.api.DeleteTask(ctx, id)
+}
+
+func toTask(t *api.Task, err error) (*Task, error) {
+	if err != nil {
+		return nil, err
+	}
+	task := taskFromAPI(t)
+	return &task, nil
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"strings"
+	"text/tabwriter"
+)
+
+var statuses = []string{
+	string(domain.StatusTodo),
+	string(domain.StatusInProgress),
+	string(domain.StatusDone),
+}
+
+func checkStatus(s string) error {
+	if !domain.IsValidStatus(domain.TaskStatus(s)) {
+		return fmt.Errorf("invalid status %q, use %s", s, strings.Join(statuses, ", "))
+	}
+	return nil
+}
+
+func setupCreate(fs *flag.FlagSet) runFunc {
+	var assignees, watchers stringList
+
+	status := fs.String("status", "", "initial status (default todo)")
+	fs.Var(&assignees, "assignee", "assign a user; repeat or separate with commas")
+	fs.Var(&watchers, "watcher", "add a watcher; repeat or separate with commas")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		title := strings.Join(args, " ")
+		if title == "" {
+			return errors.New("a title is required")
+		}
+
+		req := createRequest{Title: title, Assignees: assignees, Watchers: watchers}
+		if *status != "" {
+			if err := checkStatus(*status); err != nil {
+				return err
+			}
+			req.Status = status
+		}
+
+		task, err := a.client.createTask(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return printTask(a.stdout, a.output, task)
+	}
+}
+
+// addFilterFlags registers the filters of GET /tasks.
+func addFilterFlags(fs *flag.FlagSet, f *ListFilter) {
+	fs.StringVar(&f.Assignee, "assignee", "", "only tasks assigned to this user")
+	fs.StringVar(&f.Watcher, "watcher", "", "only tasks watched by this user")
+	fs.BoolVar(&f.Mine, "mine", false, "only tasks the profile's user is assigned to or watching")
+}
+
+func checkFilter(f ListFilter) error {
+	if f.Status != "" {
+		if err := checkStatus(f.Status); err != nil {
+			return err
+		}
+	}
+	if f.Mine && (f.Assignee != "" || f.Watcher != "") {
+		return errors.New("-mine cannot be combined with -assignee or -watcher")
+	}
+	return nil
+}
+
+func setupList(fs *flag.FlagSet) runFunc {
+	var f ListFilter
+
+	fs.StringVar(&f.Status, "status", "", "only tasks with this status")
+	addFilterFlags(fs, &f)
+	fs.IntVar(&f.Limit, "limit", 20, "maximum number of tasks")
+	fs.IntVar(&f.Offset, "offset", 0, "number of tasks to skip")
+	all := fs.Bool("all", false, "list every matching task, ignoring -limit")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) > 0 {
+			return fmt.Errorf("unexpected argument %q", args[0])
+		}
+		if err := checkFilter(f); err != nil {
+			return err
+		}
+		if f.Limit < 1 || f.Offset < 0 {
+			return errors.New("-limit must be positive and -offset not negative")
+		}
+
+		var (
+			tasks []Task
+			err   error
+		)
+		if *all {
+			tasks, err = a.client.listAllTasks(ctx, f)
+		} else {
+			tasks, err = a.client.listTasks(ctx, f)
+		}
+		if err != nil {
+			return err
+		}
+
+		return printTasks(a.stdout, a.output, tasks)
+	}
+}
+
+func setupGet(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) == 0 {
+			return errors.New("a task ID is required")
+		}
+
+		tasks := make([]Task, 0, len(args))
+		for _, id := range args {
+			task, err := a.client.getTask(ctx, id)
+			if err != nil {
+				return fmt.Errorf("%s: %w", id, err)
+			}
+			tasks = append(tasks, *task)
+		}
+
+		if len(tasks) == 1 {
+			return printTask(a.stdout, a.output, &tasks[0])
+		}
+		return printTasks(a.stdout, a.output, tasks)
+	}
+}
+
+func setupStatus(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) != 2 {
+			return errors.New("usage: taskctl status ID STATUS")
+		}
+		if err := checkStatus(args[1]); err != nil {
+			return err
+		}
+
+		task, err := a.client.updateStatus(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		return printTask(a.stdout, a.output, task)
+	}
+}
+
+func setupDelete(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) == 0 {
+			return errors.New("a task ID is required")
+		}
+
+		for _, id := range args {
+			if err := a.client.deleteTask(ctx, id); err != nil {
+				return fmt.Errorf("%s: %w", id, err)
+			}
+			if a.output == outputTable {
+				fmt.Fprintf(a.stdout, "deleted %s\n", id)
+			}
+		}
+		return nil
+	}
+}
+
+func setupConfig(fs *flag.FlagSet) runFunc {
+	var p Profile
+
+	fs.StringVar(&p.Server, "server", "", "set: server URL")
+	fs.StringVar(&p.Token, "token", "", "set: bearer token")
+	fs.StringVar(&p.User, "user", "", "set: user to act as")
+	fs.StringVar(&p.UserHeader, "user-header", "", "set: header carrying the user (default X-User)")
+	quiet := fs.Bool("q", false, "list: print profile names only")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) == 0 {
+			return errors.New("usage: taskctl config list|use NAME|set NAME [flags]|delete NAME")
+		}
+
+		path, err := configPath()
+		if err != nil {
+			return err
+		}
+
+		cfg, err := loadConfig(path)
+		if err != nil {
+			return err
+		}
+
+		if args[0] == "list" {
+			return printProfiles(a, cfg, *quiet)
+		}
+
+		if len(args) != 2 {
+			return fmt.Errorf("usage: taskctl config %s NAME", args[0])
+		}
+		name := args[1]
+
+		switch args[0] {
+		case "use":
+			if _, ok := cfg.Profiles[name]; !ok {
+				return fmt.Errorf("unknown profile %q", name)
+			}
+			cfg.Current = name
+
+		case "set":
+			// Only the given settings change; a new profile becomes current
+			// when it is the first.
+			stored, ok := cfg.Profiles[name]
+			if !ok {
+				stored = &Profile{}
+				cfg.Profiles[name] = stored
+			}
+			stored.Server = firstOf(p.Server, stored.Server)
+			stored.Token = firstOf(p.Token, stored.Token)
+			stored.User = firstOf(p.User, stored.User)
+			stored.UserHeader = firstOf(p.UserHeader, stored.UserHeader)
+			if cfg.Current == "" {
+				cfg.Current = name
+			}
+
+		case "delete":
+			if _, ok := cfg.Profiles[name]; !ok {
+				return fmt.Errorf("unknown profile %q", name)
+			}
+			delete(cfg.Profiles, name)
+			if cfg.Current == name {
+				cfg.Current = ""
+			}
+
+		default:
+			return fmt.Errorf("unknown config command %q", args[0])
+		}
+
+		return cfg.save(path)
+	}
+}
+
+func printProfiles(a *app, cfg *Config, quiet bool) error {
+	if quiet {
+		for _, name := range cfg.names() {
+			fmt.Fprintln(a.stdout, name)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENT\tNAME\tSERVER\tUSER\tTOKEN")
+
+	for _, name := range cfg.names() {
+		p := cfg.Profiles[name]
+
+		current, token := "", ""
+		if name == cfg.Current {
+			current = "*"
+		}
+		if p.Token != "" {
+			token = "****"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", current, name, p.Server, p.User, token)
+	}
+
+	return w.Flush()
+}
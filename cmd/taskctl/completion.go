@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Values completed for flags and arguments besides flag names. Profiles
+// are read from the profile file when completing.
+var (
+	configCommands     = []string{"list", "use", "set", "delete"}
+	completionShells   = []string{"bash", "zsh", "fish"}
+	listProfilesScript = "taskctl config list -q 2>/dev/null"
+)
+
+func setupCompletion(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) != 1 {
+			return errors.New("usage: taskctl completion bash|zsh|fish")
+		}
+
+		switch args[0] {
+		case "bash":
+			return writeBashCompletion(a.stdout)
+		case "zsh":
+			// zsh runs the bash script through its bash emulation.
+			fmt.Fprintln(a.stdout, "autoload -U +X compinit && compinit")
+			fmt.Fprintln(a.stdout, "autoload -U +X bashcompinit && bashcompinit")
+			return writeBashCompletion(a.stdout)
+		case "fish":
+			return writeFishCompletion(a.stdout)
+		default:
+			return fmt.Errorf("unsupported shell %q, use bash, zsh or fish", args[0])
+		}
+	}
+}
+
+// commandFlags returns the flags of c as the command line parser sees them.
+func commandFlags(c command) []*flag.Flag {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	if !c.offline {
+		var (
+			conn   connection
+			output string
+		)
+		addConnectionFlags(fs, &conn, &output)
+	}
+	c.setup(fs)
+
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+	return flags
+}
+
+func commandNames() []string {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.name
+	}
+	return names
+}
+
+func writeBashCompletion(out io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString(`# bash completion for taskctl: source <(taskctl completion bash)
+_taskctl() {
+	local cur prev cmd words
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+	cmd="${COMP_WORDS[1]}"
+
+	if [[ $COMP_CWORD -eq 1 ]]; then
+		COMPREPLY=($(compgen -W "`)
+	b.WriteString(strings.Join(commandNames(), " "))
+	b.WriteString(`" -- "$cur"))
+		return
+	fi
+
+	case "$prev" in
+	-status) COMPREPLY=($(compgen -W "`)
+	b.WriteString(strings.Join(statuses, " "))
+	b.WriteString(`" -- "$cur")); return ;;
+	-o) COMPREPLY=($(compgen -W "`)
+	b.WriteString(strings.Join(outputFormats, " "))
+	b.WriteString(`" -- "$cur")); return ;;
+	-profile) COMPREPLY=($(compgen -W "$(` + listProfilesScript + `)" -- "$cur")); return ;;
+	esac
+
+	if [[ $cur != -* ]]; then
+		case "$cmd:$COMP_CWORD" in
+		status:3) words="`)
+	b.WriteString(strings.Join(statuses, " "))
+	b.WriteString(`" ;;
+		config:2) words="`)
+	b.WriteString(strings.Join(configCommands, " "))
+	b.WriteString(`" ;;
+		config:3) [[ $prev != list ]] && words="$(` + listProfilesScript + `)" ;;
+		completion:2) words="`)
+	b.WriteString(strings.Join(completionShells, " "))
+	b.WriteString(`" ;;
+		esac
+		COMPREPLY=($(compgen -W "$words" -- "$cur"))
+		return
+	fi
+
+	case "$cmd" in
+`)
+
+	for _, c := range commands {
+		var names []string
+		for _, f := range commandFlags(c) {
+			names = append(names, "-"+f.Name)
+		}
+		fmt.Fprintf(&b, "\t%s) words=%q ;;\n", c.name, strings.Join(names, " "))
+	}
+
+	b.WriteString(`	esac
+	COMPREPLY=($(compgen -W "$words" -- "$cur"))
+}
+complete -F _taskctl taskctl
+`)
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func writeFishCompletion(out io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("# fish completion for taskctl: taskctl completion fish | source\n")
+	b.WriteString("complete -c taskctl -f\n")
+
+	for _, c := range commands {
+		fmt.Fprintf(&b, "complete -c taskctl -n __fish_use_subcommand -a %s -d %s\n", c.name, fishQuote(c.summary))
+	}
+
+	for _, c := range commands {
+		when := fishQuote("__fish_seen_subcommand_from " + c.name)
+
+		for _, f := range commandFlags(c) {
+			fmt.Fprintf(&b, "complete -c taskctl -n %s -o %s -d %s", when, f.Name, fishQuote(f.Usage))
+
+			switch f.Name {
+			case "status":
+				fmt.Fprintf(&b, " -x -a %s", fishQuote(strings.Join(statuses, " ")))
+			case "o":
+				fmt.Fprintf(&b, " -x -a %s", fishQuote(strings.Join(outputFormats, " ")))
+			case "profile":
+				fmt.Fprintf(&b, " -x -a %s", fishQuote("("+listProfilesScript+")"))
+			default:
+				if !isBoolFlag(f) {
+					b.WriteString(" -r")
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	fmt.Fprintf(&b, "complete -c taskctl -n %s -a %s\n",
+		fishQuote("__fish_seen_subcommand_from status; and test (count (commandline -opc)) -eq 3"),
+		fishQuote(strings.Join(statuses, " ")))
+	fmt.Fprintf(&b, "complete -c taskctl -n %s -a %s\n",
+		fishQuote("__fish_seen_subcommand_from config; and test (count (commandline -opc)) -eq 2"),
+		fishQuote(strings.Join(configCommands, " ")))
+	fmt.Fprintf(&b, "complete -c taskctl -n %s -a %s\n",
+		fishQuote("__fish_seen_subcommand_from use set delete"),
+		fishQuote("("+listProfilesScript+")"))
+	fmt.Fprintf(&b, "complete -c taskctl -n %s -a %s\n",
+		fishQuote("__fish_seen_subcommand_from completion"),
+		fishQuote(strings.Join(completionShells, " ")))
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
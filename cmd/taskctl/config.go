@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// Profile is a server taskctl talks to and the identity it uses there.
+type Profile struct {
+	Server string `yaml:"server"`
+	// Token is sent as a bearer token, for gateways in front of the API.
+	Token string `yaml:"token,omitempty"`
+	// User is sent in UserHeader, X-User unless set.
+	User       string `yaml:"user,omitempty"`
+	UserHeader string `yaml:"user_header,omitempty"`
+}
+
+// Config is the profile file, by default taskctl/config.yaml in the user
+// configuration directory.
+type Config struct {
+	Current  string              `yaml:"current"`
+	Profiles map[string]*Profile `yaml:"profiles"`
+}
+
+// configPath returns TASKCTL_CONFIG or the default profile file.
+func configPath() (string, error) {
+	if path := os.Getenv("TASKCTL_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "taskctl", "config.yaml"), nil
+}
+
+// loadConfig reads the profile file; a missing file is an empty config.
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]*Profile{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+
+	return cfg, nil
+}
+
+// save writes the profile file readable by its owner only, as it holds
+// tokens.
+func (c *Config) save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (c *Config) names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// connection holds what a command was told about the server through flags.
+type connection struct {
+	profile string
+	server  string
+	token   string
+	user    string
+}
+
+// resolve picks the profile and applies, in increasing precedence, the
+// profile, the TASKCTL_* environment variables and the flags. The profile is
+// named by -profile, TASKCTL_PROFILE or the current profile of the file.
+func (c *Config) resolve(conn connection) (Profile, error) {
+	name := firstOf(conn.profile, os.Getenv("TASKCTL_PROFILE"), c.Current)
+
+	var p Profile
+	if name != "" {
+		stored, ok := c.Profiles[name]
+		if !ok {
+			return Profile{}, fmt.Errorf("unknown profile %q", name)
+		}
+		p = *stored
+	}
+
+	p.Server = firstOf(conn.server, os.Getenv("TASKCTL_SERVER"), p.Server, defaultServer)
+	p.Token = firstOf(conn.token, os.Getenv("TASKCTL_TOKEN"), p.Token)
+	p.User = firstOf(conn.user, os.Getenv("TASKCTL_USER"), p.User)
+	p.UserHeader = firstOf(p.UserHeader, "X-User")
+
+	return p, nil
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
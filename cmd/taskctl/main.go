@@ -0,0 +1,208 @@
+// Command taskctl manages tasks through the REST API of the task service.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// app is what a command runs with.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	output outputFormat
+	// client is nil for the offline commands.
+	client *client
+}
+
+type runFunc func(ctx context.Context, a *app, args []string) error
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	// offline commands do not talk to a server and take no connection
+	// flags.
+	offline bool
+	// setup registers the flags of the command and returns its body.
+	setup func(fs *flag.FlagSet) runFunc
+}
+
+var commands []command
+
+func init() {
+	// Assigned here because completion reads the table it is part of.
+	commands = []command{
+		{name: "create", args: "TITLE", summary: "create a task", setup: setupCreate},
+		{name: "list", summary: "list tasks, filtered like GET /tasks", setup: setupList},
+		{name: "get", args: "ID...", summary: "show tasks", setup: setupGet},
+		{name: "status", args: "ID STATUS", summary: "update the status of a task", setup: setupStatus},
+		{name: "delete", args: "ID...", summary: "delete tasks", setup: setupDelete},
+		{name: "board", summary: "browse and move tasks on a board grouped by status", setup: setupBoard},
+		{name: "config", args: "list|use|set|delete", summary: "manage server profiles", offline: true, setup: setupConfig},
+		{name: "completion", args: "bash|zsh|fish", summary: "print a shell completion script", offline: true, setup: setupCompletion},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func usage() string {
+	var b strings.Builder
+	b.WriteString("usage: taskctl COMMAND [flags] [args]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "  %-30s %s\n", strings.TrimSpace(c.name+" "+c.args), c.summary)
+	}
+	b.WriteString(`
+Commands that call the server take -profile, -server, -token, -user and
+-o table|json|yaml. The server and identity come from the flags, then the
+TASKCTL_SERVER, TASKCTL_TOKEN and TASKCTL_USER variables, then the profile
+chosen with -profile, TASKCTL_PROFILE or "taskctl config use". Run
+"taskctl COMMAND -h" to list the flags of a command.`)
+	return b.String()
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run runs the command line args and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintln(stdout, usage())
+		return 0
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		fmt.Fprintf(stderr, "taskctl: unknown command %q\n%s\n", args[0], usage())
+		return 2
+	}
+
+	fs := flag.NewFlagSet("taskctl "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: taskctl %s [flags] %s\n\n%s\n\nflags:\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+
+	var conn connection
+	output := "table"
+	if !cmd.offline {
+		addConnectionFlags(fs, &conn, &output)
+	}
+
+	body := cmd.setup(fs)
+
+	rest, err := parseArgs(fs, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	if !cmd.offline {
+		if a.output, err = parseOutput(output); err != nil {
+			return fail(stderr, err)
+		}
+
+		profile, err := loadProfile(conn)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		if a.client, err = newClient(profile); err != nil {
+			return fail(stderr, err)
+		}
+	}
+
+	if err := body(ctx, a, rest); err != nil {
+		return fail(stderr, err)
+	}
+	return 0
+}
+
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "taskctl: %v\n", err)
+	return 1
+}
+
+func addConnectionFlags(fs *flag.FlagSet, conn *connection, output *string) {
+	fs.StringVar(&conn.profile, "profile", "", "profile to use instead of the current one")
+	fs.StringVar(&conn.server, "server", "", "server URL, e.g. "+defaultServer)
+	fs.StringVar(&conn.token, "token", "", "bearer token")
+	fs.StringVar(&conn.user, "user", "", "user to act as")
+	fs.StringVar(output, "o", *output, "output format: table, json or yaml")
+}
+
+func loadProfile(conn connection) (Profile, error) {
+	path, err := configPath()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	return cfg.resolve(conn)
+}
+
+// parseArgs parses flags that appear between and after the positional
+// arguments, which the flag package stops at, and returns the positional
+// ones. Everything after "--" is positional.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// stringList is a flag that may be repeated or hold comma separated values.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"graph-task-service/internal/handler/http"
+	"graph-task-service/internal/health"
+	"graph-task-service/internal/observability"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/router"
+	"graph-task-service/internal/service"
+	"io"
+	"log/slog"
+	nethttp "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// newTestServer serves the real router from memory and points taskctl at
+// it through the environment. The profile file starts out missing.
+func newTestServer(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+
+	tasks := memory.NewTaskRepository()
+	imports := service.NewImportService(tasks, memory.NewImportJobRepository(), nil, 1, 1)
+	t.Cleanup(func() { imports.Close(context.Background()) })
+
+	r := router.New(
+		http.NewTaskHandler(service.NewTaskService(tasks, nil)),
+		http.NewImportHandler(imports),
+		nil,
+		nil,
+		nil,
+		http.NewHealthHandler(health.NewRegistry(time.Second)),
+		nil,
+		observability.NewMetrics(),
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		router.Options{MaxBodyBytes: 1 << 20, MaxImportBytes: 1 << 20, UserHeader: "X-User"},
+	)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	t.Setenv("TASKCTL_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("TASKCTL_SERVER", srv.URL)
+	t.Setenv("TASKCTL_PROFILE", "")
+	t.Setenv("TASKCTL_TOKEN", "")
+	t.Setenv("TASKCTL_USER", "")
+
+	return srv
+}
+
+func runTaskctl(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(""), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+// mustRun runs taskctl and fails the test unless it succeeds.
+func mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+
+	stdout, stderr, code := runTaskctl(t, args...)
+	require.Equal(t, 0, code, stderr)
+	return stdout
+}
+
+func createTask(t *testing.T, args ...string) Task {
+	t.Helper()
+
+	var task Task
+	require.NoError(t, json.Unmarshal([]byte(mustRun(t, append([]string{"create", "-o", "json"}, args...)...)), &task))
+	return task
+}
+
+func TestCreateGetStatusDelete(t *testing.T) {
+	newTestServer(t)
+
+	// Flags may follow the title.
+	task := createTask(t, "Fix", "login", "-assignee", "alice,bob", "-watcher", "carol", "-status", "in_progress")
+	assert.Equal(t, "Fix login", task.Title)
+	assert.Equal(t, "in_progress", task.Status)
+	assert.Equal(t, []string{"alice", "bob"}, task.Assignees)
+	assert.Equal(t, []string{"carol"}, task.Watchers)
+
+	var got Task
+	require.NoError(t, yaml.Unmarshal([]byte(mustRun(t, "get", task.ID, "-o", "yaml")), &got))
+	assert.Equal(t, task, got)
+
+	out := mustRun(t, "status", task.ID, "done")
+	assert.Contains(t, out, "STATUS")
+	assert.Contains(t, out, "done")
+
+	assert.Equal(t, "deleted "+task.ID+"\n", mustRun(t, "delete", task.ID))
+
+	_, stderr, code := runTaskctl(t, "get", task.ID)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "404 Not Found: task not found")
+}
+
+func TestList(t *testing.T) {
+	newTestServer(t)
+
+	createTask(t, "first", "-assignee", "alice")
+	createTask(t, "second", "-watcher", "alice", "-status", "done")
+	createTask(t, "third", "-assignee", "bob")
+
+	list := func(args ...string) []string {
+		var tasks []Task
+		require.NoError(t, json.Unmarshal([]byte(mustRun(t, append([]string{"list", "-o", "json"}, args...)...)), &tasks))
+
+		titles := make([]string, len(tasks))
+		for i, task := range tasks {
+			titles[i] = task.Title
+		}
+		return titles
+	}
+
+	assert.Equal(t, []string{"third", "second", "first"}, list())
+	assert.Equal(t, []string{"first"}, list("-assignee", "alice"))
+	assert.Equal(t, []string{"second"}, list("-status", "done"))
+	assert.Equal(t, []string{"second"}, list("-limit", "1", "-offset", "1"))
+	assert.Equal(t, []string{"third", "second", "first"}, list("-all", "-limit", "1"))
+	assert.Equal(t, []string{"second", "first"}, list("-mine", "-user", "alice"))
+	assert.Equal(t, []string{}, list("-watcher", "nobody"))
+
+	_, stderr, code := runTaskctl(t, "list", "-status", "blocked")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, `invalid status "blocked"`)
+}
+
+func TestConnectionHeaders(t *testing.T) {
+	var got nethttp.Header
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	t.Setenv("TASKCTL_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("TASKCTL_SERVER", "")
+	t.Setenv("TASKCTL_TOKEN", "secret")
+
+	mustRun(t, "config", "set", "gw", "-server", srv.URL, "-user", "alice", "-user-header", "X-Forwarded-User")
+	mustRun(t, "list")
+
+	assert.Equal(t, "Bearer secret", got.Get("Authorization"))
+	assert.Equal(t, "alice", got.Get("X-Forwarded-User"))
+}
+
+func TestConfigProfiles(t *testing.T) {
+	t.Setenv("TASKCTL_CONFIG", filepath.Join(t.TempDir(), "taskctl", "config.yaml"))
+	t.Setenv("TASKCTL_PROFILE", "")
+	t.Setenv("TASKCTL_SERVER", "")
+	t.Setenv("TASKCTL_TOKEN", "")
+	t.Setenv("TASKCTL_USER", "")
+
+	mustRun(t, "config", "set", "local", "-server", "http://localhost:8080", "-user", "alice")
+	mustRun(t, "config", "set", "prod", "-server", "https://tasks.example.com", "-token", "t0ken")
+
+	out := mustRun(t, "config", "list")
+	assert.Contains(t, out, "*        local")
+	assert.Contains(t, out, "****")
+	assert.NotContains(t, out, "t0ken")
+	assert.Equal(t, "local\nprod\n", mustRun(t, "config", "list", "-q"))
+
+	path, err := configPath()
+	require.NoError(t, err)
+	load := func() *Config {
+		cfg, err := loadConfig(path)
+		require.NoError(t, err)
+		return cfg
+	}
+
+	p, err := load().resolve(connection{})
+	require.NoError(t, err)
+	assert.Equal(t, Profile{Server: "http://localhost:8080", User: "alice", UserHeader: "X-User"}, p)
+
+	mustRun(t, "config", "use", "prod")
+	p, err = load().resolve(connection{user: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, Profile{Server: "https://tasks.example.com", Token: "t0ken", User: "bob", UserHeader: "X-User"}, p)
+
+	// The environment beats the profile, flags beat the environment.
+	t.Setenv("TASKCTL_PROFILE", "local")
+	t.Setenv("TASKCTL_SERVER", "http://env:1")
+	p, err = load().resolve(connection{})
+	require.NoError(t, err)
+	assert.Equal(t, "http://env:1", p.Server)
+	assert.Equal(t, "alice", p.User)
+	p, err = load().resolve(connection{profile: "prod", server: "http://flag:2"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://flag:2", p.Server)
+	assert.Equal(t, "t0ken", p.Token)
+
+	_, err = load().resolve(connection{profile: "staging"})
+	assert.ErrorContains(t, err, `unknown profile "staging"`)
+
+	mustRun(t, "config", "delete", "prod")
+	assert.Equal(t, "", load().Current)
+	assert.Equal(t, []string{"local"}, load().names())
+
+	_, stderr, code := runTaskctl(t, "config", "use", "prod")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "unknown profile")
+}
+
+func TestCompletion(t *testing.T) {
+	bash := mustRun(t, "completion", "bash")
+	assert.Contains(t, bash, "complete -F _taskctl taskctl")
+	assert.Contains(t, bash, `"create list get status delete board config completion"`)
+	assert.Contains(t, bash, `list) words="-all -assignee -limit -mine -o -offset -profile -server -status -token -user -watcher"`)
+	assert.Contains(t, bash, `"todo in_progress done"`)
+
+	assert.Contains(t, mustRun(t, "completion", "zsh"), "bashcompinit")
+
+	fish := mustRun(t, "completion", "fish")
+	assert.Contains(t, fish, "complete -c taskctl -n __fish_use_subcommand -a board")
+	assert.Contains(t, fish, `complete -c taskctl -n '__fish_seen_subcommand_from list' -o status -d 'only tasks with this status' -x -a 'todo in_progress done'`)
+
+	_, stderr, code := runTaskctl(t, "completion", "tcsh")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "unsupported shell")
+}
+
+func TestUsageErrors(t *testing.T) {
+	stdout, _, code := runTaskctl(t)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "status ID STATUS")
+
+	_, stderr, code := runTaskctl(t, "frobnicate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "frobnicate"`)
+
+	_, _, code = runTaskctl(t, "list", "-nope")
+	assert.Equal(t, 2, code)
+
+	_, stderr, code = runTaskctl(t, "list", "-o", "xml")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "unsupported output")
+}
+
+func TestParseArgs(t *testing.T) {
+	newFlags := func() (*stringList, *bool, func([]string) ([]string, error)) {
+		var users stringList
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.Var(&users, "user", "")
+		quiet := fs.Bool("q", false, "")
+		return &users, quiet, func(args []string) ([]string, error) { return parseArgs(fs, args) }
+	}
+
+	users, quiet, parse := newFlags()
+	args, err := parse([]string{"a", "-user", "x", "b", "-q", "-user", "y,z"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, args)
+	assert.Equal(t, stringList{"x", "y", "z"}, *users)
+	assert.True(t, *quiet)
+
+	_, quiet, parse = newFlags()
+	args, err = parse([]string{"a", "--", "-q", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "-q", "b"}, args)
+	assert.False(t, *quiet)
+}
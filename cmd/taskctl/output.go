@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+	outputYAML  outputFormat = "yaml"
+)
+
+var outputFormats = []string{string(outputTable), string(outputJSON), string(outputYAML)}
+
+func parseOutput(s string) (outputFormat, error) {
+	switch f := outputFormat(strings.ToLower(s)); f {
+	case outputTable, outputJSON, outputYAML:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported output %q, use table, json or yaml", s)
+	}
+}
+
+// printTasks writes tasks as a table, or as a JSON or YAML list.
+func printTasks(out io.Writer, format outputFormat, tasks []Task) error {
+	if tasks == nil {
+		tasks = []Task{}
+	}
+
+	switch format {
+	case outputJSON:
+		return printJSON(out, tasks)
+	case outputYAML:
+		return printYAML(out, tasks)
+	default:
+		return printTable(out, tasks)
+	}
+}
+
+// printTask writes one task, as an object rather than a list in JSON and
+// YAML.
+func printTask(out io.Writer, format outputFormat, task *Task) error {
+	switch format {
+	case outputJSON:
+		return printJSON(out, task)
+	case outputYAML:
+		return printYAML(out, task)
+	default:
+		return printTable(out, []Task{*task})
+	}
+}
+
+func printTable(out io.Writer, tasks []Task) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tASSIGNEES\tWATCHERS\tUPDATED")
+
+	for _, t := range tasks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.ID,
+			truncate(t.Title, 50),
+			t.Status,
+			strings.Join(t.Assignees, ","),
+			strings.Join(t.Watchers, ","),
+			t.UpdatedAt,
+		)
+	}
+
+	return w.Flush()
+}
+
+func printJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func printYAML(out io.Writer, v any) error {
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(r[:n])
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -1,13 +1,261 @@
 package config
 
+import "time"
+
+// Config is the whole service configuration. Values are resolved from
+// Default, then an optional YAML file, then environment variables, then
+// command line flags; see Load.
+//
+// Every leaf field declares its YAML key, its environment variable and a
+// usage line. The flag name is the YAML path with dashes, e.g.
+// -server.read-timeout. Fields tagged secret are masked when printed.
 type Config struct {
-	AppPort string
-	DBURL   string
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Log         LogConfig         `yaml:"log"`
+	Features    FeatureConfig     `yaml:"features"`
+	Workers     WorkerConfig      `yaml:"workers"`
+	Events      EventConfig       `yaml:"events"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Cache       CacheConfig       `yaml:"cache"`
+	Import      ImportConfig      `yaml:"import"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Health      HealthConfig      `yaml:"health"`
+	GraphQL     GraphQLConfig     `yaml:"graphql"`
+}
+
+type ServerConfig struct {
+	Port string `yaml:"port" env:"APP_PORT" usage:"HTTP listen port"`
+	// GRPCPort serves the gRPC task API next to the REST one; empty
+	// disables it.
+	GRPCPort          string        `yaml:"grpc_port" env:"GRPC_PORT" usage:"gRPC listen port, empty to disable gRPC"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT" usage:"maximum duration for reading a request"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT" usage:"maximum duration for reading request headers"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT" usage:"maximum duration for writing a response"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" usage:"keep-alive idle timeout"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES" usage:"maximum size of request headers"`
+	// MaxBodyBytes caps request bodies; larger ones get 413.
+	MaxBodyBytes int64 `yaml:"max_body_bytes" env:"HTTP_MAX_BODY_BYTES" usage:"maximum size of request bodies"`
+	// ShutdownDelay is how long the server keeps serving, with readiness
+	// failing, after SIGINT or SIGTERM.
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env:"SHUTDOWN_DELAY" usage:"time to keep serving after a shutdown signal"`
+	// ShutdownTimeout is how long in-flight requests get to finish.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" usage:"time in-flight requests get to finish on shutdown"`
+}
+
+type DatabaseConfig struct {
+	URL string `yaml:"url" env:"DATABASE_URL" secret:"url" usage:"Postgres connection URL"`
+	// Driver selects the task repository: stdlib goes through database/sql,
+	// pgxpool uses a native pgx pool with prepared statements and batching.
+	// sqlite and memory need no Postgres and serve the task API only.
+	Driver string `yaml:"driver" env:"DB_DRIVER" usage:"stdlib, pgxpool, sqlite or memory"`
+	// SQLitePath is the database file of the sqlite driver.
+	SQLitePath      string        `yaml:"sqlite_path" env:"DB_SQLITE_PATH" usage:"SQLite database file of the sqlite driver"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" usage:"maximum open connections, 0 for unlimited"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" usage:"maximum idle connections"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" usage:"maximum lifetime of a connection, 0 for unlimited"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" usage:"maximum idle time of a connection, 0 for unlimited"`
+	// StatementTimeout is enforced by Postgres on every statement.
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT" usage:"cancel statements running longer, 0 to disable"`
+	ConnectAttempts  int           `yaml:"connect_attempts" env:"DB_CONNECT_ATTEMPTS" usage:"attempts to reach the database on startup"`
+	ConnectBackoff   time.Duration `yaml:"connect_backoff" env:"DB_CONNECT_BACKOFF" usage:"initial wait between startup connection attempts"`
+	// RetryAttempts bounds how often a repository operation that failed
+	// with a serialization or transient connection error is run.
+	RetryAttempts int           `yaml:"retry_attempts" env:"DB_RETRY_ATTEMPTS" usage:"attempts of an operation failing transiently"`
+	RetryBackoff  time.Duration `yaml:"retry_backoff" env:"DB_RETRY_BACKOFF" usage:"initial wait between operation attempts"`
+	// IsolationLevel applies to service operations that span several
+	// statements, such as read-modify-write updates of a task.
+	IsolationLevel string `yaml:"isolation_level" env:"DB_ISOLATION_LEVEL" usage:"read_committed, repeatable_read or serializable"`
+}
+
+// UsesPostgres reports whether the driver talks to Postgres, which webhooks,
+// idempotency keys, event streams and migrations require.
+func (c DatabaseConfig) UsesPostgres() bool {
+	return c.Driver == "stdlib" || c.Driver == "pgxpool"
+}
+
+type AuthConfig struct {
+	// UserHeader carries the caller identity set by the upstream gateway.
+	UserHeader string `yaml:"user_header" env:"AUTH_USER_HEADER" usage:"request header carrying the authenticated user"`
+	// Required rejects anonymous requests to the API with 401.
+	Required bool `yaml:"required" env:"AUTH_REQUIRED" usage:"reject API requests without a user"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" env:"LOG_LEVEL" usage:"debug, info, warn or error"`
+}
+
+type FeatureConfig struct {
+	Swagger       bool `yaml:"swagger" env:"ENABLE_SWAGGER" usage:"serve the Swagger UI at /swagger"`
+	RunMigrations bool `yaml:"run_migrations" env:"RUN_MIGRATIONS" usage:"apply pending migrations on startup"`
+}
+
+type WorkerConfig struct {
+	WebhookPollInterval      time.Duration `yaml:"webhook_poll_interval" env:"WEBHOOK_POLL_INTERVAL" usage:"how often due webhook deliveries are sent"`
+	WebhookMaxAttempts       int           `yaml:"webhook_max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" usage:"delivery attempts before a webhook delivery fails"`
+	WebhookTimeout           time.Duration `yaml:"webhook_timeout" env:"WEBHOOK_TIMEOUT" usage:"timeout of one webhook delivery"`
+	WebhookLease             time.Duration `yaml:"webhook_lease" env:"WEBHOOK_LEASE" usage:"how long claimed webhook deliveries are hidden from other instances"`
+	OutboxPollInterval       time.Duration `yaml:"outbox_poll_interval" env:"OUTBOX_POLL_INTERVAL" usage:"how often the outbox is relayed"`
+	IdempotencyPurgeInterval time.Duration `yaml:"idempotency_purge_interval" env:"IDEMPOTENCY_PURGE_INTERVAL" usage:"how often expired idempotency keys are deleted"`
+}
+
+type EventConfig struct {
+	// Publishers lists where outbox events go: memory, webhook, nats, kafka.
+	Publishers   []string `yaml:"publishers" env:"EVENT_PUBLISHERS" usage:"comma separated list of memory, webhook, nats, kafka"`
+	NATSURL      string   `yaml:"nats_url" env:"NATS_URL" secret:"url" usage:"NATS server URL"`
+	NATSSubject  string   `yaml:"nats_subject" env:"NATS_SUBJECT" usage:"NATS subject prefix"`
+	KafkaBrokers []string `yaml:"kafka_brokers" env:"KAFKA_BROKERS" usage:"comma separated Kafka brokers"`
+	KafkaTopic   string   `yaml:"kafka_topic" env:"KAFKA_TOPIC" usage:"Kafka topic"`
+	// StreamBuffer is how many events a streaming client may lag behind
+	// before it is disconnected.
+	StreamBuffer int `yaml:"stream_buffer" env:"STREAM_BUFFER" usage:"events a streaming client may lag behind"`
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long responses to requests with an Idempotency-Key are
+	// kept for replay.
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" usage:"how long idempotent responses are kept"`
+	// Lease is how long a request holds its key before it has a response;
+	// the key of a request that died mid-flight is free again after it.
+	Lease time.Duration `yaml:"lease" env:"IDEMPOTENCY_LEASE" usage:"how long a request without a response holds its idempotency key"`
+}
+
+type CacheConfig struct {
+	// Backend is none, memory (an LRU per instance) or redis (shared by
+	// all instances).
+	Backend string `yaml:"backend" env:"CACHE_BACKEND" usage:"none, memory or redis"`
+	// Size is how many entries the memory backend keeps.
+	Size int `yaml:"size" env:"CACHE_SIZE" usage:"entries kept by the memory backend"`
+	// TTL and ListTTL also bound how long an instance of the memory backend
+	// serves tasks changed through another instance.
+	TTL       time.Duration `yaml:"ttl" env:"CACHE_TTL" usage:"how long a task stays cached"`
+	ListTTL   time.Duration `yaml:"list_ttl" env:"CACHE_LIST_TTL" usage:"how long a task list stays cached"`
+	RedisURL  string        `yaml:"redis_url" env:"REDIS_URL" secret:"url" usage:"Redis URL of the redis backend"`
+	KeyPrefix string        `yaml:"key_prefix" env:"CACHE_KEY_PREFIX" usage:"prefix of the Redis keys"`
+}
+
+type ImportConfig struct {
+	// MaxBytes caps uploads to POST /tasks/import, which are usually larger
+	// than server.max_body_bytes allows.
+	MaxBytes int64 `yaml:"max_bytes" env:"IMPORT_MAX_BYTES" usage:"maximum size of an uploaded import file"`
+	// Workers is how many asynchronous imports run at once per instance;
+	// further jobs wait as pending.
+	Workers int `yaml:"workers" env:"IMPORT_WORKERS" usage:"asynchronous imports run at once"`
+	// Queue is how many more asynchronous imports may wait as pending per
+	// instance, each holding its file in memory; further ones are refused.
+	Queue int `yaml:"queue" env:"IMPORT_QUEUE" usage:"asynchronous imports that may wait as pending"`
+}
+
+type TracingConfig struct {
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME" usage:"service name reported in traces"`
+	// Exporter is one of none, stdout or otlp.
+	Exporter     string  `yaml:"exporter" env:"TRACE_EXPORTER" usage:"none, stdout or otlp"`
+	OTLPProtocol string  `yaml:"otlp_protocol" env:"OTEL_EXPORTER_OTLP_PROTOCOL" usage:"grpc or http"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" usage:"OTLP collector endpoint"`
+	OTLPInsecure bool    `yaml:"otlp_insecure" env:"OTEL_EXPORTER_OTLP_INSECURE" usage:"disable TLS to the collector"`
+	Sampler      string  `yaml:"sampler" env:"TRACE_SAMPLER" usage:"always, never or ratio"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"TRACE_SAMPLE_RATIO" usage:"share of traces sampled with the ratio sampler"`
+}
+
+type HealthConfig struct {
+	// CheckTimeout bounds each readiness check.
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" usage:"timeout of each readiness check"`
+	// PoolSaturationThreshold is the share of open connections in use at
+	// which the instance reports itself not ready.
+	PoolSaturationThreshold float64 `yaml:"pool_saturation_threshold" env:"POOL_SATURATION_THRESHOLD" usage:"share of busy connections that fails readiness"`
+}
+
+type GraphQLConfig struct {
+	// MaxDepth bounds how deeply the selections of an operation nest.
+	MaxDepth int `yaml:"max_depth" env:"GRAPHQL_MAX_DEPTH" usage:"maximum nesting of GraphQL selections"`
+	// MaxComplexity rejects operations whose estimated cost, counting every
+	// field once per item of the lists around it, is higher.
+	MaxComplexity int `yaml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" usage:"maximum estimated cost of a GraphQL operation"`
 }
 
-func Load() *Config {
+func Default() *Config {
 	return &Config{
-		AppPort: getEnv("APP_PORT", "8080"),
-		DBURL:   getEnv("DATABASE_URL", ""),
+		Server: ServerConfig{
+			Port:              "8080",
+			GRPCPort:          "9090",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    1 << 20,
+			MaxBodyBytes:      1 << 20,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:           "stdlib",
+			SQLitePath:       "graph-task-service.db",
+			MaxOpenConns:     25,
+			MaxIdleConns:     25,
+			ConnMaxLifetime:  30 * time.Minute,
+			ConnMaxIdleTime:  5 * time.Minute,
+			StatementTimeout: 30 * time.Second,
+			ConnectAttempts:  10,
+			ConnectBackoff:   500 * time.Millisecond,
+			RetryAttempts:    3,
+			RetryBackoff:     50 * time.Millisecond,
+			IsolationLevel:   "repeatable_read",
+		},
+		Auth: AuthConfig{
+			UserHeader: "X-User",
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Workers: WorkerConfig{
+			WebhookPollInterval:      2 * time.Second,
+			WebhookMaxAttempts:       8,
+			WebhookTimeout:           10 * time.Second,
+			WebhookLease:             4 * time.Minute,
+			OutboxPollInterval:       time.Second,
+			IdempotencyPurgeInterval: time.Hour,
+		},
+		Events: EventConfig{
+			Publishers:   []string{"webhook"},
+			NATSURL:      "nats://localhost:4222",
+			NATSSubject:  "tasks",
+			KafkaBrokers: []string{"localhost:9092"},
+			KafkaTopic:   "tasks",
+			StreamBuffer: 256,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:   24 * time.Hour,
+			Lease: time.Minute,
+		},
+		Cache: CacheConfig{
+			Backend:   "none",
+			Size:      10000,
+			TTL:       time.Minute,
+			ListTTL:   10 * time.Second,
+			RedisURL:  "redis://localhost:6379/0",
+			KeyPrefix: "graph-task-service:",
+		},
+		Import: ImportConfig{
+			MaxBytes: 32 << 20,
+			Workers:  2,
+			Queue:    8,
+		},
+		Tracing: TracingConfig{
+			ServiceName:  "graph-task-service",
+			Exporter:     "none",
+			OTLPProtocol: "grpc",
+			OTLPEndpoint: "localhost:4317",
+			OTLPInsecure: true,
+			Sampler:      "always",
+			SampleRatio:  1,
+		},
+		Health: HealthConfig{
+			CheckTimeout:            2 * time.Second,
+			PoolSaturationThreshold: 0.9,
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      15,
+			MaxComplexity: 1000,
+		},
 	}
 }
@@ -1,10 +0,0 @@
-package config
-
-import "os"
-
-func getEnv(key, defaultValue string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
-	}
-	return defaultValue
-}
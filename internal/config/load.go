@@ -0,0 +1,279 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the YAML config file when -config is not given.
+const FileEnv = "CONFIG_FILE"
+
+// field is one leaf setting of Config.
+type field struct {
+	path   string // YAML path, e.g. server.read_timeout
+	env    string
+	usage  string
+	secret string
+	value  reflect.Value
+}
+
+func (f field) flagName() string {
+	return strings.ReplaceAll(f.path, "_", "-")
+}
+
+func fields(cfg *Config) []field {
+	var out []field
+	walk(reflect.ValueOf(cfg).Elem(), "", &out)
+	return out
+}
+
+func walk(v reflect.Value, prefix string, out *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		path := sf.Tag.Get("yaml")
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		if sf.Type.Kind() == reflect.Struct && sf.Type != durationType {
+			walk(v.Field(i), path, out)
+			continue
+		}
+
+		*out = append(*out, field{
+			path:   path,
+			env:    sf.Tag.Get("env"),
+			usage:  sf.Tag.Get("usage"),
+			secret: sf.Tag.Get("secret"),
+			value:  v.Field(i),
+		})
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func set(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		// Comma separated, blank entries ignored.
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func format(v reflect.Value) string {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Kind() == reflect.Slice {
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// flagValue records a flag so it can be applied after the file and the
+// environment.
+type flagValue struct {
+	isBool bool
+	raw    string
+}
+
+func (f *flagValue) String() string     { return f.raw }
+func (f *flagValue) Set(s string) error { f.raw = s; return nil }
+func (f *flagValue) IsBoolFlag() bool   { return f.isBool }
+
+// Load resolves the configuration from defaults, the YAML file named by
+// -config or CONFIG_FILE, environment variables and the flags in args, in
+// that order of precedence. It returns the arguments left after the flags,
+// i.e. the subcommand. Every invalid value is reported, not just the first;
+// call Validate for semantic checks.
+func Load(args []string) (*Config, []string, error) {
+	cfg := Default()
+	all := fields(cfg)
+
+	fs, configFile, values := newFlagSet(all)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	var errs []error
+
+	if *configFile != "" {
+		if err := loadFile(cfg, *configFile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, f := range all {
+		raw, ok := os.LookupEnv(f.env)
+		if !ok || f.env == "" || raw == "" {
+			continue
+		}
+		if err := set(f.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q: %w", f.env, raw, err))
+		}
+	}
+
+	fs.Visit(func(fl *flag.Flag) {
+		for _, f := range all {
+			if f.flagName() != fl.Name {
+				continue
+			}
+			if err := set(f.value, values[fl.Name].raw); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: invalid value %q: %w", fl.Name, values[fl.Name].raw, err))
+			}
+		}
+	})
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+// PrintFlags writes the usage of every flag Load accepts.
+func PrintFlags(w io.Writer) {
+	fs, _, _ := newFlagSet(fields(Default()))
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+func newFlagSet(all []field) (*flag.FlagSet, *string, map[string]*flagValue) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	configFile := fs.String("config", os.Getenv(FileEnv), "YAML config file")
+
+	values := make(map[string]*flagValue, len(all))
+	for _, f := range all {
+		v := &flagValue{isBool: f.value.Kind() == reflect.Bool}
+		values[f.flagName()] = v
+		fs.Var(v, f.flagName(), fmt.Sprintf("%s (%s)", f.usage, f.env))
+	}
+
+	return fs, configFile, values
+}
+
+// loadFile overlays the keys present in a YAML file; absent keys keep their
+// defaults.
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+
+	known := make(map[string]field)
+	for _, f := range fields(cfg) {
+		known[f.path] = f
+	}
+
+	var errs []error
+	overlay(root.Content[0], "", known, &errs)
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func overlay(node *yaml.Node, prefix string, known map[string]field, errs *[]error) {
+	if node.Kind != yaml.MappingNode {
+		*errs = append(*errs, fmt.Errorf("line %d: %s must be a mapping", node.Line, prefixOrRoot(prefix)))
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+
+		f, ok := known[path]
+		if !ok {
+			if value.Kind == yaml.MappingNode {
+				overlay(value, path, known, errs)
+			} else {
+				*errs = append(*errs, fmt.Errorf("line %d: unknown key %s", key.Line, path))
+			}
+			continue
+		}
+
+		raw := value.Value
+		if value.Kind == yaml.SequenceNode {
+			items := make([]string, 0, len(value.Content))
+			for _, item := range value.Content {
+				items = append(items, item.Value)
+			}
+			raw = strings.Join(items, ",")
+		}
+
+		if err := set(f.value, raw); err != nil {
+			*errs = append(*errs, fmt.Errorf("line %d: %s: invalid value %q: %w", value.Line, path, raw, err))
+		}
+	}
+}
+
+func prefixOrRoot(prefix string) string {
+	if prefix == "" {
+		return "document"
+	}
+	return prefix
+}
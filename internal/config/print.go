@@ -0,0 +1,78 @@
+package config
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const mask = "****"
+
+// Print writes the effective configuration as YAML, the format Load reads,
+// with secrets masked. URLs keep everything but their password.
+func Print(w io.Writer, cfg *Config) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+
+	sections := make(map[string]*yaml.Node)
+	for _, f := range fields(cfg) {
+		section, key, _ := strings.Cut(f.path, ".")
+
+		node, ok := sections[section]
+		if !ok {
+			node = &yaml.Node{Kind: yaml.MappingNode}
+			sections[section] = node
+			root.Content = append(root.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: section},
+				node,
+			)
+		}
+
+		var value *yaml.Node
+		if items, ok := f.value.Interface().([]string); ok {
+			value = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, LineComment: f.env}
+			for _, item := range items {
+				value.Content = append(value.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+			}
+		} else {
+			value = &yaml.Node{Kind: yaml.ScalarNode, Value: masked(f.secret, format(f.value))}
+		}
+
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key, LineComment: f.env},
+			value,
+		)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func masked(kind, value string) string {
+	if kind == "" || value == "" {
+		return value
+	}
+
+	if kind == "url" {
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" {
+			// Not a URL, e.g. a key=value DSN: nothing is safe to show.
+			return mask
+		}
+		if q := u.Query(); q.Has("password") {
+			q.Set("password", mask)
+			u.RawQuery = q.Encode()
+		}
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), mask)
+		}
+		return strings.ReplaceAll(u.String(), url.QueryEscape(mask), mask)
+	}
+
+	return mask
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var v validator
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		v.failf("server.port: %q is not a valid port", c.Server.Port)
+	}
+	if c.Server.GRPCPort != "" {
+		if port, err := strconv.Atoi(c.Server.GRPCPort); err != nil || port < 1 || port > 65535 {
+			v.failf("server.grpc_port: %q is not a valid port", c.Server.GRPCPort)
+		} else if c.Server.GRPCPort == c.Server.Port {
+			v.failf("server.grpc_port: must differ from server.port")
+		}
+	}
+	v.notNegative("server.read_timeout", c.Server.ReadTimeout)
+	v.notNegative("server.read_header_timeout", c.Server.ReadHeaderTimeout)
+	v.notNegative("server.write_timeout", c.Server.WriteTimeout)
+	v.notNegative("server.idle_timeout", c.Server.IdleTimeout)
+	v.notNegative("server.shutdown_delay", c.Server.ShutdownDelay)
+	v.positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	if c.Server.MaxHeaderBytes <= 0 {
+		v.failf("server.max_header_bytes: must be positive")
+	}
+	if c.Server.MaxBodyBytes <= 0 {
+		v.failf("server.max_body_bytes: must be positive")
+	}
+
+	v.oneOf("database.driver", c.Database.Driver, "stdlib", "pgxpool", "sqlite", "memory")
+	switch {
+	case c.Database.Driver == "sqlite":
+		if c.Database.SQLitePath == "" {
+			v.failf("database.sqlite_path: is required with the sqlite driver")
+		}
+	case !c.Database.UsesPostgres():
+	case c.Database.URL == "":
+		v.failf("database.url: is required")
+	default:
+		if u, err := url.Parse(c.Database.URL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			v.failf("database.url: must be a postgres:// URL")
+		}
+	}
+	if c.Database.MaxOpenConns < 0 {
+		v.failf("database.max_open_conns: must not be negative")
+	}
+	if c.Database.MaxIdleConns < 0 {
+		v.failf("database.max_idle_conns: must not be negative")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		v.failf("database.max_idle_conns: %d exceeds max_open_conns %d", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+	v.notNegative("database.conn_max_lifetime", c.Database.ConnMaxLifetime)
+	v.notNegative("database.conn_max_idle_time", c.Database.ConnMaxIdleTime)
+	v.notNegative("database.statement_timeout", c.Database.StatementTimeout)
+	if c.Database.ConnectAttempts < 1 {
+		v.failf("database.connect_attempts: must be at least 1")
+	}
+	v.positive("database.connect_backoff", c.Database.ConnectBackoff)
+	if c.Database.RetryAttempts < 1 {
+		v.failf("database.retry_attempts: must be at least 1")
+	}
+	v.positive("database.retry_backoff", c.Database.RetryBackoff)
+	v.oneOf("database.isolation_level", c.Database.IsolationLevel, "read_committed", "repeatable_read", "serializable")
+
+	if c.Auth.UserHeader == "" {
+		v.failf("auth.user_header: is required")
+	}
+
+	v.oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+
+	v.positive("workers.webhook_poll_interval", c.Workers.WebhookPollInterval)
+	v.positive("workers.webhook_timeout", c.Workers.WebhookTimeout)
+	v.positive("workers.outbox_poll_interval", c.Workers.OutboxPollInterval)
+	v.positive("workers.idempotency_purge_interval", c.Workers.IdempotencyPurgeInterval)
+	if c.Workers.WebhookLease <= c.Workers.WebhookTimeout {
+		v.failf("workers.webhook_lease: must be longer than workers.webhook_timeout")
+	}
+	if c.Workers.WebhookMaxAttempts < 1 {
+		v.failf("workers.webhook_max_attempts: must be at least 1")
+	}
+
+	if len(c.Events.Publishers) == 0 {
+		v.failf("events.publishers: at least one publisher is required")
+	}
+	for _, p := range c.Events.Publishers {
+		v.oneOf("events.publishers", p, "memory", "webhook", "nats", "kafka")
+	}
+	if slices.Contains(c.Events.Publishers, "nats") && c.Events.NATSURL == "" {
+		v.failf("events.nats_url: is required with the nats publisher")
+	}
+	if slices.Contains(c.Events.Publishers, "kafka") && len(c.Events.KafkaBrokers) == 0 {
+		v.failf("events.kafka_brokers: is required with the kafka publisher")
+	}
+	if c.Events.StreamBuffer < 1 {
+		v.failf("events.stream_buffer: must be at least 1")
+	}
+
+	v.positive("idempotency.ttl", c.Idempotency.TTL)
+	v.positive("idempotency.lease", c.Idempotency.Lease)
+
+	v.oneOf("cache.backend", c.Cache.Backend, "none", "memory", "redis")
+	if c.Cache.Size < 1 {
+		v.failf("cache.size: must be at least 1")
+	}
+	v.positive("cache.ttl", c.Cache.TTL)
+	v.positive("cache.list_ttl", c.Cache.ListTTL)
+	if c.Cache.Backend == "redis" {
+		if u, err := url.Parse(c.Cache.RedisURL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") {
+			v.failf("cache.redis_url: must be a redis:// URL")
+		}
+	}
+
+	if c.Import.MaxBytes <= 0 {
+		v.failf("import.max_bytes: must be positive")
+	}
+	if c.Import.Workers < 1 {
+		v.failf("import.workers: must be at least 1")
+	}
+	if c.Import.Queue < 0 {
+		v.failf("import.queue: must not be negative")
+	}
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "none", "stdout", "otlp")
+	v.oneOf("tracing.otlp_protocol", c.Tracing.OTLPProtocol, "grpc", "http")
+	v.oneOf("tracing.sampler", c.Tracing.Sampler, "always", "never", "ratio")
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		v.failf("tracing.sample_ratio: must be between 0 and 1")
+	}
+
+	v.positive("health.check_timeout", c.Health.CheckTimeout)
+	if c.Health.PoolSaturationThreshold <= 0 || c.Health.PoolSaturationThreshold > 1 {
+		v.failf("health.pool_saturation_threshold: must be in (0, 1]")
+	}
+
+	if c.GraphQL.MaxDepth < 1 {
+		v.failf("graphql.max_depth: must be at least 1")
+	}
+	if c.GraphQL.MaxComplexity < 1 {
+		v.failf("graphql.max_complexity: must be at least 1")
+	}
+
+	return v.err()
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) failf(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validator) positive(name string, d time.Duration) {
+	if d <= 0 {
+		v.failf("%s: must be positive", name)
+	}
+}
+
+func (v *validator) notNegative(name string, d time.Duration) {
+	if d < 0 {
+		v.failf("%s: must not be negative", name)
+	}
+}
+
+func (v *validator) oneOf(name, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.failf("%s: %q is not one of %v", name, value, allowed)
+	}
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventTaskCreated       EventType = "task.created"
+	EventTaskUpdated       EventType = "task.updated"
+	EventTaskStatusChanged EventType = "task.status_changed"
+	EventTaskDeleted       EventType = "task.deleted"
+)
+
+type TaskEvent struct {
+	ID         string    `json:"id"`
+	Type       EventType `json:"type"`
+	TaskID     string    `json:"task_id"`
+	Task       *Task     `json:"task,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func IsValidEventType(e EventType) bool {
+	switch e {
+	case EventTaskCreated,
+		EventTaskUpdated,
+		EventTaskStatusChanged,
+		EventTaskDeleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// EventPublisher forwards task lifecycle events to interested parties.
+type EventPublisher interface {
+	Publish(ctx context.Context, event TaskEvent) error
+}
+
+// SequencedEvent is a TaskEvent with its position in the event log. Streaming
+// clients use Seq as the resume token.
+type SequencedEvent struct {
+	Seq   int64
+	Event TaskEvent
+}
+
+// TaskEventFeed delivers committed task events to every replica.
+type TaskEventFeed interface {
+	// Listen calls handle for each event committed after Listen starts, in
+	// sequence order, until ctx is cancelled.
+	Listen(ctx context.Context, handle func(*SequencedEvent)) error
+	// Since returns up to limit events with a sequence greater than seq,
+	// oldest first. It stops early at a sequence that may still be
+	// committed, so no later event is returned before it.
+	Since(ctx context.Context, seq int64, limit int) ([]*SequencedEvent, error)
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "context"
+
+// ExternalRef links a task to the item of another tracker it was imported
+// from, so importing the item again updates the task.
+type ExternalRef struct {
+	// Source names the tracker, e.g. jira.
+	Source     string
+	ExternalID string
+	TaskID     string
+}
+
+type ExternalRefRepository interface {
+	// Get returns ErrNotFound when no task was imported from the item.
+	Get(ctx context.Context, source string, externalID string) (*ExternalRef, error)
+	// Put links the item to ref.TaskID, replacing an earlier link.
+	Put(ctx context.Context, ref *ExternalRef) error
+	// Lock makes syncs of source wait for each other until the unit of
+	// work of ctx ends, so two of them cannot both create the task of a new
+	// item. Outside a unit of work, and in repositories that do not join
+	// one, it does nothing.
+	Lock(ctx context.Context, source string) error
+}
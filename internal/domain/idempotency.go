@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyRecord remembers the outcome of a request sent with an
+// Idempotency-Key so retries can be answered without executing it again.
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	// Completed is false while the original request is still running.
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+type IdempotencyRepository interface {
+	// Reserve claims key for a new request with fingerprint for lease, so a
+	// request that dies mid-flight holds the key only that long. When the key
+	// is already held by an unexpired record, that record is returned with
+	// reserved set to false.
+	Reserve(ctx context.Context, key string, fingerprint string, lease time.Duration) (existing *IdempotencyRecord, reserved bool, err error)
+	// Complete stores the response to the request holding key and keeps it
+	// for ttl.
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte, ttl time.Duration) error
+	// Release forgets key so the request can be retried from scratch.
+	Release(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
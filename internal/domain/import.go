@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ImportRowError is why a row of an imported file was rejected.
+type ImportRowError struct {
+	// Line is the line of the row in the file, starting at 1.
+	Line    int
+	Message string
+}
+
+// ImportResult summarises an import. An import with an invalid row creates
+// no tasks.
+type ImportResult struct {
+	DryRun bool
+	// Rows is how many rows the file had, Invalid how many of them were
+	// rejected.
+	Rows    int
+	Invalid int
+	Created int
+	// Errors holds the first rejected rows; see Invalid for their number.
+	Errors []ImportRowError
+}
+
+// SyncResult summarises an import of items from another tracker. Items
+// imported before update their task instead of creating another one. When an
+// item is invalid nothing changes.
+type SyncResult struct {
+	DryRun    bool
+	Items     int
+	Invalid   int
+	Created   int
+	Updated   int
+	Unchanged int
+	// Errors holds the first rejected items; see Invalid for their number.
+	Errors []ImportRowError
+}
+
+type ImportStatus string
+
+const (
+	ImportPending   ImportStatus = "pending"
+	ImportRunning   ImportStatus = "running"
+	ImportSucceeded ImportStatus = "succeeded"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ImportJob is an import running in the background. A job fails when rows
+// are invalid or the tasks could not be stored; Error says why in the
+// latter case.
+type ImportJob struct {
+	ID string
+	// CreatedBy is the user who started the job, empty for anonymous users.
+	CreatedBy  string
+	Status     ImportStatus
+	Result     ImportResult
+	Error      *string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *ImportJob) (*ImportJob, error)
+	GetByID(ctx context.Context, id string) (*ImportJob, error)
+	// Update stores the status, result, error and finish time of job.
+	Update(ctx context.Context, job *ImportJob) error
+	// Touch records that the pending or running job id is still alive.
+	Touch(ctx context.Context, id string) error
+	// FailStale fails the pending and running jobs that were neither
+	// updated nor touched for olderThan, giving reason as their error.
+	FailStale(ctx context.Context, olderThan time.Duration, reason string) (int64, error)
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxMessage is a task event stored in the same transaction as the task
+// change that produced it. Event.ID doubles as the idempotency key consumers
+// use to discard redeliveries.
+type OutboxMessage struct {
+	// Seq orders the messages in the order their events happened; events
+	// written in one transaction share CreatedAt.
+	Seq         int64
+	Event       TaskEvent
+	Attempts    int
+	LastError   *string
+	CreatedAt   time.Time
+	PublishedAt *time.Time
+}
+
+type OutboxRepository interface {
+	// ClaimBatch leases up to limit unpublished messages that are due, in Seq
+	// order, hiding them from other relays for the duration of lease.
+	ClaimBatch(ctx context.Context, limit int, lease time.Duration) ([]*OutboxMessage, error)
+	MarkPublished(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, lastErr string, retryIn time.Duration) error
+	// DeletePublished removes messages published longer than olderThan ago.
+	DeletePublished(ctx context.Context, olderThan time.Duration) (int64, error)
+}
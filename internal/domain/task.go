@@ -14,7 +14,8 @@ type Task struct {
 	ID        string     `json:"id"`
 	Title     string     `json:"title"`
 	Status    TaskStatus `json:"status"`
-	Assignee  *string    `json:"assignee,omitempty"`
+	Assignees []string   `json:"assignees"`
+	Watchers  []string   `json:"watchers"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 }
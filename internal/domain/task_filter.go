@@ -1,10 +1,15 @@
 package domain
 
+import "slices"
+
 type TaskFilter struct {
 	Status   *TaskStatus
 	Assignee *string
-	Limit    int
-	Offset   int
+	Watcher  *string
+	// Participant matches tasks where the user is either an assignee or a watcher.
+	Participant *string
+	Limit       int
+	Offset      int
 }
 
 func IsValidStatus(s TaskStatus) bool {
@@ -17,3 +22,27 @@ func IsValidStatus(s TaskStatus) bool {
 		return false
 	}
 }
+
+// Matches reports whether t satisfies the criteria of f. Limit and Offset are
+// not considered.
+func (f TaskFilter) Matches(t *Task) bool {
+	if f.Status != nil && t.Status != *f.Status {
+		return false
+	}
+
+	if f.Assignee != nil && !slices.Contains(t.Assignees, *f.Assignee) {
+		return false
+	}
+
+	if f.Watcher != nil && !slices.Contains(t.Watchers, *f.Watcher) {
+		return false
+	}
+
+	if f.Participant != nil &&
+		!slices.Contains(t.Assignees, *f.Participant) &&
+		!slices.Contains(t.Watchers, *f.Participant) {
+		return false
+	}
+
+	return true
+}
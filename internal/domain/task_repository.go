@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"errors"
 )
 
 type TaskRepository interface {
@@ -11,3 +12,130 @@ type TaskRepository interface {
 	Update(ctx context.Context, task *Task) error
 	Delete(ctx context.Context, id string) error
 }
+
+// TaskCounter reports how many tasks exist in each status.
+type TaskCounter interface {
+	CountByStatus(ctx context.Context) (map[TaskStatus]int, error)
+}
+
+// TaskBulkCreator is implemented by repositories that can store many tasks
+// in one round trip. Tasks are created atomically: all or none.
+type TaskBulkCreator interface {
+	CreateMany(ctx context.Context, tasks []*Task) ([]*Task, error)
+}
+
+// TaskStreamer is implemented by repositories that can hand out the tasks
+// matching a filter one at a time, in List order, without loading all of
+// them. fn must not use the repository: the stream may hold its connection.
+type TaskStreamer interface {
+	Stream(ctx context.Context, filter TaskFilter, fn func(*Task) error) error
+}
+
+// TaskBatchGetter is implemented by repositories that can look up many
+// tasks by id in one round trip. The result holds every task found once,
+// in no particular order; unknown ids are left out.
+type TaskBatchGetter interface {
+	GetByIDs(ctx context.Context, ids []string) ([]*Task, error)
+}
+
+// streamPageSize is how many tasks StreamTasks lists at once when repo is
+// not a TaskStreamer.
+const streamPageSize = 500
+
+// StreamTasks calls fn for every task matching filter, in List order. It
+// uses repo's TaskStreamer and otherwise pages through List. An error of fn
+// stops the stream and is returned.
+func StreamTasks(
+	ctx context.Context,
+	repo TaskRepository,
+	filter TaskFilter,
+	fn func(*Task) error,
+) error {
+
+	if streamer, ok := repo.(TaskStreamer); ok {
+		return streamer.Stream(ctx, filter, fn)
+	}
+
+	page := filter
+	page.Offset = max(filter.Offset, 0)
+	remaining := filter.Limit
+
+	for {
+		page.Limit = streamPageSize
+		if filter.Limit > 0 {
+			page.Limit = min(streamPageSize, remaining)
+		}
+
+		tasks, err := repo.List(ctx, page)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			if err := fn(task); err != nil {
+				return err
+			}
+		}
+
+		if len(tasks) < page.Limit {
+			return nil
+		}
+
+		page.Offset += len(tasks)
+		if filter.Limit > 0 {
+			remaining -= len(tasks)
+			if remaining == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// CreateTasks stores tasks with repo's TaskBulkCreator and otherwise one by
+// one. Only the bulk path is atomic on its own; run it in a unit of work to
+// create all tasks or none.
+func CreateTasks(ctx context.Context, repo TaskRepository, tasks []*Task) ([]*Task, error) {
+	if creator, ok := repo.(TaskBulkCreator); ok {
+		return creator.CreateMany(ctx, tasks)
+	}
+
+	created := make([]*Task, 0, len(tasks))
+	for _, task := range tasks {
+		t, err := repo.Create(ctx, task)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, t)
+	}
+
+	return created, nil
+}
+
+// GetTasks returns the tasks with ids that exist, each once and in no
+// particular order. It uses repo's TaskBatchGetter and otherwise GetByID for
+// every id.
+func GetTasks(ctx context.Context, repo TaskRepository, ids []string) ([]*Task, error) {
+	if getter, ok := repo.(TaskBatchGetter); ok {
+		return getter.GetByIDs(ctx, ids)
+	}
+
+	tasks := make([]*Task, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		task, err := repo.GetByID(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "context"
+
+// IsolationLevel is the SQL isolation level of a unit of work.
+type IsolationLevel string
+
+const (
+	// IsolationDefault uses the level configured for the TxManager.
+	IsolationDefault        IsolationLevel = ""
+	IsolationReadCommitted  IsolationLevel = "read_committed"
+	IsolationRepeatableRead IsolationLevel = "repeatable_read"
+	IsolationSerializable   IsolationLevel = "serializable"
+)
+
+func IsValidIsolationLevel(l IsolationLevel) bool {
+	switch l {
+	case IsolationDefault,
+		IsolationReadCommitted,
+		IsolationRepeatableRead,
+		IsolationSerializable:
+		return true
+	default:
+		return false
+	}
+}
+
+// TxManager runs several repository calls as one unit of work. Repositories
+// take part in it by finding the transaction in the context passed to fn,
+// so fn must hand that context on and must not use it concurrently.
+type TxManager interface {
+	// WithinTx runs fn in a transaction, committing when fn returns nil and
+	// rolling back otherwise. Called inside another unit of work, fn runs
+	// in a savepoint: its error only undoes what fn did.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// WithinTxLevel is WithinTx with an explicit isolation level. A nested
+	// call keeps the level of the outer transaction.
+	WithinTxLevel(ctx context.Context, level IsolationLevel, fn func(ctx context.Context) error) error
+}
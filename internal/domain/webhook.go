@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrNotFound is returned by repositories when the requested record does not exist.
+var ErrNotFound = errors.New("not found")
+
+type Webhook struct {
+	ID     string
+	URL    string
+	Secret string
+	// Events limits deliveries to the listed types; an empty list subscribes to every event.
+	Events    []EventType
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (w *Webhook) Subscribes(e EventType) bool {
+	return w.Active && (len(w.Events) == 0 || slices.Contains(w.Events, e))
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	ID            string
+	WebhookID     string
+	EventID       string
+	EventType     EventType
+	Payload       []byte
+	Status        DeliveryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     *string
+	ResponseCode  *int
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *Webhook) (*Webhook, error)
+	GetByID(ctx context.Context, id string) (*Webhook, error)
+	List(ctx context.Context) ([]*Webhook, error)
+	Update(ctx context.Context, webhook *Webhook) error
+	Delete(ctx context.Context, id string) error
+}
+
+type WebhookDeliveryRepository interface {
+	Enqueue(ctx context.Context, deliveries []*WebhookDelivery) error
+	// ClaimDue leases up to limit pending deliveries whose next attempt is due,
+	// pushing their next attempt out by lease so other workers skip them.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*WebhookDelivery, error)
+	MarkSucceeded(ctx context.Context, id string, responseCode int) error
+	// MarkFailed records a failed attempt and schedules the next one after retryIn;
+	// a nil retryIn gives up on the delivery.
+	MarkFailed(ctx context.Context, id string, responseCode *int, lastErr string, retryIn *time.Duration) error
+	ListByWebhook(ctx context.Context, webhookID string, limit int, offset int) ([]*WebhookDelivery, error)
+}
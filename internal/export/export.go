@@ -0,0 +1,167 @@
+// Package export writes tasks as CSV, JSON Lines or Excel files, one task at
+// a time, so exports of any size run in constant memory.
+package export
+
+import (
+	"fmt"
+	"graph-task-service/internal/domain"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatXLSX  Format = "xlsx"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatJSONL, FormatXLSX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, use csv, jsonl or xlsx", s)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/jsonl; charset=utf-8"
+	default:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+}
+
+// Column is a task field, named like in the JSON API.
+type Column string
+
+const (
+	ColumnID        Column = "id"
+	ColumnTitle     Column = "title"
+	ColumnStatus    Column = "status"
+	ColumnAssignees Column = "assignees"
+	ColumnWatchers  Column = "watchers"
+	ColumnCreatedAt Column = "created_at"
+	ColumnUpdatedAt Column = "updated_at"
+)
+
+// AllColumns are exported when no columns are selected.
+var AllColumns = []Column{
+	ColumnID,
+	ColumnTitle,
+	ColumnStatus,
+	ColumnAssignees,
+	ColumnWatchers,
+	ColumnCreatedAt,
+	ColumnUpdatedAt,
+}
+
+// ParseColumns parses a comma separated list of columns. An empty list
+// selects AllColumns.
+func ParseColumns(s string) ([]Column, error) {
+	if strings.TrimSpace(s) == "" {
+		return AllColumns, nil
+	}
+
+	var columns []Column
+	for _, name := range strings.Split(s, ",") {
+		column := Column(strings.TrimSpace(name))
+		if !slices.Contains(AllColumns, column) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		if slices.Contains(columns, column) {
+			return nil, fmt.Errorf("column %q selected twice", name)
+		}
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+type Options struct {
+	// Columns are written in this order; empty means AllColumns.
+	Columns []Column
+	// Location is the time zone of timestamps; nil means UTC.
+	Location *time.Location
+}
+
+// Writer writes tasks in one format.
+type Writer interface {
+	Write(task *domain.Task) error
+	// Close completes the output. It does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns a Writer of format on w. CSV and JSON Lines are written
+// as tasks arrive; an Excel workbook is buffered, in temporary files once it
+// grows large, and written to w by Close.
+func NewWriter(w io.Writer, format Format, opts Options) (Writer, error) {
+	if len(opts.Columns) == 0 {
+		opts.Columns = AllColumns
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, opts)
+	case FormatJSONL:
+		return newJSONLWriter(w, opts), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, opts)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// field returns the value of column of task. Timestamps are converted to
+// loc; users are returned as a slice.
+func field(task *domain.Task, column Column, loc *time.Location) any {
+	switch column {
+	case ColumnID:
+		return task.ID
+	case ColumnTitle:
+		return task.Title
+	case ColumnStatus:
+		return string(task.Status)
+	case ColumnAssignees:
+		return orEmpty(task.Assignees)
+	case ColumnWatchers:
+		return orEmpty(task.Watchers)
+	case ColumnCreatedAt:
+		return task.CreatedAt.In(loc)
+	case ColumnUpdatedAt:
+		return task.UpdatedAt.In(loc)
+	default:
+		return nil
+	}
+}
+
+// text renders a field value for formats without native types.
+func text(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ", ")
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
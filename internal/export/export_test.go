@@ -0,0 +1,162 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/export"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+var tasks = []*domain.Task{
+	{
+		ID:        "1",
+		Title:     `quoted "title", with comma`,
+		Status:    domain.StatusTodo,
+		Assignees: []string{"alice", "bob"},
+		CreatedAt: time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+	},
+	{
+		ID:        "2",
+		Title:     "plain",
+		Status:    domain.StatusDone,
+		Watchers:  []string{"carol"},
+		CreatedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+	},
+}
+
+func write(t *testing.T, format export.Format, opts export.Options) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w, err := export.NewWriter(&buf, format, opts)
+	require.NoError(t, err)
+
+	for _, task := range tasks {
+		require.NoError(t, w.Write(task))
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	out := write(t, export.FormatCSV, export.Options{})
+
+	assert.Equal(t, `id,title,status,assignees,watchers,created_at,updated_at
+1,"quoted ""title"", with comma",todo,"alice, bob",,2026-03-01T23:30:00Z,2026-03-02T08:00:00Z
+2,plain,done,,carol,2026-03-01T10:00:00Z,2026-03-01T10:00:00Z
+`, string(out))
+}
+
+func TestSpreadsheetFormatsEscapeFormulas(t *testing.T) {
+	task := &domain.Task{
+		ID:        "1",
+		Title:     `=HYPERLINK("http://evil.example","x")`,
+		Assignees: []string{"+alice"},
+		Watchers:  []string{"@bob", "-carol"},
+	}
+	opts := export.Options{Columns: []export.Column{export.ColumnTitle, export.ColumnAssignees, export.ColumnWatchers}}
+	want := []string{`'=HYPERLINK("http://evil.example","x")`, "'+alice", "'@bob, -carol"}
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, export.FormatCSV, opts)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(task))
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, want, records[1])
+
+	buf.Reset()
+	w, err = export.NewWriter(&buf, export.FormatXLSX, opts)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(task))
+	require.NoError(t, w.Close())
+
+	f, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows("Tasks")
+	require.NoError(t, err)
+	assert.Equal(t, want, rows[1])
+
+	buf.Reset()
+	w, err = export.NewWriter(&buf, export.FormatJSONL, opts)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(task))
+	require.NoError(t, w.Close())
+
+	assert.Contains(t, buf.String(), `"title":"=HYPERLINK(`, "JSON Lines are not opened by spreadsheets")
+}
+
+func TestJSONL_ColumnsAndTimeZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	out := write(t, export.FormatJSONL, export.Options{
+		Columns:  []export.Column{export.ColumnCreatedAt, export.ColumnID, export.ColumnWatchers},
+		Location: berlin,
+	})
+
+	assert.Equal(t, `{"created_at":"2026-03-02T00:30:00+01:00","id":"1","watchers":[]}
+{"created_at":"2026-03-01T11:00:00+01:00","id":"2","watchers":["carol"]}
+`, string(out))
+}
+
+func TestXLSX(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	out := write(t, export.FormatXLSX, export.Options{
+		Columns:  []export.Column{export.ColumnTitle, export.ColumnAssignees, export.ColumnCreatedAt},
+		Location: tokyo,
+	})
+
+	f, err := excelize.OpenReader(bytes.NewReader(out))
+	require.NoError(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows("Tasks")
+	require.NoError(t, err)
+
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"title", "assignees", "created_at"}, rows[0])
+	assert.Equal(t, []string{`quoted "title", with comma`, "alice, bob", "2026-03-02 08:30:00"}, rows[1])
+	assert.Equal(t, []string{"plain", "", "2026-03-01 19:00:00"}, rows[2])
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := export.ParseColumns("")
+	require.NoError(t, err)
+	assert.Equal(t, export.AllColumns, columns)
+
+	columns, err = export.ParseColumns("status, id")
+	require.NoError(t, err)
+	assert.Equal(t, []export.Column{export.ColumnStatus, export.ColumnID}, columns)
+
+	_, err = export.ParseColumns("id,secret")
+	assert.ErrorContains(t, err, `"secret"`)
+
+	_, err = export.ParseColumns("id,id")
+	assert.ErrorContains(t, err, "twice")
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := export.ParseFormat("XLSX")
+	require.NoError(t, err)
+	assert.Equal(t, export.FormatXLSX, format)
+
+	_, err = export.ParseFormat("pdf")
+	assert.Error(t, err)
+}
@@ -0,0 +1,196 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"graph-task-service/internal/domain"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type csvWriter struct {
+	w    *csv.Writer
+	opts Options
+}
+
+// newCSVWriter writes a header row of column names, then one row per task.
+// Users are joined with ", ".
+func newCSVWriter(w io.Writer, opts Options) (Writer, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), opts: opts}
+
+	header := make([]string, len(opts.Columns))
+	for i, column := range opts.Columns {
+		header[i] = string(column)
+	}
+
+	if err := cw.w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(task *domain.Task) error {
+	record := make([]string, len(cw.opts.Columns))
+	for i, column := range cw.opts.Columns {
+		record[i] = escapeFormula(text(field(task, column, cw.opts.Location)))
+	}
+
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// escapeFormula prefixes text that spreadsheets would evaluate as a
+// formula with a quote, so a title like =HYPERLINK(...) opens as text.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type jsonlWriter struct {
+	w    *bufio.Writer
+	opts Options
+}
+
+// newJSONLWriter writes one JSON object per line with the selected columns
+// in order. Users stay arrays.
+func newJSONLWriter(w io.Writer, opts Options) Writer {
+	return &jsonlWriter{w: bufio.NewWriter(w), opts: opts}
+}
+
+func (jw *jsonlWriter) Write(task *domain.Task) error {
+	jw.w.WriteByte('{')
+
+	for i, column := range jw.opts.Columns {
+		if i > 0 {
+			jw.w.WriteByte(',')
+		}
+
+		value := field(task, column, jw.opts.Location)
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(time.RFC3339)
+		}
+
+		key, _ := json.Marshal(string(column))
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		jw.w.Write(key)
+		jw.w.WriteByte(':')
+		jw.w.Write(encoded)
+	}
+
+	jw.w.WriteString("}\n")
+
+	// bufio keeps the first error, so checking once per line suffices.
+	_, err := jw.w.Write(nil)
+	return err
+}
+
+func (jw *jsonlWriter) Close() error {
+	return jw.w.Flush()
+}
+
+const xlsxSheet = "Tasks"
+
+type xlsxWriter struct {
+	w         io.Writer
+	file      *excelize.File
+	sheet     *excelize.StreamWriter
+	opts      Options
+	row       int
+	timeStyle int
+}
+
+// newXLSXWriter writes a single sheet with a bold header row. Timestamps
+// are date cells showing the wall clock of opts.Location.
+func newXLSXWriter(w io.Writer, opts Options) (_ Writer, err error) {
+	file := excelize.NewFile()
+	defer func() {
+		if err != nil {
+			file.Close()
+		}
+	}()
+
+	if err := file.SetSheetName("Sheet1", xlsxSheet); err != nil {
+		return nil, err
+	}
+
+	sheet, err := file.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		return nil, err
+	}
+
+	bold, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	timeFormat := "yyyy-mm-dd hh:mm:ss"
+	timeStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: &timeFormat})
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]any, len(opts.Columns))
+	for i, column := range opts.Columns {
+		header[i] = excelize.Cell{StyleID: bold, Value: string(column)}
+	}
+
+	if err := sheet.SetRow("A1", header); err != nil {
+		return nil, err
+	}
+
+	return &xlsxWriter{w: w, file: file, sheet: sheet, opts: opts, row: 1, timeStyle: timeStyle}, nil
+}
+
+func (xw *xlsxWriter) Write(task *domain.Task) error {
+	xw.row++
+
+	values := make([]any, len(xw.opts.Columns))
+	for i, column := range xw.opts.Columns {
+		switch value := field(task, column, xw.opts.Location).(type) {
+		case time.Time:
+			// Excel has no time zones: store the wall clock of the location.
+			wall := time.Date(value.Year(), value.Month(), value.Day(),
+				value.Hour(), value.Minute(), value.Second(), value.Nanosecond(), time.UTC)
+			values[i] = excelize.Cell{StyleID: xw.timeStyle, Value: wall}
+		case []string:
+			values[i] = escapeFormula(text(value))
+		case string:
+			values[i] = escapeFormula(value)
+		default:
+			values[i] = value
+		}
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+
+	return xw.sheet.SetRow(cell, values)
+}
+
+func (xw *xlsxWriter) Close() error {
+	defer xw.file.Close()
+
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+
+	_, err := xw.file.WriteTo(xw.w)
+	return err
+}
@@ -0,0 +1,152 @@
+package graphql
+
+import (
+	"math"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// listFields are the fields returning a list sized by their limit argument,
+// with the default limit of the schema.
+var listFields = map[string]int{
+	"tasks":   defaultListLimit,
+	"myTasks": defaultListLimit,
+}
+
+// operation is what is known about a document before it runs.
+type operation struct {
+	// kind is "query", "mutation" or "subscription"; empty if the document
+	// could not be analyzed.
+	kind       string
+	complexity int
+}
+
+// analyze estimates the work of the operation named name in query. Every
+// field costs 1, and the fields selected below a list cost once per item
+// the list may hold: its limit, the number of ids asked for, or the default
+// limit. Documents that do not parse, or lack the operation, are left for
+// graphql-go to report.
+func analyze(query, name string, vars map[string]any) operation {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return operation{}
+	}
+
+	op := doc.Operations.ForName(name)
+	if op == nil {
+		return operation{}
+	}
+
+	c := &costCounter{
+		fragments: doc.Fragments,
+		vars:      vars,
+		costs:     make(map[string]int),
+		visiting:  make(map[string]bool),
+	}
+	return operation{
+		kind:       string(op.Operation),
+		complexity: c.selectionSet(op.SelectionSet),
+	}
+}
+
+type costCounter struct {
+	fragments ast.FragmentDefinitionList
+	vars      map[string]any
+	// costs memoizes the cost of fragments by name.
+	costs map[string]int
+	// visiting holds the fragments being counted, to stop at cycles, which
+	// graphql-go rejects anyway.
+	visiting map[string]bool
+}
+
+func (c *costCounter) selectionSet(set ast.SelectionSet) int {
+	total := 0
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			total = add(total, c.field(sel))
+		case *ast.InlineFragment:
+			total = add(total, c.selectionSet(sel.SelectionSet))
+		case *ast.FragmentSpread:
+			total = add(total, c.fragment(sel.Name))
+		}
+	}
+	return total
+}
+
+func (c *costCounter) field(f *ast.Field) int {
+	if len(f.SelectionSet) == 0 {
+		return 1
+	}
+	return add(1, mul(c.listSize(f), c.selectionSet(f.SelectionSet)))
+}
+
+func (c *costCounter) fragment(name string) int {
+	if cost, ok := c.costs[name]; ok {
+		return cost
+	}
+	def := c.fragments.ForName(name)
+	if def == nil || c.visiting[name] {
+		return 0
+	}
+
+	c.visiting[name] = true
+	cost := c.selectionSet(def.SelectionSet)
+	delete(c.visiting, name)
+
+	c.costs[name] = cost
+	return cost
+}
+
+// listSize is the number of items f may return.
+func (c *costCounter) listSize(f *ast.Field) int {
+	if arg := f.Arguments.ForName("limit"); arg != nil {
+		if v, err := arg.Value.Value(c.vars); err == nil {
+			if n, ok := toInt(v); ok {
+				return max(n, 0)
+			}
+		}
+	}
+
+	if arg := f.Arguments.ForName("ids"); arg != nil {
+		if v, err := arg.Value.Value(c.vars); err == nil {
+			if ids, ok := v.([]any); ok {
+				return len(ids)
+			}
+		}
+	}
+
+	if n, ok := listFields[f.Name]; ok {
+		return n
+	}
+	return 1
+}
+
+// toInt converts an Int literal, or a variable decoded from JSON.
+func toInt(v any) (int, bool) {
+	switch v := v.(type) {
+	case int64:
+		return int(min(max(v, math.MinInt32), math.MaxInt32)), true
+	case float64:
+		return int(min(max(v, math.MinInt32), math.MaxInt32)), true
+	case int:
+		return v, true
+	}
+	return 0, false
+}
+
+// add and mul saturate instead of overflowing on absurd documents.
+func add(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+func mul(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
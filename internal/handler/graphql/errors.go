@@ -0,0 +1,92 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graph-task-service/internal/logging"
+	"graph-task-service/internal/service"
+
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+// Codes put in the extensions of errors, named like those of other GraphQL
+// servers so clients can tell failures apart without parsing messages.
+const (
+	codeBadUserInput       = "BAD_USER_INPUT"
+	codeNotFound           = "NOT_FOUND"
+	codeUnauthenticated    = "UNAUTHENTICATED"
+	codeUnimplemented      = "UNIMPLEMENTED"
+	codeComplexityExceeded = "COMPLEXITY_LIMIT_EXCEEDED"
+	codeInternal           = "INTERNAL_SERVER_ERROR"
+)
+
+// resolverError is an error a resolver returns to the client as is.
+type resolverError struct {
+	code    string
+	message string
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+// Extensions is read by graphql-go to fill the extensions of the error.
+func (e *resolverError) Extensions() map[string]any {
+	return map[string]any{"code": e.code}
+}
+
+func badUserInput(format string, args ...any) error {
+	return &resolverError{code: codeBadUserInput, message: fmt.Sprintf(format, args...)}
+}
+
+// toError maps an error of the service layer to one for the client, with
+// the codes matching the status codes of the REST API. Unexpected errors
+// are logged and answered without their message.
+func toError(ctx context.Context, err error) error {
+	var re *resolverError
+	if errors.As(err, &re) {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		return &resolverError{code: codeNotFound, message: err.Error()}
+	case errors.Is(err, service.ErrEmptyTitle),
+		errors.Is(err, service.ErrInvalidStatus),
+		errors.Is(err, service.ErrEmptyUser):
+		return badUserInput("%s", err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	}
+
+	logging.FromContext(ctx).Error("graphql resolver failed", "error", err)
+	return &resolverError{code: codeInternal, message: "internal error"}
+}
+
+// panicHandler answers panics in resolvers like failed resolvers and logs
+// them with the request.
+type panicHandler struct{}
+
+func (panicHandler) MakePanicError(ctx context.Context, value any) *gqlerrors.QueryError {
+	return &gqlerrors.QueryError{
+		Message:    "internal error",
+		Extensions: map[string]any{"code": codeInternal},
+	}
+}
+
+func (panicHandler) LogPanic(ctx context.Context, value any) {
+	logging.FromContext(ctx).Error("panic recovered", "panic", value)
+}
+
+// withCodes copies the code of resolver errors into errs where graphql-go
+// leaves it out, as it does for errors of subscription resolvers.
+func withCodes(errs []*gqlerrors.QueryError) []*gqlerrors.QueryError {
+	for _, err := range errs {
+		var re *resolverError
+		if err.Extensions == nil && errors.As(err.ResolverError, &re) {
+			err.Extensions = re.Extensions()
+		}
+	}
+	return errs
+}
@@ -0,0 +1,171 @@
+// Package graphql serves the task API as GraphQL at /graphql: queries and
+// mutations over HTTP or WebSocket, subscriptions over WebSocket with the
+// graphql-transport-ws protocol.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/service"
+	"graph-task-service/internal/stream"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Options are the limits put on every operation.
+type Options struct {
+	// MaxDepth is how deeply selections may nest, counting introspection
+	// fields.
+	MaxDepth int
+	// MaxComplexity rejects operations estimated to cost more before they
+	// run; see analyze for how the cost is counted.
+	MaxComplexity int
+}
+
+type Handler struct {
+	schema        *graphql.Schema
+	service       service.TaskService
+	maxComplexity int
+	upgrader      websocket.Upgrader
+}
+
+// NewHandler serves the schema backed by s. hub and feed serve the
+// taskChanged subscription; without them, as on servers without Postgres,
+// subscribing answers UNIMPLEMENTED.
+func NewHandler(
+	s service.TaskService,
+	hub *stream.Hub,
+	feed domain.TaskEventFeed,
+	opts Options,
+) *Handler {
+
+	schema := graphql.MustParseSchema(schemaSDL, &resolver{service: s, hub: hub, feed: feed},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(opts.MaxDepth),
+		graphql.Logger(panicHandler{}),
+		graphql.PanicHandler(panicHandler{}),
+	)
+
+	return &Handler{
+		schema:        schema,
+		service:       s,
+		maxComplexity: opts.MaxComplexity,
+		upgrader:      websocket.Upgrader{Subprotocols: []string{subprotocol}},
+	}
+}
+
+// request is a GraphQL request, as POSTed in JSON or passed in the query
+// string of a GET.
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// ServeHTTP answers GraphQL over HTTP. Results, including failed
+// operations, are answered with 200 and the errors in the body; only
+// requests that are no GraphQL request at all get another status. GET runs
+// queries only, so they can never be triggered by a link.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		h.serveWebSocket(w, r)
+		return
+	}
+
+	var req request
+
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid variables")
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	op := analyze(req.Query, req.OperationName, req.Variables)
+
+	switch {
+	case op.kind == "subscription":
+		writeResponse(w, errorResponse(&gqlerrors.QueryError{
+			Message:    "subscriptions are served over WebSocket",
+			Extensions: map[string]any{"code": codeBadUserInput},
+		}))
+		return
+	case r.Method == http.MethodGet && op.kind == "mutation":
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "mutations must be POSTed")
+		return
+	}
+
+	writeResponse(w, h.exec(r.Context(), req, op))
+}
+
+// exec runs a query or mutation with loaders of its own.
+func (h *Handler) exec(ctx context.Context, req request, op operation) *graphql.Response {
+	if err := h.checkComplexity(op); err != nil {
+		return errorResponse(err)
+	}
+
+	ctx = withLoaders(ctx, newLoaders(h.service))
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	resp.Errors = withCodes(resp.Errors)
+	return resp
+}
+
+func (h *Handler) checkComplexity(op operation) *gqlerrors.QueryError {
+	if op.complexity <= h.maxComplexity {
+		return nil
+	}
+	return &gqlerrors.QueryError{
+		Message: fmt.Sprintf("operation complexity %d exceeds the limit of %d", op.complexity, h.maxComplexity),
+		Extensions: map[string]any{
+			"code":          codeComplexityExceeded,
+			"complexity":    op.complexity,
+			"maxComplexity": h.maxComplexity,
+		},
+	}
+}
+
+func errorResponse(err *gqlerrors.QueryError) *graphql.Response {
+	return &graphql.Response{Errors: []*gqlerrors.QueryError{err}}
+}
+
+func writeResponse(w http.ResponseWriter, resp *graphql.Response) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// writeError answers requests that never reached GraphQL execution, in the
+// shape of a GraphQL response so clients need a single error path.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorResponse(&gqlerrors.QueryError{Message: message}))
+}
@@ -0,0 +1,471 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/handler/graphql"
+	"graph-task-service/internal/middelware"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/service"
+	"graph-task-service/internal/stream"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFeed struct {
+	events []*domain.SequencedEvent
+}
+
+func (f *fakeFeed) Listen(ctx context.Context, handle func(*domain.SequencedEvent)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (f *fakeFeed) Since(ctx context.Context, seq int64, limit int) ([]*domain.SequencedEvent, error) {
+	var out []*domain.SequencedEvent
+	for _, e := range f.events {
+		if e.Seq > seq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func sequenced(seq int64, id string, assignee string) *domain.SequencedEvent {
+	return &domain.SequencedEvent{
+		Seq: seq,
+		Event: domain.TaskEvent{
+			ID:     id,
+			Type:   domain.EventTaskCreated,
+			TaskID: id,
+			Task:   &domain.Task{ID: id, Status: domain.StatusTodo, Assignees: []string{assignee}},
+		},
+	}
+}
+
+// countingService counts the batch lookups reaching the service.
+type countingService struct {
+	service.TaskService
+	batches atomic.Int32
+}
+
+func (s *countingService) GetTasks(ctx context.Context, ids []string) ([]*domain.Task, error) {
+	s.batches.Add(1)
+	return s.TaskService.GetTasks(ctx, ids)
+}
+
+type testServer struct {
+	URL   string
+	tasks *countingService
+}
+
+// newTestServer serves tasks from memory, taking the caller from X-User.
+func newTestServer(
+	t *testing.T,
+	hub *stream.Hub,
+	feed domain.TaskEventFeed,
+	opts graphql.Options,
+) *testServer {
+
+	t.Helper()
+
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = 15
+	}
+	if opts.MaxComplexity == 0 {
+		opts.MaxComplexity = 1000
+	}
+
+	tasks := &countingService{TaskService: service.NewTaskService(memory.NewTaskRepository(), nil)}
+	h := graphql.NewHandler(tasks, hub, feed, opts)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user := r.Header.Get("X-User"); user != "" {
+			r = r.WithContext(middelware.WithUser(r.Context(), user))
+		}
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return &testServer{URL: srv.URL, tasks: tasks}
+}
+
+type gqlError struct {
+	Message    string         `json:"message"`
+	Extensions map[string]any `json:"extensions"`
+}
+
+type gqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []gqlError      `json:"errors"`
+}
+
+func (r gqlResponse) code() any {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return r.Errors[0].Extensions["code"]
+}
+
+func (s *testServer) do(t *testing.T, user, query string, vars map[string]any) gqlResponse {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]any{"query": query, "variables": vars})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var out gqlResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+	return out
+}
+
+// data runs query, requires it to succeed and decodes its data into out.
+func (s *testServer) data(t *testing.T, user, query string, vars map[string]any, out any) {
+	t.Helper()
+
+	resp := s.do(t, user, query, vars)
+	require.Empty(t, resp.Errors)
+	require.NoError(t, json.Unmarshal(resp.Data, out))
+}
+
+type task struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Status    string   `json:"status"`
+	Assignees []string `json:"assignees"`
+	Watchers  []string `json:"watchers"`
+}
+
+const createTask = `mutation($input: CreateTaskInput!) {
+	createTask(input: $input) { id title status assignees watchers }
+}`
+
+func (s *testServer) create(t *testing.T, title string, assignees ...string) task {
+	t.Helper()
+
+	var out struct{ CreateTask task }
+	s.data(t, "", createTask, map[string]any{
+		"input": map[string]any{"title": title, "assignees": assignees},
+	}, &out)
+	return out.CreateTask
+}
+
+func TestHandler_Lifecycle(t *testing.T) {
+	srv := newTestServer(t, nil, nil, graphql.Options{})
+
+	created := srv.create(t, "Ship GraphQL", "alice")
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "TODO", created.Status)
+	assert.Equal(t, []string{"alice"}, created.Assignees)
+	assert.Equal(t, []string{}, created.Watchers)
+	srv.create(t, "Write docs", "bob")
+
+	var list struct{ Tasks []task }
+	srv.data(t, "", `{ tasks(filter: {assignee: "alice"}) { id title } }`, nil, &list)
+	require.Len(t, list.Tasks, 1)
+	assert.Equal(t, "Ship GraphQL", list.Tasks[0].Title)
+
+	var updated struct {
+		UpdateTaskStatus task
+		AddWatcher       task
+	}
+	srv.data(t, "", `mutation($id: ID!) {
+		updateTaskStatus(id: $id, status: IN_PROGRESS) { status }
+		addWatcher(id: $id, user: "carol") { watchers }
+	}`, map[string]any{"id": created.ID}, &updated)
+	assert.Equal(t, "IN_PROGRESS", updated.UpdateTaskStatus.Status)
+	assert.Equal(t, []string{"carol"}, updated.AddWatcher.Watchers)
+
+	var mine struct{ MyTasks []task }
+	srv.data(t, "carol", `{ myTasks(status: IN_PROGRESS) { id } }`, nil, &mine)
+	require.Len(t, mine.MyTasks, 1)
+	assert.Equal(t, created.ID, mine.MyTasks[0].ID)
+
+	var deleted struct{ DeleteTask string }
+	srv.data(t, "", `mutation($id: ID!) { deleteTask(id: $id) }`, map[string]any{"id": created.ID}, &deleted)
+	assert.Equal(t, created.ID, deleted.DeleteTask)
+
+	var got struct{ Task *task }
+	srv.data(t, "", `query($id: ID!) { task(id: $id) { id } }`, map[string]any{"id": created.ID}, &got)
+	assert.Nil(t, got.Task)
+}
+
+func TestHandler_Errors(t *testing.T) {
+	srv := newTestServer(t, nil, nil, graphql.Options{})
+
+	tests := []struct {
+		name  string
+		user  string
+		query string
+		code  any
+	}{
+		{"unknown task", "", `mutation { updateTaskStatus(id: "missing", status: DONE) { id } }`, "NOT_FOUND"},
+		{"empty title", "", `mutation { createTask(input: {title: ""}) { id } }`, "BAD_USER_INPUT"},
+		{"empty user", "", `mutation { addWatcher(id: "missing", user: "") { id } }`, "BAD_USER_INPUT"},
+		{"limit too high", "", `{ tasks(limit: 500) { id } }`, "BAD_USER_INPUT"},
+		{"negative offset", "", `{ tasks(offset: -1) { id } }`, "BAD_USER_INPUT"},
+		{"anonymous myTasks", "", `{ myTasks { id } }`, "UNAUTHENTICATED"},
+		{"subscription over HTTP", "", `subscription { taskChanged { seq } }`, "BAD_USER_INPUT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := srv.do(t, tt.user, tt.query, nil)
+			require.NotEmpty(t, resp.Errors)
+			assert.Equal(t, tt.code, resp.code(), resp.Errors[0].Message)
+		})
+	}
+
+	resp := srv.do(t, "", `{ tasks { nope } }`, nil)
+	require.NotEmpty(t, resp.Errors, "invalid documents are rejected")
+	assert.Empty(t, resp.Data)
+}
+
+func TestHandler_BatchesTaskLookups(t *testing.T) {
+	srv := newTestServer(t, nil, nil, graphql.Options{})
+
+	a := srv.create(t, "A")
+	b := srv.create(t, "B")
+
+	var out struct {
+		A          *task
+		B          *task
+		Missing    *task
+		TasksByIds []*task
+	}
+	srv.data(t, "", `query($a: ID!, $b: ID!) {
+		a: task(id: $a) { title }
+		b: task(id: $b) { title }
+		missing: task(id: "missing") { title }
+		tasksByIds(ids: [$b, "missing", $a]) { id }
+	}`, map[string]any{"a": a.ID, "b": b.ID}, &out)
+
+	assert.Equal(t, int32(1), srv.tasks.batches.Load(), "every lookup goes into one batch")
+
+	require.NotNil(t, out.A)
+	assert.Equal(t, "A", out.A.Title)
+	require.NotNil(t, out.B)
+	assert.Equal(t, "B", out.B.Title)
+	assert.Nil(t, out.Missing)
+
+	require.Len(t, out.TasksByIds, 3)
+	assert.Equal(t, b.ID, out.TasksByIds[0].ID)
+	assert.Nil(t, out.TasksByIds[1])
+	assert.Equal(t, a.ID, out.TasksByIds[2].ID)
+}
+
+func TestHandler_Limits(t *testing.T) {
+	srv := newTestServer(t, nil, nil, graphql.Options{MaxDepth: 3, MaxComplexity: 100})
+
+	resp := srv.do(t, "", `{ tasks(limit: 50) { id title } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "COMPLEXITY_LIMIT_EXCEEDED", resp.code())
+	assert.EqualValues(t, 101, resp.Errors[0].Extensions["complexity"], "1 + 50 tasks of 2 fields")
+	assert.EqualValues(t, 100, resp.Errors[0].Extensions["maxComplexity"])
+
+	var out struct{ Tasks []task }
+	srv.data(t, "", `query($n: Int) { tasks(limit: $n) { ...fields } }
+		fragment fields on Task { id title }`, map[string]any{"n": 10}, &out)
+
+	resp = srv.do(t, "", `query($n: Int) { tasks(limit: $n) { ...fields } }
+		fragment fields on Task { id title }`, map[string]any{"n": 60})
+	assert.Equal(t, "COMPLEXITY_LIMIT_EXCEEDED", resp.code(), "limits given as variables count")
+
+	resp = srv.do(t, "", `{ tasks(limit: 10) { id } tasksByIds(ids: ["1", "2"]) { id } }`, nil)
+	assert.Empty(t, resp.Errors)
+
+	resp = srv.do(t, "", `{ __schema { types { fields { type { name } } } } }`, nil)
+	require.NotEmpty(t, resp.Errors)
+	assert.Contains(t, resp.Errors[0].Message, "depth")
+}
+
+func TestHandler_HTTP(t *testing.T) {
+	srv := newTestServer(t, nil, nil, graphql.Options{})
+	srv.create(t, "A")
+
+	res, err := http.Get(srv.URL + "?query=" + url.QueryEscape(`{ tasks { title } }`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+	var resp gqlResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+	assert.JSONEq(t, `{"tasks":[{"title":"A"}]}`, string(resp.Data))
+
+	res, err = http.Get(srv.URL + "?query=" + url.QueryEscape(`mutation { deleteTask(id: "x") }`))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode, "mutations must not run on GET")
+
+	res, err = http.Post(srv.URL, "application/json", strings.NewReader(`{"query":`))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err = http.Post(srv.URL, "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, "query is required")
+}
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// dial opens an initialized graphql-transport-ws connection.
+func dial(t *testing.T, srv *testServer) *websocket.Conn {
+	t.Helper()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: "connection_init"}))
+	assert.Equal(t, "connection_ack", read(t, conn).Type)
+
+	return conn
+}
+
+func read(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+
+	var msg wsMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func subscribe(t *testing.T, conn *websocket.Conn, id, query string) {
+	t.Helper()
+
+	payload, err := json.Marshal(map[string]any{"query": query})
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteJSON(wsMessage{ID: id, Type: "subscribe", Payload: payload}))
+}
+
+func TestHandler_Subscription(t *testing.T) {
+	hub := stream.NewHub(8)
+	feed := &fakeFeed{events: []*domain.SequencedEvent{
+		sequenced(1, "t1", "alice"),
+		sequenced(2, "t2", "bob"),
+		sequenced(3, "t3", "alice"),
+	}}
+	srv := newTestServer(t, hub, feed, graphql.Options{})
+	conn := dial(t, srv)
+
+	subscribe(t, conn, "1", `subscription {
+		taskChanged(filter: {assignee: "alice"}, afterSeq: "1") { seq type task { id } }
+	}`)
+
+	msg := read(t, conn)
+	require.Equal(t, "next", msg.Type, string(msg.Payload))
+	assert.Equal(t, "1", msg.ID)
+	assert.JSONEq(t, `{"data":{"taskChanged":{"seq":"3","type":"TASK_CREATED","task":{"id":"t3"}}}}`,
+		string(msg.Payload), "replays matching events after the seq")
+
+	// The subscription opens before the replay, so a live event can be
+	// broadcast once the replay has arrived.
+	hub.Broadcast(sequenced(4, "t4", "bob"))
+	hub.Broadcast(sequenced(5, "t5", "alice"))
+
+	msg = read(t, conn)
+	require.Equal(t, "next", msg.Type)
+	assert.Contains(t, string(msg.Payload), `"seq":"5"`)
+
+	hub.Close()
+
+	msg = read(t, conn)
+	assert.Equal(t, wsMessage{ID: "1", Type: "complete"}, msg, "dropped subscriptions complete")
+}
+
+func TestHandler_SubscriptionWithoutPostgres(t *testing.T) {
+	srv := newTestServer(t, nil, nil, graphql.Options{})
+	conn := dial(t, srv)
+
+	subscribe(t, conn, "1", `subscription { taskChanged { seq } }`)
+
+	msg := read(t, conn)
+	require.Equal(t, "error", msg.Type)
+
+	var errs []gqlError
+	require.NoError(t, json.Unmarshal(msg.Payload, &errs))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "UNIMPLEMENTED", errs[0].Extensions["code"])
+}
+
+func TestHandler_WebSocketOperations(t *testing.T) {
+	srv := newTestServer(t, nil, nil, graphql.Options{})
+	conn := dial(t, srv)
+
+	subscribe(t, conn, "1", `mutation { createTask(input: {title: "A"}) { title } }`)
+
+	msg := read(t, conn)
+	require.Equal(t, "next", msg.Type)
+	assert.JSONEq(t, `{"data":{"createTask":{"title":"A"}}}`, string(msg.Payload))
+	assert.Equal(t, wsMessage{ID: "1", Type: "complete"}, read(t, conn))
+
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: "ping"}))
+	assert.Equal(t, "pong", read(t, conn).Type)
+
+	subscribe(t, conn, "2", `{ nope }`)
+	assert.Equal(t, "error", read(t, conn).Type, "invalid documents fail the operation")
+}
+
+func TestHandler_WebSocketProtocolErrors(t *testing.T) {
+	srv := newTestServer(t, nil, nil, graphql.Options{})
+	endpoint := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	closeCode := func(conn *websocket.Conn) int {
+		t.Helper()
+
+		_, _, err := conn.ReadMessage()
+		var closeErr *websocket.CloseError
+		require.ErrorAs(t, err, &closeErr)
+		return closeErr.Code
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(endpoint, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, 4406, closeCode(conn), "the subprotocol is required")
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	conn, _, err = dialer.Dial(endpoint, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	subscribe(t, conn, "1", `{ tasks { id } }`)
+	assert.Equal(t, 4401, closeCode(conn), "subscribing requires connection_init")
+
+	conn = dial(t, srv)
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: "connection_init"}))
+	assert.Equal(t, 4429, closeCode(conn))
+
+	conn = dial(t, srv)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("nope")))
+	assert.Equal(t, 4400, closeCode(conn))
+}
@@ -0,0 +1,71 @@
+package graphql
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/service"
+	"time"
+
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+const (
+	// loaderWait is how long a loader collects keys before it loads them.
+	// Resolvers of sibling fields run concurrently, so their keys arrive
+	// well within it.
+	loaderWait = 2 * time.Millisecond
+	// loaderBatchSize caps the ids looked up at once.
+	loaderBatchSize = 100
+)
+
+type loadersKey struct{}
+
+// loaders batch the lookups of one operation: every task requested by id
+// while a batch is open is fetched with one GetTasks call. They also cache
+// what they loaded, so they must not outlive the operation.
+type loaders struct {
+	task *dataloader.Loader[string, *domain.Task]
+}
+
+func newLoaders(tasks service.TaskService) *loaders {
+	return &loaders{
+		task: dataloader.NewBatchedLoader(
+			batchTasks(tasks),
+			dataloader.WithWait[string, *domain.Task](loaderWait),
+			dataloader.WithBatchCapacity[string, *domain.Task](loaderBatchSize),
+		),
+	}
+}
+
+// batchTasks loads tasks by id. Unknown ids load nil.
+func batchTasks(tasks service.TaskService) dataloader.BatchFunc[string, *domain.Task] {
+	return func(ctx context.Context, ids []string) []*dataloader.Result[*domain.Task] {
+		results := make([]*dataloader.Result[*domain.Task], len(ids))
+
+		found, err := tasks.GetTasks(ctx, ids)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[*domain.Task]{Error: err}
+			}
+			return results
+		}
+
+		byID := make(map[string]*domain.Task, len(found))
+		for _, task := range found {
+			byID[task.ID] = task
+		}
+
+		for i, id := range ids {
+			results[i] = &dataloader.Result[*domain.Task]{Data: byID[id]}
+		}
+		return results
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,409 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/logging"
+	"graph-task-service/internal/middelware"
+	"graph-task-service/internal/service"
+	"graph-task-service/internal/stream"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+var (
+	statusToGraphQL = map[domain.TaskStatus]string{
+		domain.StatusTodo:       "TODO",
+		domain.StatusInProgress: "IN_PROGRESS",
+		domain.StatusDone:       "DONE",
+	}
+
+	eventTypeToGraphQL = map[domain.EventType]string{
+		domain.EventTaskCreated:       "TASK_CREATED",
+		domain.EventTaskStatusChanged: "TASK_STATUS_CHANGED",
+		domain.EventTaskUpdated:       "TASK_UPDATED",
+		domain.EventTaskDeleted:       "TASK_DELETED",
+	}
+)
+
+// resolver is the root of the Query, Mutation and Subscription types.
+type resolver struct {
+	service service.TaskService
+	hub     *stream.Hub
+	feed    domain.TaskEventFeed
+}
+
+type taskFilterInput struct {
+	Status      *string
+	Assignee    *string
+	Watcher     *string
+	Participant *string
+}
+
+type createTaskInput struct {
+	Title     string
+	Assignees *[]string
+	Watchers  *[]string
+	Status    *string
+}
+
+// pageArgs holds limit and offset, which the schema defaults.
+type pageArgs struct {
+	Limit  int32
+	Offset int32
+}
+
+type userArgs struct {
+	ID   graphql.ID
+	User string
+}
+
+func (r *resolver) Task(ctx context.Context, args struct{ ID graphql.ID }) (*taskResolver, error) {
+	task, err := loadersFrom(ctx).task.Load(ctx, string(args.ID))()
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return newTaskResolver(task), nil
+}
+
+func (r *resolver) TasksByIds(ctx context.Context, args struct{ IDs []graphql.ID }) ([]*taskResolver, error) {
+	if len(args.IDs) > maxListLimit {
+		return nil, badUserInput("at most %d ids can be requested at once", maxListLimit)
+	}
+
+	ids := make([]string, len(args.IDs))
+	for i, id := range args.IDs {
+		ids[i] = string(id)
+	}
+
+	tasks, errs := loadersFrom(ctx).task.LoadMany(ctx, ids)()
+	for _, err := range errs {
+		if err != nil {
+			return nil, toError(ctx, err)
+		}
+	}
+
+	out := make([]*taskResolver, len(tasks))
+	for i, task := range tasks {
+		out[i] = newTaskResolver(task)
+	}
+	return out, nil
+}
+
+func (r *resolver) Tasks(ctx context.Context, args struct {
+	Filter *taskFilterInput
+	pageArgs
+}) ([]*taskResolver, error) {
+
+	filter, err := filterFromInput(args.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := args.pageArgs.apply(&filter); err != nil {
+		return nil, err
+	}
+
+	return r.list(ctx, filter)
+}
+
+func (r *resolver) MyTasks(ctx context.Context, args struct {
+	Status *string
+	pageArgs
+}) ([]*taskResolver, error) {
+
+	user, ok := middelware.UserFromContext(ctx)
+	if !ok {
+		return nil, &resolverError{code: codeUnauthenticated, message: "authentication required"}
+	}
+
+	filter, err := filterFromInput(&taskFilterInput{Status: args.Status, Participant: &user})
+	if err != nil {
+		return nil, err
+	}
+	if err := args.pageArgs.apply(&filter); err != nil {
+		return nil, err
+	}
+
+	return r.list(ctx, filter)
+}
+
+func (r *resolver) list(ctx context.Context, filter domain.TaskFilter) ([]*taskResolver, error) {
+	tasks, err := r.service.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+
+	out := make([]*taskResolver, len(tasks))
+	for i, task := range tasks {
+		out[i] = newTaskResolver(task)
+	}
+	return out, nil
+}
+
+func (r *resolver) CreateTask(ctx context.Context, args struct{ Input createTaskInput }) (*taskResolver, error) {
+	status, err := statusFromGraphQL(args.Input.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignees, watchers []string
+	if args.Input.Assignees != nil {
+		assignees = *args.Input.Assignees
+	}
+	if args.Input.Watchers != nil {
+		watchers = *args.Input.Watchers
+	}
+
+	task, err := r.service.CreateTask(ctx, args.Input.Title, assignees, watchers, status)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return newTaskResolver(task), nil
+}
+
+func (r *resolver) UpdateTaskStatus(ctx context.Context, args struct {
+	ID     graphql.ID
+	Status string
+}) (*taskResolver, error) {
+
+	status, err := statusFromGraphQL(&args.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := r.service.UpdateStatus(ctx, string(args.ID), *status)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return newTaskResolver(task), nil
+}
+
+func (r *resolver) DeleteTask(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	if err := r.service.DeleteTask(ctx, string(args.ID)); err != nil {
+		return "", toError(ctx, err)
+	}
+	return args.ID, nil
+}
+
+func (r *resolver) AddAssignee(ctx context.Context, args userArgs) (*taskResolver, error) {
+	return r.updateUsers(ctx, r.service.AddAssignee, args)
+}
+
+func (r *resolver) RemoveAssignee(ctx context.Context, args userArgs) (*taskResolver, error) {
+	return r.updateUsers(ctx, r.service.RemoveAssignee, args)
+}
+
+func (r *resolver) AddWatcher(ctx context.Context, args userArgs) (*taskResolver, error) {
+	return r.updateUsers(ctx, r.service.AddWatcher, args)
+}
+
+func (r *resolver) RemoveWatcher(ctx context.Context, args userArgs) (*taskResolver, error) {
+	return r.updateUsers(ctx, r.service.RemoveWatcher, args)
+}
+
+func (r *resolver) updateUsers(
+	ctx context.Context,
+	update func(ctx context.Context, id string, user string) (*domain.Task, error),
+	args userArgs,
+) (*taskResolver, error) {
+
+	task, err := update(ctx, string(args.ID), args.User)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return newTaskResolver(task), nil
+}
+
+// TaskChanged follows the event stream like /events/stream. The WebSocket
+// pings of the transport take the place of its heartbeats.
+func (r *resolver) TaskChanged(ctx context.Context, args struct {
+	Filter   *taskFilterInput
+	AfterSeq *graphql.ID
+}) (<-chan *eventResolver, error) {
+
+	if r.hub == nil {
+		return nil, &resolverError{code: codeUnimplemented, message: "subscriptions require Postgres"}
+	}
+
+	filter, err := filterFromInput(args.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var afterSeq int64
+	if args.AfterSeq != nil {
+		afterSeq, err = strconv.ParseInt(string(*args.AfterSeq), 10, 64)
+		if err != nil || afterSeq < 0 {
+			return nil, badUserInput("invalid afterSeq")
+		}
+	}
+
+	events := make(chan *eventResolver)
+
+	go func() {
+		defer close(events)
+
+		err := r.hub.Follow(ctx, r.feed, filter, afterSeq, nil, func(e *domain.SequencedEvent) error {
+			select {
+			case events <- &eventResolver{e: e}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, nil)
+
+		switch {
+		case errors.Is(err, stream.ErrDropped):
+			logging.FromContext(ctx).Info("task subscription dropped")
+		case err != nil && ctx.Err() == nil:
+			logging.FromContext(ctx).Error("task subscription failed", "error", err)
+		}
+	}()
+
+	return events, nil
+}
+
+// apply sets the page of filter. Unlike the REST API, which lists every
+// task for limit 0, lists are always paged so the complexity of an
+// operation stays bounded.
+func (p pageArgs) apply(filter *domain.TaskFilter) error {
+	if p.Limit < 1 || p.Limit > maxListLimit {
+		return badUserInput("limit must be between 1 and %d", maxListLimit)
+	}
+	if p.Offset < 0 {
+		return badUserInput("offset must not be negative")
+	}
+
+	filter.Limit = int(p.Limit)
+	filter.Offset = int(p.Offset)
+	return nil
+}
+
+// filterFromInput builds a domain.TaskFilter; a nil filter matches every
+// task. Empty strings are treated like unset fields, as the REST API does
+// with empty query parameters.
+func filterFromInput(in *taskFilterInput) (domain.TaskFilter, error) {
+	var filter domain.TaskFilter
+	if in == nil {
+		return filter, nil
+	}
+
+	status, err := statusFromGraphQL(in.Status)
+	if err != nil {
+		return domain.TaskFilter{}, err
+	}
+	filter.Status = status
+
+	filter.Assignee = nonEmpty(in.Assignee)
+	filter.Watcher = nonEmpty(in.Watcher)
+	filter.Participant = nonEmpty(in.Participant)
+
+	return filter, nil
+}
+
+// statusFromGraphQL maps a TaskStatus value to the domain; nil maps to nil.
+// graphql-go has already rejected values outside the enum.
+func statusFromGraphQL(s *string) (*domain.TaskStatus, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	for status, name := range statusToGraphQL {
+		if name == *s {
+			return &status, nil
+		}
+	}
+	return nil, badUserInput("invalid task status %q", *s)
+}
+
+func nonEmpty(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
+
+type taskResolver struct {
+	t *domain.Task
+}
+
+// newTaskResolver returns nil for a nil task, which the schema answers with
+// null.
+func newTaskResolver(t *domain.Task) *taskResolver {
+	if t == nil {
+		return nil
+	}
+	return &taskResolver{t: t}
+}
+
+func (r *taskResolver) ID() graphql.ID {
+	return graphql.ID(r.t.ID)
+}
+
+func (r *taskResolver) Title() string {
+	return r.t.Title
+}
+
+func (r *taskResolver) Status() string {
+	return statusToGraphQL[r.t.Status]
+}
+
+func (r *taskResolver) Assignees() []string {
+	return orEmpty(r.t.Assignees)
+}
+
+func (r *taskResolver) Watchers() []string {
+	return orEmpty(r.t.Watchers)
+}
+
+func (r *taskResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.t.CreatedAt}
+}
+
+func (r *taskResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.t.UpdatedAt}
+}
+
+// orEmpty answers a nil list with an empty one, as the schema requires a
+// list.
+func orEmpty(users []string) []string {
+	if users == nil {
+		return []string{}
+	}
+	return users
+}
+
+type eventResolver struct {
+	e *domain.SequencedEvent
+}
+
+func (r *eventResolver) Seq() graphql.ID {
+	return graphql.ID(strconv.FormatInt(r.e.Seq, 10))
+}
+
+func (r *eventResolver) ID() graphql.ID {
+	return graphql.ID(r.e.Event.ID)
+}
+
+func (r *eventResolver) Type() string {
+	return eventTypeToGraphQL[r.e.Event.Type]
+}
+
+func (r *eventResolver) TaskID() graphql.ID {
+	return graphql.ID(r.e.Event.TaskID)
+}
+
+func (r *eventResolver) Task() *taskResolver {
+	return newTaskResolver(r.e.Event.Task)
+}
+
+func (r *eventResolver) OccurredAt() graphql.Time {
+	return graphql.Time{Time: r.e.Event.OccurredAt}
+}
@@ -0,0 +1,315 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"graph-task-service/internal/logging"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+// subprotocol is the graphql-transport-ws protocol of graphql-ws, the one
+// current GraphQL clients speak.
+const subprotocol = "graphql-transport-ws"
+
+const (
+	initTimeout  = 10 * time.Second
+	pingInterval = 15 * time.Second
+	writeTimeout = 5 * time.Second
+)
+
+// Message types of the protocol.
+const (
+	msgConnectionInit = "connection_init"
+	msgConnectionAck  = "connection_ack"
+	msgPing           = "ping"
+	msgPong           = "pong"
+	msgSubscribe      = "subscribe"
+	msgNext           = "next"
+	msgError          = "error"
+	msgComplete       = "complete"
+)
+
+// Close codes of the protocol.
+const (
+	closeInvalidMessage        = 4400
+	closeUnauthorized          = 4401
+	closeSubprotocolNotAllowed = 4406
+	closeInitTimeout           = 4408
+	closeSubscriberExists      = 4409
+	closeTooManyInits          = 4429
+)
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsConn is one WebSocket connection, multiplexing operations by id.
+type wsConn struct {
+	h    *Handler
+	conn *websocket.Conn
+	ctx  context.Context
+
+	// writeMu serializes writes, which gorilla/websocket requires.
+	writeMu sync.Mutex
+
+	mu    sync.Mutex
+	acked bool
+	ops   map[string]context.CancelFunc
+	wg    sync.WaitGroup
+}
+
+func (h *Handler) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Hijacked connections keep the deadlines of the server timeouts.
+	_ = conn.NetConn().SetDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	c := &wsConn{h: h, conn: conn, ctx: ctx, ops: make(map[string]context.CancelFunc)}
+	defer func() {
+		cancel()
+		c.wg.Wait()
+	}()
+
+	if conn.Subprotocol() != subprotocol {
+		c.close(closeSubprotocolNotAllowed, "Subprotocol not acceptable")
+		return
+	}
+
+	go c.keepAlive()
+	c.read()
+}
+
+// keepAlive closes connections that are not initialized in time and pings
+// the rest, so idle subscriptions survive proxies.
+func (c *wsConn) keepAlive() {
+	initTimer := time.NewTimer(initTimeout)
+	defer initTimer.Stop()
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-initTimer.C:
+			c.mu.Lock()
+			acked := c.acked
+			c.mu.Unlock()
+			if !acked {
+				c.close(closeInitTimeout, "Connection initialisation timeout")
+				return
+			}
+		case <-ping.C:
+			c.writeMu.Lock()
+			err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+			c.writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// read handles the messages of the client until the connection closes.
+func (c *wsConn) read() {
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.close(closeInvalidMessage, "Invalid message received")
+			return
+		}
+
+		switch msg.Type {
+		case msgConnectionInit:
+			c.mu.Lock()
+			initialized := c.acked
+			c.acked = true
+			c.mu.Unlock()
+			if initialized {
+				c.close(closeTooManyInits, "Too many initialisation requests")
+				return
+			}
+			c.write(wsMessage{Type: msgConnectionAck})
+		case msgPing:
+			c.write(wsMessage{Type: msgPong})
+		case msgPong:
+		case msgSubscribe:
+			if !c.subscribe(msg) {
+				return
+			}
+		case msgComplete:
+			c.stop(msg.ID)
+		default:
+			c.close(closeInvalidMessage, fmt.Sprintf("Invalid message type %q", msg.Type))
+			return
+		}
+	}
+}
+
+// subscribe starts the operation of msg. It reports false if it closed the
+// connection instead.
+func (c *wsConn) subscribe(msg wsMessage) bool {
+	var req request
+	if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil || req.Query == "" {
+		c.close(closeInvalidMessage, "Invalid subscribe message")
+		return false
+	}
+
+	c.mu.Lock()
+	if !c.acked {
+		c.mu.Unlock()
+		c.close(closeUnauthorized, "Unauthorized")
+		return false
+	}
+	if _, ok := c.ops[msg.ID]; ok {
+		c.mu.Unlock()
+		c.close(closeSubscriberExists, fmt.Sprintf("Subscriber for %s already exists", msg.ID))
+		return false
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.ops[msg.ID] = cancel
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.run(ctx, msg.ID, req)
+	}()
+	return true
+}
+
+// stop cancels operation id and forgets it, so its id can be reused.
+func (c *wsConn) stop(id string) {
+	c.mu.Lock()
+	cancel, ok := c.ops[id]
+	delete(c.ops, id)
+	c.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+// run executes one operation and sends its results. Failures before the
+// first result, such as invalid documents, end it with an error message;
+// otherwise it ends with complete, unless the client completed it.
+func (c *wsConn) run(ctx context.Context, id string, req request) {
+	// Stopping also cancels the subscription within graphql-go.
+	defer c.stop(id)
+
+	op := analyze(req.Query, req.OperationName, req.Variables)
+
+	if op.kind != "subscription" {
+		resp := c.h.exec(ctx, req, op)
+		if isOperationError(resp) {
+			c.sendError(id, resp.Errors)
+			return
+		}
+		if c.sendNext(ctx, id, resp) {
+			c.complete(ctx, id)
+		}
+		return
+	}
+
+	if err := c.h.checkComplexity(op); err != nil {
+		c.sendError(id, []*gqlerrors.QueryError{err})
+		return
+	}
+
+	results, err := c.h.schema.Subscribe(ctx, req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		logging.FromContext(ctx).Error("graphql subscribe failed", "error", err)
+		c.sendError(id, []*gqlerrors.QueryError{{
+			Message:    "internal error",
+			Extensions: map[string]any{"code": codeInternal},
+		}})
+		return
+	}
+
+	first := true
+	for result := range results {
+		resp := result.(*graphql.Response)
+		resp.Errors = withCodes(resp.Errors)
+		if first && isOperationError(resp) {
+			c.sendError(id, resp.Errors)
+			return
+		}
+		first = false
+
+		if !c.sendNext(ctx, id, resp) {
+			return
+		}
+	}
+
+	c.complete(ctx, id)
+}
+
+// isOperationError reports whether resp failed without any data, as
+// operations rejected by validation do.
+func isOperationError(resp *graphql.Response) bool {
+	return resp.Data == nil && len(resp.Errors) > 0
+}
+
+func (c *wsConn) sendNext(ctx context.Context, id string, resp *graphql.Response) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	payload, err := json.Marshal(resp)
+	if err != nil {
+		return false
+	}
+	return c.write(wsMessage{ID: id, Type: msgNext, Payload: payload})
+}
+
+func (c *wsConn) sendError(id string, errs []*gqlerrors.QueryError) {
+	payload, _ := json.Marshal(errs)
+	c.write(wsMessage{ID: id, Type: msgError, Payload: payload})
+}
+
+// complete tells the client operation id is done, unless it completed it
+// itself.
+func (c *wsConn) complete(ctx context.Context, id string) {
+	if ctx.Err() == nil {
+		c.write(wsMessage{ID: id, Type: msgComplete})
+	}
+}
+
+func (c *wsConn) write(msg wsMessage) bool {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.conn.WriteJSON(msg) == nil
+}
+
+// close closes the connection with a close code of the protocol. The read
+// loop then fails and tears the connection down.
+func (c *wsConn) close(code int, reason string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_ = c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason), time.Now().Add(writeTimeout))
+	_ = c.conn.Close()
+}
@@ -0,0 +1,94 @@
+package grpc
+
+import (
+	taskv1 "graph-task-service/api/task/v1"
+	"graph-task-service/internal/domain"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	statusToProto = map[domain.TaskStatus]taskv1.TaskStatus{
+		domain.StatusTodo:       taskv1.TaskStatus_TASK_STATUS_TODO,
+		domain.StatusInProgress: taskv1.TaskStatus_TASK_STATUS_IN_PROGRESS,
+		domain.StatusDone:       taskv1.TaskStatus_TASK_STATUS_DONE,
+	}
+
+	eventTypeToProto = map[domain.EventType]taskv1.EventType{
+		domain.EventTaskCreated:       taskv1.EventType_EVENT_TYPE_TASK_CREATED,
+		domain.EventTaskStatusChanged: taskv1.EventType_EVENT_TYPE_TASK_STATUS_CHANGED,
+		domain.EventTaskUpdated:       taskv1.EventType_EVENT_TYPE_TASK_UPDATED,
+		domain.EventTaskDeleted:       taskv1.EventType_EVENT_TYPE_TASK_DELETED,
+	}
+)
+
+// statusFromProto maps a status of the API to the domain. Unspecified maps
+// to nil; an unknown value fails.
+func statusFromProto(s taskv1.TaskStatus) (*domain.TaskStatus, error) {
+	if s == taskv1.TaskStatus_TASK_STATUS_UNSPECIFIED {
+		return nil, nil
+	}
+
+	for status, p := range statusToProto {
+		if p == s {
+			return &status, nil
+		}
+	}
+	return nil, invalidArgument("status", "invalid task status")
+}
+
+func toProtoTask(t *domain.Task) *taskv1.Task {
+	return &taskv1.Task{
+		Id:        t.ID,
+		Title:     t.Title,
+		Status:    statusToProto[t.Status],
+		Assignees: t.Assignees,
+		Watchers:  t.Watchers,
+		CreatedAt: timestamppb.New(t.CreatedAt),
+		UpdatedAt: timestamppb.New(t.UpdatedAt),
+	}
+}
+
+func toProtoEvent(e *domain.SequencedEvent) *taskv1.TaskEvent {
+	event := &taskv1.TaskEvent{
+		Seq:        e.Seq,
+		Id:         e.Event.ID,
+		Type:       eventTypeToProto[e.Event.Type],
+		TaskId:     e.Event.TaskID,
+		OccurredAt: timestamppb.New(e.Event.OccurredAt),
+	}
+	if e.Event.Task != nil {
+		event.Task = toProtoTask(e.Event.Task)
+	}
+	return event
+}
+
+// filterFromProto builds a domain.TaskFilter; a nil filter matches every
+// task.
+func filterFromProto(f *taskv1.TaskFilter) (domain.TaskFilter, error) {
+	var filter domain.TaskFilter
+	if f == nil {
+		return filter, nil
+	}
+
+	status, err := statusFromProto(f.GetStatus())
+	if err != nil {
+		return domain.TaskFilter{}, invalidArgument("filter.status", "invalid task status")
+	}
+	filter.Status = status
+
+	filter.Assignee = nonEmpty(f.Assignee)
+	filter.Watcher = nonEmpty(f.Watcher)
+	filter.Participant = nonEmpty(f.Participant)
+
+	return filter, nil
+}
+
+// nonEmpty treats an empty string like an unset field, as the REST API
+// does with empty query parameters.
+func nonEmpty(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
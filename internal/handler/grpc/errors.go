@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/logging"
+	"graph-task-service/internal/service"
+	"graph-task-service/internal/stream"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps an error of the service layer to a gRPC status. The codes
+// are those grpc-gateway maps back to the status codes of the REST API:
+// NOT_FOUND is 404, INVALID_ARGUMENT 400 and UNAVAILABLE 503. Unexpected
+// errors are logged and answered with INTERNAL without their message.
+func toStatus(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrEmptyTitle):
+		return invalidArgument("title", err.Error())
+	case errors.Is(err, service.ErrInvalidStatus):
+		return invalidArgument("status", err.Error())
+	case errors.Is(err, service.ErrEmptyUser):
+		return invalidArgument("user", err.Error())
+	case errors.Is(err, stream.ErrDropped):
+		return status.Error(codes.Unavailable, "event stream dropped, resume after the last seq received")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	logging.FromContext(ctx).Error("rpc failed", "error", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// invalidArgument returns INVALID_ARGUMENT with a google.rpc.BadRequest
+// detail naming the offending request field.
+func invalidArgument(field, description string) error {
+	st := status.New(codes.InvalidArgument, description)
+
+	detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: field, Description: description},
+		},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,151 @@
+package grpc
+
+import (
+	"context"
+	taskv1 "graph-task-service/api/task/v1"
+	"graph-task-service/internal/logging"
+	"graph-task-service/internal/middelware"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+type Options struct {
+	// UserHeader is the metadata key carrying the caller identity, matched
+	// case-insensitively like the REST header.
+	UserHeader string
+	// RequireUser rejects anonymous calls with UNAUTHENTICATED.
+	RequireUser bool
+	Logger      *slog.Logger
+}
+
+// NewServer returns a gRPC server of tasks with server reflection, so tools
+// such as grpcurl need no proto files. Every call gets the user from its
+// metadata, a logger in its context and one access log line; panics are
+// answered with INTERNAL.
+func NewServer(tasks *TaskServer, opts Options) *grpc.Server {
+	i := &interceptor{
+		userKey:     strings.ToLower(opts.UserHeader),
+		requireUser: opts.RequireUser,
+		logger:      opts.Logger,
+	}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(i.unary),
+		grpc.ChainStreamInterceptor(i.stream),
+		// Pings keep idle watches open through proxies and detect dead
+		// clients, like the heartbeats of the event streams.
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: 15 * time.Second, Timeout: 5 * time.Second}),
+	)
+
+	taskv1.RegisterTaskServiceServer(srv, tasks)
+	reflection.Register(srv)
+
+	return srv
+}
+
+type interceptor struct {
+	userKey     string
+	requireUser bool
+	logger      *slog.Logger
+}
+
+func (i *interceptor) unary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+
+	ctx, done, err := i.begin(ctx, info.FullMethod)
+	defer func() { done(err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	defer recoverPanic(ctx, &err)
+	return handler(ctx, req)
+}
+
+func (i *interceptor) stream(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+
+	ctx, done, err := i.begin(ss.Context(), info.FullMethod)
+	defer func() { done(err) }()
+	if err != nil {
+		return err
+	}
+
+	defer recoverPanic(ctx, &err)
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// begin authenticates a call and returns its context and the function
+// logging its outcome.
+func (i *interceptor) begin(ctx context.Context, method string) (context.Context, func(error), error) {
+	start := time.Now()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	user := ""
+	if values := md.Get(i.userKey); len(values) > 0 {
+		user = values[0]
+	}
+
+	logger := i.logger.With("rpc", method)
+	if user != "" {
+		ctx = middelware.WithUser(ctx, user)
+		logger = logger.With("user", user)
+	}
+	ctx = logging.WithLogger(ctx, logger)
+
+	done := func(err error) {
+		code := status.Code(err)
+
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK, codes.Canceled:
+		case codes.Internal, codes.Unknown, codes.DataLoss:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+
+		logger.LogAttrs(ctx, level, "rpc",
+			slog.String("code", code.String()),
+			slog.Duration("latency", time.Since(start)),
+		)
+	}
+
+	if i.requireUser && user == "" {
+		return ctx, done, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	return ctx, done, nil
+}
+
+func recoverPanic(ctx context.Context, err *error) {
+	if p := recover(); p != nil {
+		logging.FromContext(ctx).Error("panic recovered", "panic", p)
+		*err = status.Error(codes.Internal, "internal error")
+	}
+}
+
+// contextStream replaces the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,176 @@
+// Package grpc serves the task API over gRPC; see api/task/v1/task.proto.
+// Its RPCs call the same service layer as the REST handlers.
+package grpc
+
+import (
+	"context"
+	taskv1 "graph-task-service/api/task/v1"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/service"
+	"graph-task-service/internal/stream"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultListLimit = 20
+
+type TaskServer struct {
+	taskv1.UnimplementedTaskServiceServer
+
+	service service.TaskService
+	hub     *stream.Hub
+	feed    domain.TaskEventFeed
+}
+
+// NewTaskServer returns the gRPC task service. hub and feed serve
+// WatchTasks; without them, as on servers without Postgres, WatchTasks
+// answers UNIMPLEMENTED.
+func NewTaskServer(s service.TaskService, hub *stream.Hub, feed domain.TaskEventFeed) *TaskServer {
+	return &TaskServer{service: s, hub: hub, feed: feed}
+}
+
+func (s *TaskServer) CreateTask(
+	ctx context.Context,
+	req *taskv1.CreateTaskRequest,
+) (*taskv1.CreateTaskResponse, error) {
+
+	taskStatus, err := statusFromProto(req.GetStatus())
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.service.CreateTask(ctx, req.GetTitle(), req.GetAssignees(), req.GetWatchers(), taskStatus)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &taskv1.CreateTaskResponse{Task: toProtoTask(task)}, nil
+}
+
+func (s *TaskServer) GetTask(
+	ctx context.Context,
+	req *taskv1.GetTaskRequest,
+) (*taskv1.GetTaskResponse, error) {
+
+	if req.GetId() == "" {
+		return nil, invalidArgument("id", "id is required")
+	}
+
+	task, err := s.service.GetTask(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &taskv1.GetTaskResponse{Task: toProtoTask(task)}, nil
+}
+
+func (s *TaskServer) ListTasks(
+	ctx context.Context,
+	req *taskv1.ListTasksRequest,
+) (*taskv1.ListTasksResponse, error) {
+
+	filter, err := filterFromProto(req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.GetLimit() < 0:
+		return nil, invalidArgument("limit", "limit must not be negative")
+	case req.GetOffset() < 0:
+		return nil, invalidArgument("offset", "offset must not be negative")
+	}
+
+	filter.Limit = int(req.GetLimit())
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+	filter.Offset = int(req.GetOffset())
+
+	tasks, err := s.service.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &taskv1.ListTasksResponse{Tasks: make([]*taskv1.Task, 0, len(tasks))}
+	for _, t := range tasks {
+		resp.Tasks = append(resp.Tasks, toProtoTask(t))
+	}
+	return resp, nil
+}
+
+func (s *TaskServer) UpdateTaskStatus(
+	ctx context.Context,
+	req *taskv1.UpdateTaskStatusRequest,
+) (*taskv1.UpdateTaskStatusResponse, error) {
+
+	if req.GetId() == "" {
+		return nil, invalidArgument("id", "id is required")
+	}
+
+	taskStatus, err := statusFromProto(req.GetStatus())
+	if err != nil {
+		return nil, err
+	}
+	if taskStatus == nil {
+		return nil, invalidArgument("status", "status is required")
+	}
+
+	task, err := s.service.UpdateStatus(ctx, req.GetId(), *taskStatus)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &taskv1.UpdateTaskStatusResponse{Task: toProtoTask(task)}, nil
+}
+
+func (s *TaskServer) DeleteTask(
+	ctx context.Context,
+	req *taskv1.DeleteTaskRequest,
+) (*taskv1.DeleteTaskResponse, error) {
+
+	if req.GetId() == "" {
+		return nil, invalidArgument("id", "id is required")
+	}
+
+	if err := s.service.DeleteTask(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &taskv1.DeleteTaskResponse{}, nil
+}
+
+// WatchTasks follows the event stream like /events/stream. gRPC keepalives
+// take the place of its heartbeats.
+func (s *TaskServer) WatchTasks(
+	req *taskv1.WatchTasksRequest,
+	srv grpc.ServerStreamingServer[taskv1.WatchTasksResponse],
+) error {
+
+	if s.hub == nil {
+		return status.Error(codes.Unimplemented, "watching tasks requires Postgres")
+	}
+
+	filter, err := filterFromProto(req.GetFilter())
+	if err != nil {
+		return err
+	}
+	if req.GetAfterSeq() < 0 {
+		return invalidArgument("after_seq", "after_seq must not be negative")
+	}
+
+	ctx := srv.Context()
+
+	// Headers go out right away so clients know the watch is established
+	// before the first event.
+	if err := srv.SendHeader(nil); err != nil {
+		return err
+	}
+
+	err = s.hub.Follow(ctx, s.feed, filter, req.GetAfterSeq(), nil, func(e *domain.SequencedEvent) error {
+		return srv.Send(&taskv1.WatchTasksResponse{Event: toProtoEvent(e)})
+	}, nil)
+	return toStatus(ctx, err)
+}
@@ -0,0 +1,275 @@
+package grpc_test
+
+import (
+	"context"
+	taskv1 "graph-task-service/api/task/v1"
+	"graph-task-service/internal/domain"
+	handlerGrpc "graph-task-service/internal/handler/grpc"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/service"
+	"graph-task-service/internal/stream"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeFeed struct {
+	events []*domain.SequencedEvent
+}
+
+func (f *fakeFeed) Listen(ctx context.Context, handle func(*domain.SequencedEvent)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (f *fakeFeed) Since(ctx context.Context, seq int64, limit int) ([]*domain.SequencedEvent, error) {
+	var out []*domain.SequencedEvent
+	for _, e := range f.events {
+		if e.Seq > seq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func sequenced(seq int64, id string, assignee string) *domain.SequencedEvent {
+	return &domain.SequencedEvent{
+		Seq: seq,
+		Event: domain.TaskEvent{
+			ID:     id,
+			Type:   domain.EventTaskCreated,
+			TaskID: id,
+			Task:   &domain.Task{ID: id, Status: domain.StatusTodo, Assignees: []string{assignee}},
+		},
+	}
+}
+
+// newTestClient serves tasks from memory over an in-process connection.
+func newTestClient(
+	t *testing.T,
+	hub *stream.Hub,
+	feed domain.TaskEventFeed,
+	opts handlerGrpc.Options,
+) (*grpc.ClientConn, taskv1.TaskServiceClient) {
+
+	t.Helper()
+
+	opts.UserHeader = "X-User"
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tasks := service.NewTaskService(memory.NewTaskRepository(), nil)
+	srv := handlerGrpc.NewServer(handlerGrpc.NewTaskServer(tasks, hub, feed), opts)
+
+	ln := bufconn.Listen(1 << 20)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, taskv1.NewTaskServiceClient(conn)
+}
+
+func requireCode(t *testing.T, err error, code codes.Code) *status.Status {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok, "not a status: %v", err)
+	require.Equal(t, code, st.Code(), st.Message())
+	return st
+}
+
+func TestTaskServer_Lifecycle(t *testing.T) {
+	_, client := newTestClient(t, nil, nil, handlerGrpc.Options{})
+	ctx := context.Background()
+
+	created, err := client.CreateTask(ctx, &taskv1.CreateTaskRequest{
+		Title:     "Ship gRPC",
+		Assignees: []string{"alice"},
+		Watchers:  []string{"bob"},
+	})
+	require.NoError(t, err)
+
+	task := created.GetTask()
+	assert.NotEmpty(t, task.GetId())
+	assert.Equal(t, taskv1.TaskStatus_TASK_STATUS_TODO, task.GetStatus())
+	assert.Equal(t, []string{"alice"}, task.GetAssignees())
+	assert.False(t, task.GetCreatedAt().AsTime().IsZero())
+
+	got, err := client.GetTask(ctx, &taskv1.GetTaskRequest{Id: task.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, "Ship gRPC", got.GetTask().GetTitle())
+
+	_, err = client.CreateTask(ctx, &taskv1.CreateTaskRequest{Title: "Other", Status: taskv1.TaskStatus_TASK_STATUS_DONE})
+	require.NoError(t, err)
+
+	watcher := "bob"
+	list, err := client.ListTasks(ctx, &taskv1.ListTasksRequest{
+		Filter: &taskv1.TaskFilter{Status: taskv1.TaskStatus_TASK_STATUS_TODO, Watcher: &watcher},
+	})
+	require.NoError(t, err)
+	require.Len(t, list.GetTasks(), 1)
+	assert.Equal(t, task.GetId(), list.GetTasks()[0].GetId())
+
+	updated, err := client.UpdateTaskStatus(ctx, &taskv1.UpdateTaskStatusRequest{
+		Id:     task.GetId(),
+		Status: taskv1.TaskStatus_TASK_STATUS_IN_PROGRESS,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, taskv1.TaskStatus_TASK_STATUS_IN_PROGRESS, updated.GetTask().GetStatus())
+
+	_, err = client.DeleteTask(ctx, &taskv1.DeleteTaskRequest{Id: task.GetId()})
+	require.NoError(t, err)
+
+	_, err = client.GetTask(ctx, &taskv1.GetTaskRequest{Id: task.GetId()})
+	requireCode(t, err, codes.NotFound)
+
+	_, err = client.DeleteTask(ctx, &taskv1.DeleteTaskRequest{Id: task.GetId()})
+	requireCode(t, err, codes.NotFound)
+}
+
+func TestTaskServer_InvalidArguments(t *testing.T) {
+	_, client := newTestClient(t, nil, nil, handlerGrpc.Options{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		call  func() error
+		field string
+	}{
+		{"empty title", func() error {
+			_, err := client.CreateTask(ctx, &taskv1.CreateTaskRequest{})
+			return err
+		}, "title"},
+		{"unknown status", func() error {
+			_, err := client.CreateTask(ctx, &taskv1.CreateTaskRequest{Title: "x", Status: 42})
+			return err
+		}, "status"},
+		{"missing status", func() error {
+			_, err := client.UpdateTaskStatus(ctx, &taskv1.UpdateTaskStatusRequest{Id: "1"})
+			return err
+		}, "status"},
+		{"missing id", func() error {
+			_, err := client.GetTask(ctx, &taskv1.GetTaskRequest{})
+			return err
+		}, "id"},
+		{"negative limit", func() error {
+			_, err := client.ListTasks(ctx, &taskv1.ListTasksRequest{Limit: -1})
+			return err
+		}, "limit"},
+		{"unknown filter status", func() error {
+			_, err := client.ListTasks(ctx, &taskv1.ListTasksRequest{Filter: &taskv1.TaskFilter{Status: 42}})
+			return err
+		}, "filter.status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := requireCode(t, tt.call(), codes.InvalidArgument)
+
+			require.Len(t, st.Details(), 1)
+			badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+			require.True(t, ok)
+			assert.Equal(t, tt.field, badRequest.GetFieldViolations()[0].GetField())
+		})
+	}
+}
+
+func TestTaskServer_RequireUser(t *testing.T) {
+	_, client := newTestClient(t, nil, nil, handlerGrpc.Options{RequireUser: true})
+
+	_, err := client.ListTasks(context.Background(), &taskv1.ListTasksRequest{})
+	requireCode(t, err, codes.Unauthenticated)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-user", "alice")
+	_, err = client.ListTasks(ctx, &taskv1.ListTasksRequest{})
+	require.NoError(t, err)
+}
+
+func TestTaskServer_WatchTasks(t *testing.T) {
+	hub := stream.NewHub(8)
+	feed := &fakeFeed{events: []*domain.SequencedEvent{
+		sequenced(1, "t1", "alice"),
+		sequenced(2, "t2", "bob"),
+		sequenced(3, "t3", "alice"),
+	}}
+	_, client := newTestClient(t, hub, feed, handlerGrpc.Options{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	alice := "alice"
+	watch, err := client.WatchTasks(ctx, &taskv1.WatchTasksRequest{
+		Filter:   &taskv1.TaskFilter{Assignee: &alice},
+		AfterSeq: 1,
+	})
+	require.NoError(t, err)
+
+	resp, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.GetEvent().GetSeq(), "replays matching events after the seq")
+	assert.Equal(t, taskv1.EventType_EVENT_TYPE_TASK_CREATED, resp.GetEvent().GetType())
+	assert.Equal(t, "t3", resp.GetEvent().GetTask().GetId())
+
+	// The watch subscribes before replaying, so a live event can be
+	// broadcast once the replay has arrived.
+	hub.Broadcast(sequenced(4, "t4", "bob"))
+	hub.Broadcast(sequenced(5, "t5", "alice"))
+
+	resp, err = watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), resp.GetEvent().GetSeq())
+
+	hub.Close()
+
+	_, err = watch.Recv()
+	requireCode(t, err, codes.Unavailable)
+}
+
+func TestTaskServer_WatchTasksWithoutPostgres(t *testing.T) {
+	_, client := newTestClient(t, nil, nil, handlerGrpc.Options{})
+
+	watch, err := client.WatchTasks(context.Background(), &taskv1.WatchTasksRequest{})
+	require.NoError(t, err)
+
+	_, err = watch.Recv()
+	requireCode(t, err, codes.Unimplemented)
+}
+
+func TestServer_Reflection(t *testing.T) {
+	conn, _ := newTestClient(t, nil, nil, handlerGrpc.Options{})
+
+	info, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, info.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := info.Recv()
+	require.NoError(t, err)
+
+	var names []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		names = append(names, s.GetName())
+	}
+	assert.Contains(t, names, "task.v1.TaskService")
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeConditional writes body as the 200 JSON response of a GET, with a
+// strong ETag computed from the encoded body and Last-Modified set to
+// lastModified. When the validators of the request show the client already
+// has this representation, it answers 304 Not Modified instead.
+//
+// If-None-Match takes precedence over If-Modified-Since. The latter is only
+// evaluated when exactModified is set: the newest UpdatedAt of a list does
+// not change when a task is deleted or leaves the filter, so for lists only
+// the ETag is reliable.
+func writeConditional(c *gin.Context, body any, lastModified time.Time, exactModified bool) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, lastModified, exactModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+func notModified(r *http.Request, etag string, lastModified time.Time, exactModified bool) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	if !exactModified || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	// HTTP dates have a resolution of one second.
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches applies the weak comparison If-None-Match calls for to the
+// comma separated list header.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
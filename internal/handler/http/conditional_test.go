@@ -0,0 +1,119 @@
+package http_test
+
+import (
+	"graph-task-service/internal/domain"
+	handlerHttp "graph-task-service/internal/handler/http"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupReadRouter(service *MockTaskService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := handlerHttp.NewTaskHandler(service)
+
+	r := gin.New()
+	r.GET("/tasks", handler.List)
+	r.GET("/tasks/:id", handler.GetByID)
+
+	return r
+}
+
+func get(r *gin.Engine, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTaskHandler_GetByID_Conditional(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+	task := &domain.Task{ID: "1", Title: "cached", Status: domain.StatusTodo, UpdatedAt: updatedAt}
+
+	service := new(MockTaskService)
+	service.On("GetTask", mock.Anything, "1").Return(task, nil)
+	r := setupReadRouter(service)
+
+	rec := get(r, "/tasks/1", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	etag := rec.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag, "strong ETag")
+	assert.Equal(t, "Sun, 01 Mar 2026 12:00:00 GMT", rec.Header().Get("Last-Modified"))
+	assert.Equal(t, etag, get(r, "/tasks/1", nil).Header().Get("ETag"), "same content, same ETag")
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   int
+	}{
+		{"matching etag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"etag in list", map[string]string{"If-None-Match": `"other", ` + etag}, http.StatusNotModified},
+		{"weak form of the etag", map[string]string{"If-None-Match": "W/" + etag}, http.StatusNotModified},
+		{"any", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"other etag", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"not modified since", map[string]string{"If-Modified-Since": "Sun, 01 Mar 2026 12:00:00 GMT"}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": "Sun, 01 Mar 2026 11:59:59 GMT"}, http.StatusOK},
+		{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+		{
+			"etag takes precedence",
+			map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": "Sun, 01 Mar 2026 12:00:00 GMT"},
+			http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(r, "/tasks/1", tt.header)
+
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			if tt.want == http.StatusNotModified {
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTaskHandler_List_Conditional(t *testing.T) {
+	older := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	service := new(MockTaskService)
+	service.On("ListTasks", mock.Anything, mock.Anything).Return([]*domain.Task{
+		{ID: "1", Title: "one", UpdatedAt: newer},
+		{ID: "2", Title: "two", UpdatedAt: older},
+	}, nil).Twice()
+	r := setupReadRouter(service)
+
+	rec := get(r, "/tasks", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Sun, 01 Mar 2026 13:00:00 GMT", rec.Header().Get("Last-Modified"), "newest task")
+
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, http.StatusNotModified, get(r, "/tasks", map[string]string{"If-None-Match": etag}).Code)
+
+	// A list loses tasks without its newest UpdatedAt changing, so only the
+	// ETag decides.
+	service.On("ListTasks", mock.Anything, mock.Anything).Return([]*domain.Task{
+		{ID: "1", Title: "one", UpdatedAt: newer},
+	}, nil)
+
+	rec = get(r, "/tasks", map[string]string{"If-Modified-Since": "Sun, 01 Mar 2026 13:00:00 GMT"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = get(r, "/tasks", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"graph-task-service/internal/domain"
 	handlerHttp "graph-task-service/internal/handler/http"
+	"graph-task-service/internal/middelware"
 	"net/http"
 	"net/http/httptest"
 
@@ -23,11 +24,12 @@ type MockTaskService struct {
 func (m *MockTaskService) CreateTask(
 	ctx context.Context,
 	title string,
-	assignee *string,
+	assignees []string,
+	watchers []string,
 	status *domain.TaskStatus,
 ) (*domain.Task, error) {
 
-	args := m.Called(ctx, title, assignee, status)
+	args := m.Called(ctx, title, assignees, watchers, status)
 	task, _ := args.Get(0).(*domain.Task)
 	return task, args.Error(1)
 }
@@ -42,6 +44,22 @@ func (m *MockTaskService) ListTasks(
 	return tasks, args.Error(1)
 }
 
+func (m *MockTaskService) StreamTasks(
+	ctx context.Context,
+	filter domain.TaskFilter,
+	fn func(*domain.Task) error,
+) error {
+
+	args := m.Called(ctx, filter)
+	tasks, _ := args.Get(0).([]*domain.Task)
+	for _, task := range tasks {
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MockTaskService) GetTask(
 	ctx context.Context,
 	id string,
@@ -52,6 +70,16 @@ func (m *MockTaskService) GetTask(
 	return task, args.Error(1)
 }
 
+func (m *MockTaskService) GetTasks(
+	ctx context.Context,
+	ids []string,
+) ([]*domain.Task, error) {
+
+	args := m.Called(ctx, ids)
+	tasks, _ := args.Get(0).([]*domain.Task)
+	return tasks, args.Error(1)
+}
+
 func (m *MockTaskService) UpdateStatus(
 	ctx context.Context,
 	id string,
@@ -72,11 +100,38 @@ func (m *MockTaskService) DeleteTask(
 	return args.Error(0)
 }
 
+func (m *MockTaskService) AddAssignee(ctx context.Context, id string, user string) (*domain.Task, error) {
+	args := m.Called(ctx, id, user)
+	task, _ := args.Get(0).(*domain.Task)
+	return task, args.Error(1)
+}
+
+func (m *MockTaskService) RemoveAssignee(ctx context.Context, id string, user string) (*domain.Task, error) {
+	args := m.Called(ctx, id, user)
+	task, _ := args.Get(0).(*domain.Task)
+	return task, args.Error(1)
+}
+
+func (m *MockTaskService) AddWatcher(ctx context.Context, id string, user string) (*domain.Task, error) {
+	args := m.Called(ctx, id, user)
+	task, _ := args.Get(0).(*domain.Task)
+	return task, args.Error(1)
+}
+
+func (m *MockTaskService) RemoveWatcher(ctx context.Context, id string, user string) (*domain.Task, error) {
+	args := m.Called(ctx, id, user)
+	task, _ := args.Get(0).(*domain.Task)
+	return task, args.Error(1)
+}
+
 func setupRouter(handler *handlerHttp.TaskHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 
 	r := gin.New()
+	r.Use(middelware.Authenticate())
 	r.POST("/tasks", handler.Create)
+	r.GET("/me/tasks", handler.ListMine)
+	r.POST("/tasks/:id/assignees", handler.AddAssignee)
 
 	return r
 }
@@ -96,10 +151,10 @@ func TestTaskHandler_Create_Success(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	expectedTask := &domain.Task{
-		ID:       "c292d1f6-b03b-4490-a2cb-3bd272f05dda",
-		Title:    "test task",
-		Assignee: &assignee,
-		Status:   domain.StatusTodo,
+		ID:        "c292d1f6-b03b-4490-a2cb-3bd272f05dda",
+		Title:     "test task",
+		Assignees: []string{assignee},
+		Status:    domain.StatusTodo,
 	}
 
 	service.
@@ -107,7 +162,8 @@ func TestTaskHandler_Create_Success(t *testing.T) {
 			"CreateTask",
 			mock.Anything,
 			"test task",
-			&assignee,
+			[]string{assignee},
+			([]string)(nil),
 			(*domain.TaskStatus)(nil),
 		).
 		Return(expectedTask, nil)
@@ -127,3 +183,63 @@ func TestTaskHandler_Create_Success(t *testing.T) {
 
 	service.AssertExpectations(t)
 }
+
+func TestTaskHandler_ListMine_Unauthenticated(t *testing.T) {
+	service := new(MockTaskService)
+	handler := handlerHttp.NewTaskHandler(service)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/me/tasks", nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	service.AssertExpectations(t)
+}
+
+func TestTaskHandler_ListMine_FiltersByParticipant(t *testing.T) {
+	service := new(MockTaskService)
+	handler := handlerHttp.NewTaskHandler(service)
+	router := setupRouter(handler)
+
+	service.
+		On(
+			"ListTasks",
+			mock.Anything,
+			mock.MatchedBy(func(f domain.TaskFilter) bool {
+				return f.Participant != nil && *f.Participant == "abo" &&
+					f.Assignee == nil && f.Watcher == nil
+			}),
+		).
+		Return([]*domain.Task{{ID: "1", Title: "mine", Watchers: []string{"abo"}}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/me/tasks?assignee=someone", nil)
+	req.Header.Set(middelware.UserHeader, "abo")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"watchers":["abo"]`)
+	service.AssertExpectations(t)
+}
+
+func TestTaskHandler_AddAssignee_MissingUser(t *testing.T) {
+	service := new(MockTaskService)
+	handler := handlerHttp.NewTaskHandler(service)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/tasks/1/assignees",
+		bytes.NewBufferString(`{}`),
+	)
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"graph-task-service/internal/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// Livez godoc
+// @Summary      Liveness probe
+// @Description  Reports that the process is running. It does not check dependencies, so an unavailable database never gets the pod restarted.
+// @Tags         health
+// @Produce      json
+// @Success      200 {object} map[string]string
+// @Router       /livez [get]
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusPass})
+}
+
+// Readyz godoc
+// @Summary      Readiness probe
+// @Description  Runs every registered dependency check and reports each result. Fails while the server is shutting down.
+// @Tags         health
+// @Produce      json
+// @Success      200 {object} health.Report
+// @Failure      503 {object} health.Report
+// @Router       /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != health.StatusPass {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
@@ -0,0 +1,51 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	handlerHttp "graph-task-service/internal/handler/http"
+	"graph-task-service/internal/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler_Readyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dbErr := errors.New("connection refused")
+
+	registry := health.NewRegistry(time.Second)
+	registry.Register("database", func(ctx context.Context) error { return dbErr })
+
+	handler := handlerHttp.NewHealthHandler(registry)
+
+	r := gin.New()
+	r.GET("/livez", handler.Livez)
+	r.GET("/readyz", handler.Readyz)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var report health.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["database"].Error)
+
+	dbErr = nil
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -3,8 +3,11 @@ package http
 import "graph-task-service/internal/domain"
 
 type CreateRequest struct {
-	Title       string             `json:"title" binding:"required"`
+	Title string `json:"title" binding:"required"`
+	// Assignee is kept for clients that predate multiple assignees; it is merged into Assignees.
 	Assignee    *string            `json:"assignee"`
+	Assignees   []string           `json:"assignees"`
+	Watchers    []string           `json:"watchers"`
 	Description *string            `json:"description"`
 	Status      *domain.TaskStatus `json:"status"`
 }
@@ -12,3 +15,21 @@ type CreateRequest struct {
 type UpdateStatusRequest struct {
 	Status domain.TaskStatus `json:"status" binding:"required"`
 }
+
+type UserRequest struct {
+	User string `json:"user" binding:"required" example:"alice"`
+}
+
+type CreateWebhookRequest struct {
+	URL string `json:"url" binding:"required" example:"https://ci.example.com/hooks/tasks"`
+	// Events limits deliveries to the listed types; omit to receive every event.
+	Events []domain.EventType `json:"events" example:"task.created,task.deleted"`
+	// Secret signs deliveries; a random one is generated when omitted.
+	Secret *string `json:"secret"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    string             `json:"url" binding:"required"`
+	Events []domain.EventType `json:"events"`
+	Active *bool              `json:"active" binding:"required"`
+}
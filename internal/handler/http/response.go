@@ -6,12 +6,13 @@ import (
 )
 
 type TaskResponse struct {
-	ID        string  `json:"id"`
-	Title     string  `json:"title"`
-	Status    string  `json:"status"`
-	Assignee  *string `json:"assignee,omitempty"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Status    string   `json:"status"`
+	Assignees []string `json:"assignees"`
+	Watchers  []string `json:"watchers"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
 }
 
 func FromDomain(t *domain.Task) TaskResponse {
@@ -19,8 +20,136 @@ func FromDomain(t *domain.Task) TaskResponse {
 		ID:        t.ID,
 		Title:     t.Title,
 		Status:    string(t.Status),
-		Assignee:  t.Assignee,
+		Assignees: orEmpty(t.Assignees),
+		Watchers:  orEmpty(t.Watchers),
 		CreatedAt: t.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: t.UpdatedAt.Format(time.RFC3339),
 	}
 }
+
+// orEmpty makes empty user lists render as [] instead of null.
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+type WebhookResponse struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+	// Secret is only returned when the webhook is created.
+	Secret    string `json:"secret,omitempty"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func FromWebhook(w *domain.Webhook) WebhookResponse {
+	events := make([]string, 0, len(w.Events))
+	for _, e := range w.Events {
+		events = append(events, string(e))
+	}
+
+	return WebhookResponse{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    events,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: w.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+type WebhookDeliveryResponse struct {
+	ID            string  `json:"id"`
+	EventID       string  `json:"event_id"`
+	EventType     string  `json:"event_type"`
+	Status        string  `json:"status"`
+	Attempts      int     `json:"attempts"`
+	NextAttemptAt string  `json:"next_attempt_at"`
+	LastError     *string `json:"last_error,omitempty"`
+	ResponseCode  *int    `json:"response_code,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+	DeliveredAt   *string `json:"delivered_at,omitempty"`
+}
+
+func FromWebhookDelivery(d *domain.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:            d.ID,
+		EventID:       d.EventID,
+		EventType:     string(d.EventType),
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt.Format(time.RFC3339),
+		LastError:     d.LastError,
+		ResponseCode:  d.ResponseCode,
+		CreatedAt:     d.CreatedAt.Format(time.RFC3339),
+	}
+
+	if d.DeliveredAt != nil {
+		deliveredAt := d.DeliveredAt.Format(time.RFC3339)
+		resp.DeliveredAt = &deliveredAt
+	}
+
+	return resp
+}
+
+type ImportRowErrorResponse struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportResultResponse struct {
+	DryRun  bool `json:"dry_run"`
+	Rows    int  `json:"rows"`
+	Invalid int  `json:"invalid"`
+	Created int  `json:"created"`
+	// Errors lists the first rejected rows.
+	Errors []ImportRowErrorResponse `json:"errors"`
+}
+
+func FromImportResult(r *domain.ImportResult) ImportResultResponse {
+	errs := make([]ImportRowErrorResponse, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		errs = append(errs, ImportRowErrorResponse{Line: e.Line, Error: e.Message})
+	}
+
+	return ImportResultResponse{
+		DryRun:  r.DryRun,
+		Rows:    r.Rows,
+		Invalid: r.Invalid,
+		Created: r.Created,
+		Errors:  errs,
+	}
+}
+
+type ImportJobResponse struct {
+	ID     string               `json:"id"`
+	Status string               `json:"status" example:"running"`
+	Result ImportResultResponse `json:"result"`
+	// Error is why the import failed, unless rows were invalid.
+	Error      *string `json:"error,omitempty"`
+	CreatedAt  string  `json:"created_at"`
+	UpdatedAt  string  `json:"updated_at"`
+	FinishedAt *string `json:"finished_at,omitempty"`
+}
+
+func FromImportJob(j *domain.ImportJob) ImportJobResponse {
+	resp := ImportJobResponse{
+		ID:        j.ID,
+		Status:    string(j.Status),
+		Result:    FromImportResult(&j.Result),
+		Error:     j.Error,
+		CreatedAt: j.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: j.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if j.FinishedAt != nil {
+		finishedAt := j.FinishedAt.Format(time.RFC3339)
+		resp.FinishedAt = &finishedAt
+	}
+
+	return resp
+}
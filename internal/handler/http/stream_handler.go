@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/stream"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+type StreamHandler struct {
+	hub      *stream.Hub
+	feed     domain.TaskEventFeed
+	upgrader websocket.Upgrader
+}
+
+func NewStreamHandler(hub *stream.Hub, feed domain.TaskEventFeed) *StreamHandler {
+	return &StreamHandler{hub: hub, feed: feed}
+}
+
+// EventMessage is the body of every streamed event.
+type EventMessage struct {
+	ID    string           `json:"id"`
+	Type  string           `json:"type"`
+	Event domain.TaskEvent `json:"event"`
+}
+
+func toEventMessage(e *domain.SequencedEvent) EventMessage {
+	return EventMessage{
+		ID:    strconv.FormatInt(e.Seq, 10),
+		Type:  string(e.Event.Type),
+		Event: e.Event,
+	}
+}
+
+// SSE godoc
+// @Summary      Stream task events (SSE)
+// @Description  Server-Sent Events stream of task created/updated/deleted events matching the filters. Send Last-Event-ID to resume after a disconnect.
+// @Tags         events
+// @Produce      text/event-stream
+// @Param        Last-Event-ID  header    string  false  "Resume after this event ID"
+// @Param        status         query     string  false  "Task status" Enums(todo,in_progress,done)
+// @Param        assignee       query     string  false  "Only tasks assigned to this user"
+// @Param        watcher        query     string  false  "Only tasks watched by this user"
+// @Success      200  {object}  http.EventMessage
+// @Failure      400  {object}  http.ErrorResponse
+// @Router       /events/stream [get]
+func (h *StreamHandler) SSE(c *gin.Context) {
+	filter, lastID, err := parseStreamRequest(c, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// The stream outlives the server write timeout; heartbeats detect
+	// dead clients instead.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	h.run(c.Request.Context(), filter, lastID, heartbeat.C, func(msg EventMessage) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, data)
+		c.Writer.Flush()
+		return err
+	}, func() error {
+		_, err := fmt.Fprint(c.Writer, ": keep-alive\n\n")
+		c.Writer.Flush()
+		return err
+	})
+}
+
+// WebSocket godoc
+// @Summary      Stream task events (WebSocket)
+// @Description  WebSocket stream of task events as JSON messages. Pass last_event_id to resume after a disconnect.
+// @Tags         events
+// @Param        last_event_id  query     string  false  "Resume after this event ID"
+// @Param        status         query     string  false  "Task status" Enums(todo,in_progress,done)
+// @Param        assignee       query     string  false  "Only tasks assigned to this user"
+// @Param        watcher        query     string  false  "Only tasks watched by this user"
+// @Success      101  {object}  http.EventMessage
+// @Failure      400  {object}  http.ErrorResponse
+// @Router       /events/ws [get]
+func (h *StreamHandler) WebSocket(c *gin.Context) {
+	filter, lastID, err := parseStreamRequest(c, c.Query("last_event_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Hijacked connections keep the deadlines of the server timeouts.
+	_ = conn.NetConn().SetDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// The client never sends data; reading only surfaces the close frame.
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(heartbeatInterval)
+	defer ping.Stop()
+
+	h.run(ctx, filter, lastID, ping.C, func(msg EventMessage) error {
+		return conn.WriteJSON(msg)
+	}, func() error {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second))
+	})
+}
+
+// run replays events after lastID, then forwards live events until the client
+// goes away or falls behind.
+func (h *StreamHandler) run(
+	ctx context.Context,
+	filter domain.TaskFilter,
+	lastID int64,
+	heartbeat <-chan time.Time,
+	send func(EventMessage) error,
+	keepAlive func() error,
+) {
+
+	_ = h.hub.Follow(ctx, h.feed, filter, lastID, heartbeat, func(e *domain.SequencedEvent) error {
+		return send(toEventMessage(e))
+	}, keepAlive)
+}
+
+func parseStreamRequest(c *gin.Context, lastEventID string) (domain.TaskFilter, int64, error) {
+	filter, err := parseFilter(c)
+	if err != nil {
+		return domain.TaskFilter{}, 0, err
+	}
+
+	var lastID int64
+	if lastEventID != "" {
+		lastID, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || lastID < 0 {
+			return domain.TaskFilter{}, 0, errors.New("invalid last event id")
+		}
+	}
+
+	return filter, lastID, nil
+}
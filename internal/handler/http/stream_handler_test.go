@@ -0,0 +1,148 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"graph-task-service/internal/domain"
+	handlerHttp "graph-task-service/internal/handler/http"
+	"graph-task-service/internal/stream"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFeed struct {
+	events []*domain.SequencedEvent
+}
+
+func (f *fakeFeed) Listen(ctx context.Context, handle func(*domain.SequencedEvent)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (f *fakeFeed) Since(ctx context.Context, seq int64, limit int) ([]*domain.SequencedEvent, error) {
+	var out []*domain.SequencedEvent
+	for _, e := range f.events {
+		if e.Seq > seq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func sequenced(seq int64, id string, assignee string) *domain.SequencedEvent {
+	return &domain.SequencedEvent{
+		Seq: seq,
+		Event: domain.TaskEvent{
+			ID:     id,
+			Type:   domain.EventTaskCreated,
+			TaskID: id,
+			Task:   &domain.Task{ID: id, Assignees: []string{assignee}},
+		},
+	}
+}
+
+func setupStreamServer(t *testing.T, feed *fakeFeed) (*stream.Hub, *httptest.Server) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	hub := stream.NewHub(8)
+	handler := handlerHttp.NewStreamHandler(hub, feed)
+
+	r := gin.New()
+	r.GET("/events/stream", handler.SSE)
+	r.GET("/events/ws", handler.WebSocket)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	return hub, srv
+}
+
+// broadcastUntilReceived repeats e until the client sees it, since the
+// handler subscribes to the hub asynchronously after the handshake. The
+// handler drops the repeats because their sequence was already sent.
+func broadcastUntilReceived(hub *stream.Hub, e *domain.SequencedEvent, received <-chan struct{}) {
+	for {
+		hub.Broadcast(e)
+		select {
+		case <-received:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestStreamHandler_SSE_ResumesAndFilters(t *testing.T) {
+	feed := &fakeFeed{events: []*domain.SequencedEvent{
+		sequenced(1, "old", "abo"),
+		sequenced(2, "missed", "abo"),
+		sequenced(3, "other", "someone"),
+	}}
+	hub, srv := setupStreamServer(t, feed)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/events/stream?assignee=abo", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	ids := make(chan string, 4)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if id, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+				ids <- id
+			}
+		}
+	}()
+
+	assert.Equal(t, "2", <-ids)
+
+	received := make(chan struct{})
+	go func() {
+		assert.Equal(t, "4", <-ids)
+		close(received)
+	}()
+	broadcastUntilReceived(hub, sequenced(4, "live", "abo"), received)
+}
+
+func TestStreamHandler_WebSocket(t *testing.T) {
+	hub, srv := setupStreamServer(t, &fakeFeed{})
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/events/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	messages := make(chan handlerHttp.EventMessage, 4)
+	go func() {
+		for {
+			var msg handlerHttp.EventMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			messages <- msg
+		}
+	}()
+
+	received := make(chan struct{})
+	go func() {
+		msg := <-messages
+		assert.Equal(t, "7", msg.ID)
+		assert.Equal(t, "task.created", msg.Type)
+		close(received)
+	}()
+	broadcastUntilReceived(hub, sequenced(7, "live", "abo"), received)
+}
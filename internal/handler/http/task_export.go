@@ -0,0 +1,115 @@
+package http
+
+import (
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/export"
+	"graph-task-service/internal/logging"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export godoc
+// @Summary      Export tasks
+// @Description  Stream the tasks matching the filter as CSV, JSON Lines or an Excel workbook. Without limit every matching task is exported.
+// @Tags         tasks
+// @Produce      text/csv
+// @Produce      application/jsonl
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format       query     string  false  "File format" Enums(csv,jsonl,xlsx) default(csv)
+// @Param        columns      query     string  false  "Comma separated columns: id,title,status,assignees,watchers,created_at,updated_at"
+// @Param        tz           query     string  false  "IANA time zone of timestamps" default(UTC)
+// @Param        status       query     string  false  "Task status" Enums(todo,in_progress,done)
+// @Param        assignee     query     string  false  "Matches tasks that have this user among their assignees"
+// @Param        watcher      query     string  false  "Matches tasks that have this user among their watchers"
+// @Param        participant  query     string  false  "Matches tasks the user is assigned to or watching"
+// @Param        limit        query     int     false  "Limit"
+// @Param        offset       query     int     false  "Offset"  default(0)
+// @Success      200  {file}    file
+// @Failure      400  {object}  http.ErrorResponse
+// @Failure      500  {object}  http.ErrorResponse
+// @Router       /tasks/export [get]
+func (h *TaskHandler) Export(c *gin.Context) {
+	format, err := export.ParseFormat(c.DefaultQuery("format", "csv"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	columns, err := export.ParseColumns(c.Query("columns"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid time zone"})
+		return
+	}
+
+	filter, err := parseExportFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Large exports outlast the server's write timeout.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logging.FromContext(ctx).DebugContext(ctx, "keep write deadline of export", "error", err)
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="tasks.`+string(format)+`"`)
+
+	w, err := export.NewWriter(c.Writer, format, export.Options{Columns: columns, Location: loc})
+	if err == nil {
+		err = h.service.StreamTasks(ctx, filter, w.Write)
+		if err == nil {
+			err = w.Close()
+		}
+	}
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		c.Header("Content-Disposition", "")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The status is sent already; cutting the response short tells the
+	// client the file is incomplete.
+	logging.FromContext(ctx).ErrorContext(ctx, "export tasks", "format", format, "error", err)
+	c.Abort()
+	panic(http.ErrAbortHandler)
+}
+
+// parseExportFilter reads the criteria of parseFilter plus participant. An
+// export has no limit unless one is given.
+func parseExportFilter(c *gin.Context) (domain.TaskFilter, error) {
+	filter, err := parseFilter(c)
+	if err != nil {
+		return domain.TaskFilter{}, err
+	}
+
+	filter.Limit = 0
+	if l := c.Query("limit"); l != "" {
+		filter.Limit, err = strconv.Atoi(l)
+		if err != nil || filter.Limit < 0 {
+			return domain.TaskFilter{}, errors.New("invalid limit")
+		}
+	}
+
+	if p := c.Query("participant"); p != "" {
+		filter.Participant = &p
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,98 @@
+package http_test
+
+import (
+	"errors"
+	"graph-task-service/internal/domain"
+	handlerHttp "graph-task-service/internal/handler/http"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupExportRouter(service *MockTaskService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := handlerHttp.NewTaskHandler(service)
+
+	r := gin.New()
+	r.GET("/tasks/export", handler.Export)
+	r.GET("/tasks/:id", handler.GetByID)
+
+	return r
+}
+
+func TestTaskHandler_Export_CSV(t *testing.T) {
+	service := new(MockTaskService)
+	service.
+		On(
+			"StreamTasks",
+			mock.Anything,
+			mock.MatchedBy(func(f domain.TaskFilter) bool {
+				return f.Limit == 0 && f.Offset == 0 &&
+					f.Status != nil && *f.Status == domain.StatusTodo &&
+					f.Participant != nil && *f.Participant == "alice"
+			}),
+		).
+		Return([]*domain.Task{{
+			ID:        "1",
+			Title:     "export me",
+			Status:    domain.StatusTodo,
+			CreatedAt: time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC),
+		}}, nil)
+
+	rec := get(setupExportRouter(service), "/tasks/export?status=todo&participant=alice&columns=id,title,created_at&tz=America/New_York", nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="tasks.csv"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,title,created_at\n1,export me,2026-03-01T18:00:00-05:00\n", rec.Body.String())
+	service.AssertExpectations(t)
+}
+
+func TestTaskHandler_Export_InvalidParameters(t *testing.T) {
+	r := setupExportRouter(new(MockTaskService))
+
+	for _, query := range []string{
+		"format=pdf",
+		"columns=id,password",
+		"tz=Mars/Olympus",
+		"status=blocked",
+		"limit=-1",
+	} {
+		rec := get(r, "/tasks/export?"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestTaskHandler_Export_FailsBeforeWriting(t *testing.T) {
+	service := new(MockTaskService)
+	service.On("StreamTasks", mock.Anything, mock.Anything).Return(nil, errors.New("database down"))
+
+	rec := get(setupExportRouter(service), "/tasks/export?format=xlsx", nil)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	assert.Contains(t, rec.Body.String(), "database down")
+}
+
+func TestTaskHandler_Export_AbortsStartedResponse(t *testing.T) {
+	// Enough rows to fill the CSV buffer, so the response is under way
+	// when the stream fails.
+	var tasks []*domain.Task
+	for range 200 {
+		tasks = append(tasks, &domain.Task{ID: "1", Title: "a task with a reasonably long title"})
+	}
+
+	service := new(MockTaskService)
+	service.On("StreamTasks", mock.Anything, mock.Anything).Return(tasks, errors.New("connection lost"))
+
+	r := setupExportRouter(service)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		get(r, "/tasks/export", nil)
+	})
+}
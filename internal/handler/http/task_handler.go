@@ -1,11 +1,14 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"graph-task-service/internal/domain"
+	"graph-task-service/internal/middelware"
 	"graph-task-service/internal/service"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,9 +27,13 @@ func NewTaskHandler(s service.TaskService) *TaskHandler {
 // @Tags         tasks
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key header string false "Replays the original response when a request is retried with the same key"
 // @Param        request body http.CreateRequest true "Create task payload"
 // @Success      201 {object} http.TaskResponse "Task created successfully"
 // @Failure      400 {object} http.ErrorResponse "Invalid request body"
+// @Failure      409 {object} http.ErrorResponse "A request with the same idempotency key is in progress"
+// @Header       409 {string} Retry-After "Set when the conflict is a request with the same idempotency key in progress"
+// @Failure      422 {object} http.ErrorResponse "Idempotency key reused with a different body"
 // @Failure      500 {object} http.ErrorResponse "Internal server error"
 // @Router       /tasks [post]
 func (h *TaskHandler) Create(c *gin.Context) {
@@ -36,10 +43,16 @@ func (h *TaskHandler) Create(c *gin.Context) {
 		return
 	}
 
+	assignees := req.Assignees
+	if req.Assignee != nil {
+		assignees = append([]string{*req.Assignee}, assignees...)
+	}
+
 	task, err := h.service.CreateTask(
 		c.Request.Context(),
 		req.Title,
-		req.Assignee,
+		assignees,
+		req.Watchers,
 		req.Status,
 	)
 	if err != nil {
@@ -57,55 +70,112 @@ func (h *TaskHandler) Create(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        status    query     string  false  "Task status" Enums(todo,in_progress,done)
-// @Param        assignee  query     string  false  "Assignee username"
+// @Param        assignee  query     string  false  "Matches tasks that have this user among their assignees"
+// @Param        watcher   query     string  false  "Matches tasks that have this user among their watchers"
 // @Param        limit     query     int     false  "Limit"   default(20)
 // @Param        offset    query     int     false  "Offset"  default(0)
+// @Param        If-None-Match  header  string  false  "ETag of a cached copy"
 // @Success      200  {array}   http.TaskResponse
+// @Header       200  {string}  ETag  "Strong validator of the response body"
+// @Success      304  "The cached copy is current"
 // @Failure      400  {object}  http.ErrorResponse
 // @Failure      500  {object}  http.ErrorResponse
 // @Router       /tasks [get]
 func (h *TaskHandler) List(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-
-	var (
-		status   *domain.TaskStatus
-		assignee *string
-	)
+	filter, err := parseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	if s := c.Query("status"); s != "" {
-		st := domain.TaskStatus(s)
+	h.list(c, filter)
+}
 
-		if !domain.IsValidStatus(st) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
-			return
-		}
-		status = &st
+// ListMine godoc
+// @Summary      List my tasks
+// @Description  List tasks the authenticated user is assigned to or watching
+// @Tags         tasks
+// @Accept       json
+// @Produce      json
+// @Param        X-User    header    string  true   "Authenticated username"
+// @Param        status    query     string  false  "Task status" Enums(todo,in_progress,done)
+// @Param        limit     query     int     false  "Limit"   default(20)
+// @Param        offset    query     int     false  "Offset"  default(0)
+// @Param        If-None-Match  header  string  false  "ETag of a cached copy"
+// @Success      200  {array}   http.TaskResponse
+// @Header       200  {string}  ETag  "Strong validator of the response body"
+// @Success      304  "The cached copy is current"
+// @Failure      400  {object}  http.ErrorResponse
+// @Failure      401  {object}  http.ErrorResponse
+// @Failure      500  {object}  http.ErrorResponse
+// @Router       /me/tasks [get]
+func (h *TaskHandler) ListMine(c *gin.Context) {
+	user, ok := middelware.UserFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return
 	}
 
-	if a := c.Query("assignee"); a != "" {
-		assignee = &a
+	filter, err := parseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
+	filter.Assignee = nil
+	filter.Watcher = nil
+	filter.Participant = &user
 
-	filter := domain.TaskFilter{
-		Status:   status,
-		Assignee: assignee,
-		Limit:    limit,
-		Offset:   offset,
-	}
+	h.list(c, filter)
+}
 
+func (h *TaskHandler) list(c *gin.Context, filter domain.TaskFilter) {
 	tasks, err := h.service.ListTasks(c.Request.Context(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	var lastModified time.Time
+
 	resp := make([]TaskResponse, 0, len(tasks))
 	for _, t := range tasks {
 		resp = append(resp, FromDomain(t))
+		if t.UpdatedAt.After(lastModified) {
+			lastModified = t.UpdatedAt
+		}
 	}
 
-	c.JSON(http.StatusOK, resp)
+	writeConditional(c, resp, lastModified, false)
+}
+
+// parseFilter builds a domain.TaskFilter from the query string of c.
+func parseFilter(c *gin.Context) (domain.TaskFilter, error) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	filter := domain.TaskFilter{
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	if s := c.Query("status"); s != "" {
+		st := domain.TaskStatus(s)
+
+		if !domain.IsValidStatus(st) {
+			return domain.TaskFilter{}, errors.New("invalid status")
+		}
+		filter.Status = &st
+	}
+
+	if a := c.Query("assignee"); a != "" {
+		filter.Assignee = &a
+	}
+
+	if w := c.Query("watcher"); w != "" {
+		filter.Watcher = &w
+	}
+
+	return filter, nil
 }
 
 // GetByID godoc
@@ -114,8 +184,13 @@ func (h *TaskHandler) List(c *gin.Context) {
 // @Tags         tasks
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Task ID"
+// @Param        id                 path      string  true   "Task ID"
+// @Param        If-None-Match      header    string  false  "ETag of a cached copy"
+// @Param        If-Modified-Since  header    string  false  "Last-Modified of a cached copy"
 // @Success      200  {object}  http.TaskResponse
+// @Header       200  {string}  ETag           "Strong validator of the response body"
+// @Header       200  {string}  Last-Modified  "When the task was last updated"
+// @Success      304  "The cached copy is current"
 // @Failure      400  {object}  http.ErrorResponse
 // @Failure      404  {object}  http.ErrorResponse
 // @Failure      500  {object}  http.ErrorResponse
@@ -138,7 +213,7 @@ func (h *TaskHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, FromDomain(task))
+	writeConditional(c, FromDomain(task), task.UpdatedAt, true)
 }
 
 // UpdateStatus godoc
@@ -217,3 +292,113 @@ func (h *TaskHandler) Delete(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// AddAssignee godoc
+// @Summary      Add assignee
+// @Description  Add a user to the assignees of a task
+// @Tags         tasks
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string            true  "Task ID"
+// @Param        request  body      http.UserRequest  true  "User to assign"
+// @Success      200      {object}  http.TaskResponse
+// @Failure      400      {object}  http.ErrorResponse
+// @Failure      404      {object}  http.ErrorResponse
+// @Failure      500      {object}  http.ErrorResponse
+// @Router       /tasks/{id}/assignees [post]
+func (h *TaskHandler) AddAssignee(c *gin.Context) {
+	h.addUser(c, h.service.AddAssignee)
+}
+
+// RemoveAssignee godoc
+// @Summary      Remove assignee
+// @Description  Remove a user from the assignees of a task
+// @Tags         tasks
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string  true  "Task ID"
+// @Param        user  path      string  true  "Username"
+// @Success      200   {object}  http.TaskResponse
+// @Failure      400   {object}  http.ErrorResponse
+// @Failure      404   {object}  http.ErrorResponse
+// @Failure      500   {object}  http.ErrorResponse
+// @Router       /tasks/{id}/assignees/{user} [delete]
+func (h *TaskHandler) RemoveAssignee(c *gin.Context) {
+	h.removeUser(c, h.service.RemoveAssignee)
+}
+
+// AddWatcher godoc
+// @Summary      Add watcher
+// @Description  Add a user to the watchers of a task
+// @Tags         tasks
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string            true  "Task ID"
+// @Param        request  body      http.UserRequest  true  "User to add as watcher"
+// @Success      200      {object}  http.TaskResponse
+// @Failure      400      {object}  http.ErrorResponse
+// @Failure      404      {object}  http.ErrorResponse
+// @Failure      500      {object}  http.ErrorResponse
+// @Router       /tasks/{id}/watchers [post]
+func (h *TaskHandler) AddWatcher(c *gin.Context) {
+	h.addUser(c, h.service.AddWatcher)
+}
+
+// RemoveWatcher godoc
+// @Summary      Remove watcher
+// @Description  Remove a user from the watchers of a task
+// @Tags         tasks
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string  true  "Task ID"
+// @Param        user  path      string  true  "Username"
+// @Success      200   {object}  http.TaskResponse
+// @Failure      400   {object}  http.ErrorResponse
+// @Failure      404   {object}  http.ErrorResponse
+// @Failure      500   {object}  http.ErrorResponse
+// @Router       /tasks/{id}/watchers/{user} [delete]
+func (h *TaskHandler) RemoveWatcher(c *gin.Context) {
+	h.removeUser(c, h.service.RemoveWatcher)
+}
+
+type userUpdateFunc func(ctx context.Context, id string, user string) (*domain.Task, error)
+
+func (h *TaskHandler) addUser(c *gin.Context, update userUpdateFunc) {
+	var req UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	h.updateUser(c, req.User, update)
+}
+
+func (h *TaskHandler) removeUser(c *gin.Context, update userUpdateFunc) {
+	h.updateUser(c, c.Param("user"), update)
+}
+
+func (h *TaskHandler) updateUser(c *gin.Context, user string, update userUpdateFunc) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+
+	task, err := update(c.Request.Context(), id, user)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyUser) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors.Is(err, service.ErrTaskNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, FromDomain(task))
+}
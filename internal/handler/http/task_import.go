@@ -0,0 +1,165 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"graph-task-service/internal/importer"
+	"graph-task-service/internal/middelware"
+	"graph-task-service/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImportHandler struct {
+	service service.ImportService
+}
+
+func NewImportHandler(s service.ImportService) *ImportHandler {
+	return &ImportHandler{service: s}
+}
+
+// Import godoc
+// @Summary      Import tasks
+// @Description  Create tasks from a CSV file with a header row or from JSON Lines. Every row is validated like POST /tasks; when one is invalid no task is created and the first rejected rows are listed. Users are arrays in JSON or comma separated text. With async the import runs in the background; poll the job at the Location header.
+// @Tags         tasks
+// @Accept       text/csv
+// @Accept       application/jsonl
+// @Produce      json
+// @Param        file     body      string  true   "CSV or JSON Lines file"
+// @Param        format   query     string  false  "File format, by default taken from Content-Type" Enums(csv,jsonl)
+// @Param        mapping  query     string  false  "Columns or keys of the fields when they differ from the field names, e.g. title:Summary,assignees:Owner"
+// @Param        dry_run  query     bool    false  "Only validate the rows"  default(false)
+// @Param        async    query     bool    false  "Run the import in the background"  default(false)
+// @Success      200  {object}  http.ImportResultResponse  "Dry run"
+// @Success      201  {object}  http.ImportResultResponse  "Tasks created"
+// @Success      202  {object}  http.ImportJobResponse     "Import started"
+// @Failure      400  {object}  http.ErrorResponse
+// @Failure      413  {object}  http.ErrorResponse
+// @Failure      422  {object}  http.ImportResultResponse  "Invalid rows, no task created"
+// @Failure      500  {object}  http.ErrorResponse
+// @Failure      503  {object}  http.ErrorResponse  "Too many imports are waiting"
+// @Header       503  {string}  Retry-After  "Seconds to wait before trying again"
+// @Router       /tasks/import [post]
+func (h *ImportHandler) Import(c *gin.Context) {
+	format, ok := importer.FormatOf(c.ContentType())
+	if f := c.Query("format"); f != "" || !ok {
+		var err error
+		format, err = importer.ParseFormat(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "set format to csv or jsonl, or send a text/csv or application/jsonl body"})
+			return
+		}
+	}
+
+	mapping, err := importer.ParseMapping(c.Query("mapping"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun, err := queryBool(c, "dry_run")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+		return
+	}
+
+	async, err := queryBool(c, "async")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid async"})
+		return
+	}
+
+	rows, err := importer.Read(c.Request.Body, format, mapping)
+	if maxErr := (*http.MaxBytesError)(nil); errors.As(err, &maxErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the file has no rows"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, _ := middelware.UserFromContext(ctx)
+
+	if async {
+		job, err := h.service.StartImport(ctx, user, rows, dryRun)
+		if errors.Is(err, service.ErrImportQueueFull) {
+			c.Header("Retry-After", "30")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Location", "/tasks/import/jobs/"+job.ID)
+		c.JSON(http.StatusAccepted, FromImportJob(job))
+		return
+	}
+
+	result, err := h.service.ImportTasks(ctx, rows, dryRun)
+	if err != nil && result != nil && result.Created > 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("%v; the first %d tasks were created", err, result.Created),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	switch {
+	case dryRun:
+		status = http.StatusOK
+	case result.Invalid > 0:
+		status = http.StatusUnprocessableEntity
+	}
+
+	c.JSON(status, FromImportResult(result))
+}
+
+// GetJob godoc
+// @Summary      Get import job
+// @Description  Report the progress of an import started with async. Only the user who started a job can read it. Jobs are kept in the database; with the memory or sqlite driver only the instance that runs a job knows it.
+// @Tags         tasks
+// @Produce      json
+// @Param        id   path      string  true  "Import job ID"
+// @Success      200  {object}  http.ImportJobResponse
+// @Failure      404  {object}  http.ErrorResponse
+// @Failure      500  {object}  http.ErrorResponse
+// @Router       /tasks/import/jobs/{id} [get]
+func (h *ImportHandler) GetJob(c *gin.Context) {
+	user, _ := middelware.UserFromContext(c.Request.Context())
+
+	job, err := h.service.GetImportJob(c.Request.Context(), user, c.Param("id"))
+	if errors.Is(err, service.ErrImportJobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, FromImportJob(job))
+}
+
+// queryBool reads an optional boolean query parameter.
+func queryBool(c *gin.Context, name string) (bool, error) {
+	v := c.Query(name)
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
@@ -0,0 +1,225 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"graph-task-service/internal/domain"
+	handlerHttp "graph-task-service/internal/handler/http"
+	"graph-task-service/internal/importer"
+	"graph-task-service/internal/middelware"
+	"graph-task-service/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockImportService struct {
+	mock.Mock
+}
+
+func (m *MockImportService) ImportTasks(ctx context.Context, rows []importer.Row, dryRun bool) (*domain.ImportResult, error) {
+	args := m.Called(ctx, rows, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ImportResult), args.Error(1)
+}
+
+func (m *MockImportService) StartImport(ctx context.Context, user string, rows []importer.Row, dryRun bool) (*domain.ImportJob, error) {
+	args := m.Called(ctx, user, rows, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ImportJob), args.Error(1)
+}
+
+func (m *MockImportService) GetImportJob(ctx context.Context, user string, id string) (*domain.ImportJob, error) {
+	args := m.Called(ctx, user, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ImportJob), args.Error(1)
+}
+
+func (m *MockImportService) FailAbandoned(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockImportService) Close(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func setupImportRouter(service *MockImportService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := handlerHttp.NewImportHandler(service)
+
+	r := gin.New()
+	r.Use(middelware.BodyLimit(1 << 10))
+	r.POST("/tasks/import", handler.Import)
+	r.GET("/tasks/import/jobs/:id", handler.GetJob)
+	r.GET("/tasks/:id", func(c *gin.Context) { c.String(http.StatusOK, "task "+c.Param("id")) })
+
+	return r
+}
+
+func postImport(r *gin.Engine, query string, contentType string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/tasks/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestImportHandler_Import_CSV(t *testing.T) {
+	service := new(MockImportService)
+	service.
+		On("ImportTasks", mock.Anything, []importer.Row{
+			{Line: 2, Title: "Fix login", Status: "done", Assignees: []string{"alice", "bob"}},
+		}, false).
+		Return(&domain.ImportResult{Rows: 1, Created: 1}, nil)
+
+	rec := postImport(
+		setupImportRouter(service),
+		"?mapping=title:Summary",
+		"text/csv",
+		"Summary,status,assignees\nFix login,done,\"alice, bob\"\n",
+	)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"dry_run":false,"rows":1,"invalid":0,"created":1,"errors":[]}`, rec.Body.String())
+	service.AssertExpectations(t)
+}
+
+func TestImportHandler_Import_DryRunAndInvalidRows(t *testing.T) {
+	result := &domain.ImportResult{
+		Rows:    2,
+		Invalid: 1,
+		Errors:  []domain.ImportRowError{{Line: 2, Message: "title cannot be empty"}},
+	}
+	body := "{\"title\":\"\"}\n{\"title\":\"ok\"}\n"
+
+	service := new(MockImportService)
+	service.On("ImportTasks", mock.Anything, mock.Anything, true).Return(result, nil).Once()
+	service.On("ImportTasks", mock.Anything, mock.Anything, false).Return(result, nil).Once()
+
+	r := setupImportRouter(service)
+
+	rec := postImport(r, "?format=jsonl&dry_run=true", "application/octet-stream", body)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = postImport(r, "", "application/jsonl", body)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{
+		"dry_run": false,
+		"rows": 2,
+		"invalid": 1,
+		"created": 0,
+		"errors": [{"line": 2, "error": "title cannot be empty"}]
+	}`, rec.Body.String())
+
+	service.AssertExpectations(t)
+}
+
+func TestImportHandler_Import_Async(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	service := new(MockImportService)
+	service.
+		On("StartImport", mock.Anything, "", mock.MatchedBy(func(rows []importer.Row) bool { return len(rows) == 2 }), false).
+		Return(&domain.ImportJob{
+			ID:        "job-1",
+			Status:    domain.ImportPending,
+			Result:    domain.ImportResult{Rows: 2},
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}, nil)
+
+	rec := postImport(setupImportRouter(service), "?async=1", "text/csv", "title\na\nb\n")
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "/tasks/import/jobs/job-1", rec.Header().Get("Location"))
+
+	var job handlerHttp.ImportJobResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+	assert.Equal(t, "pending", job.Status)
+	assert.Equal(t, 2, job.Result.Rows)
+	service.AssertExpectations(t)
+}
+
+func TestImportHandler_Import_QueueFull(t *testing.T) {
+	imports := new(MockImportService)
+	imports.On("StartImport", mock.Anything, "", mock.Anything, false).Return(nil, service.ErrImportQueueFull)
+
+	rec := postImport(setupImportRouter(imports), "?async=1", "text/csv", "title\na\n")
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+}
+
+func TestImportHandler_Import_BadRequests(t *testing.T) {
+	r := setupImportRouter(new(MockImportService))
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"unknown format", "", "application/octet-stream", "title\na\n", http.StatusBadRequest},
+		{"invalid mapping", "?mapping=owner:Owner", "text/csv", "title\na\n", http.StatusBadRequest},
+		{"invalid dry_run", "?dry_run=maybe", "text/csv", "title\na\n", http.StatusBadRequest},
+		{"missing title column", "", "text/csv", "name\na\n", http.StatusBadRequest},
+		{"no rows", "", "text/csv", "title\n", http.StatusBadRequest},
+		{"too large", "", "text/csv", "title\n" + strings.Repeat("a\n", 1<<10), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postImport(r, tt.query, tt.contentType, tt.body)
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestImportHandler_GetJob(t *testing.T) {
+	finishedAt := time.Date(2026, 3, 1, 12, 5, 0, 0, time.UTC)
+
+	imports := new(MockImportService)
+	imports.On("GetImportJob", mock.Anything, "", "job-1").Return(&domain.ImportJob{
+		ID:         "job-1",
+		Status:     domain.ImportSucceeded,
+		Result:     domain.ImportResult{Rows: 2, Created: 2},
+		FinishedAt: &finishedAt,
+	}, nil)
+	imports.On("GetImportJob", mock.Anything, "", "missing").Return(nil, service.ErrImportJobNotFound)
+
+	r := setupImportRouter(imports)
+
+	rec := get(r, "/tasks/import/jobs/job-1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var job handlerHttp.ImportJobResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+	assert.Equal(t, "succeeded", job.Status)
+	assert.Equal(t, 2, job.Result.Created)
+	require.NotNil(t, job.FinishedAt)
+	assert.Equal(t, "2026-03-01T12:05:00Z", *job.FinishedAt)
+
+	rec = get(r, "/tasks/import/jobs/missing", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// The import routes leave task ids alone.
+	rec = get(r, "/tasks/import", nil)
+	assert.Equal(t, "task import", rec.Body.String())
+}
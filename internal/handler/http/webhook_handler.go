@@ -0,0 +1,201 @@
+package http
+
+import (
+	"errors"
+	"graph-task-service/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	service service.WebhookService
+}
+
+func NewWebhookHandler(s service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: s}
+}
+
+// Create godoc
+// @Summary      Create a webhook
+// @Description  Subscribe a URL to task lifecycle events. Deliveries are signed with HMAC-SHA256 of the X-Webhook-Timestamp header, a dot and the body in the X-Webhook-Signature header; reject deliveries whose timestamp is old to stop replays.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request body http.CreateWebhookRequest true "Create webhook payload"
+// @Success      201 {object} http.WebhookResponse "Webhook created, including its secret"
+// @Failure      400 {object} http.ErrorResponse "Invalid request body"
+// @Failure      500 {object} http.ErrorResponse "Internal server error"
+// @Router       /webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(
+		c.Request.Context(),
+		req.URL,
+		req.Events,
+		req.Secret,
+	)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	resp := FromWebhook(webhook)
+	resp.Secret = webhook.Secret
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// List godoc
+// @Summary      List webhooks
+// @Description  List all webhook subscriptions
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   http.WebhookResponse
+// @Failure      500  {object}  http.ErrorResponse
+// @Router       /webhooks [get]
+func (h *WebhookHandler) List(c *gin.Context) {
+	webhooks, err := h.service.ListWebhooks(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]WebhookResponse, 0, len(webhooks))
+	for _, w := range webhooks {
+		resp = append(resp, FromWebhook(w))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetByID godoc
+// @Summary      Get webhook by ID
+// @Description  Retrieve a webhook subscription by its ID
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Webhook ID"
+// @Success      200  {object}  http.WebhookResponse
+// @Failure      404  {object}  http.ErrorResponse
+// @Failure      500  {object}  http.ErrorResponse
+// @Router       /webhooks/{id} [get]
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	webhook, err := h.service.GetWebhook(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, FromWebhook(webhook))
+}
+
+// Update godoc
+// @Summary      Update webhook
+// @Description  Replace the URL, event filter and active flag of a webhook
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                      true  "Webhook ID"
+// @Param        request  body      http.UpdateWebhookRequest   true  "Update webhook payload"
+// @Success      200      {object}  http.WebhookResponse
+// @Failure      400      {object}  http.ErrorResponse
+// @Failure      404      {object}  http.ErrorResponse
+// @Failure      500      {object}  http.ErrorResponse
+// @Router       /webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var req UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(
+		c.Request.Context(),
+		c.Param("id"),
+		req.URL,
+		req.Events,
+		*req.Active,
+	)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, FromWebhook(webhook))
+}
+
+// Delete godoc
+// @Summary      Delete webhook
+// @Description  Delete a webhook subscription and its delivery log
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path  string  true  "Webhook ID"
+// @Success      204  "No Content"
+// @Failure      404  {object}  http.ErrorResponse
+// @Failure      500  {object}  http.ErrorResponse
+// @Router       /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	if err := h.service.DeleteWebhook(c.Request.Context(), c.Param("id")); err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries godoc
+// @Summary      List webhook deliveries
+// @Description  Delivery log of a webhook, newest first
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string  true   "Webhook ID"
+// @Param        limit   query     int     false  "Limit"   default(20)
+// @Param        offset  query     int     false  "Offset"  default(0)
+// @Success      200  {array}   http.WebhookDeliveryResponse
+// @Failure      404  {object}  http.ErrorResponse
+// @Failure      500  {object}  http.ErrorResponse
+// @Router       /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	deliveries, err := h.service.ListDeliveries(
+		c.Request.Context(),
+		c.Param("id"),
+		limit,
+		offset,
+	)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	resp := make([]WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, FromWebhookDelivery(d))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func writeWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+	case errors.Is(err, service.ErrInvalidURL),
+		errors.Is(err, service.ErrInvalidEventType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,54 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/health"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	r := health.NewRegistry(time.Second)
+	r.Register("ok", func(ctx context.Context) error { return nil })
+
+	report := r.Check(context.Background())
+	assert.Equal(t, health.StatusPass, report.Status)
+	assert.Equal(t, health.StatusPass, report.Checks["ok"].Status)
+
+	r.Register("broken", func(ctx context.Context) error { return errors.New("down") })
+
+	report = r.Check(context.Background())
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.StatusPass, report.Checks["ok"].Status)
+	assert.Equal(t, "down", report.Checks["broken"].Error)
+}
+
+func TestRegistry_Timeout(t *testing.T) {
+	r := health.NewRegistry(20 * time.Millisecond)
+	r.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	report := r.Check(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestRegistry_Shutdown(t *testing.T) {
+	r := health.NewRegistry(time.Second)
+	r.Register("ok", func(ctx context.Context) error { return nil })
+
+	r.Shutdown()
+
+	report := r.Check(context.Background())
+	assert.True(t, r.ShuttingDown())
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.ErrShuttingDown.Error(), report.Checks["shutdown"].Error)
+}
@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"io"
+	"strings"
+)
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubIssue struct {
+	ID          json.Number     `json:"id"`
+	Number      int             `json:"number"`
+	URL         string          `json:"url"`
+	HTMLURL     string          `json:"html_url"`
+	Title       string          `json:"title"`
+	State       string          `json:"state"`
+	Assignee    *githubUser     `json:"assignee"`
+	Assignees   []githubUser    `json:"assignees"`
+	User        *githubUser     `json:"user"`
+	Author      *githubUser     `json:"author"`
+	PullRequest json.RawMessage `json:"pull_request"`
+	Labels      []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+// externalID prefers the issue URL, which unlike the number is unique
+// across repositories. gh issue list --json has no html_url, and its url is
+// the web URL.
+func (i githubIssue) externalID() string {
+	switch {
+	case i.HTMLURL != "":
+		return i.HTMLURL
+	case i.URL != "":
+		return i.URL
+	default:
+		return i.ID.String()
+	}
+}
+
+// readGitHub reads an array of issues from the REST API or from
+// gh issue list --json. Pull requests, which the REST API lists as issues,
+// are skipped. Closed issues are done; open ones are in progress when a
+// label says so and to do otherwise. The statuses "open" and "closed" and
+// any label in statuses override that.
+func readGitHub(r io.Reader, statuses StatusMap) ([]Row, error) {
+	var issues []githubIssue
+	if err := decodeExport(r, TrackerGitHub, &issues); err != nil {
+		return nil, err
+	}
+
+	rows := make([]Row, 0, len(issues))
+
+	for i, issue := range issues {
+		if len(issue.PullRequest) > 0 && string(issue.PullRequest) != "null" {
+			continue
+		}
+
+		row := Row{
+			Line:       i + 1,
+			ExternalID: issue.externalID(),
+			Title:      issue.Title,
+			Status:     string(githubStatus(issue, statuses)),
+		}
+
+		if row.ExternalID == "" {
+			row.Err = fmt.Errorf("issue #%d has no url or id", issue.Number)
+		}
+
+		seen := map[string]bool{}
+		for _, u := range append(issue.Assignees, deref(issue.Assignee)...) {
+			if u.Login != "" && !seen[u.Login] {
+				seen[u.Login] = true
+				row.Assignees = append(row.Assignees, u.Login)
+			}
+		}
+
+		for _, u := range append(deref(issue.User), deref(issue.Author)...) {
+			if u.Login != "" {
+				row.Watchers = []string{u.Login}
+				break
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func githubStatus(issue githubIssue, statuses StatusMap) domain.TaskStatus {
+	// The REST API says "closed", gh says "CLOSED".
+	if strings.EqualFold(issue.State, "closed") {
+		if status, ok := statuses.lookup("closed"); ok {
+			return status
+		}
+		return domain.StatusDone
+	}
+
+	for _, l := range issue.Labels {
+		if status, ok := statuses.lookup(l.Name); ok {
+			return status
+		}
+	}
+	for _, l := range issue.Labels {
+		if containsAny(l.Name, "in progress", "in-progress", "doing", "wip") {
+			return domain.StatusInProgress
+		}
+	}
+
+	if status, ok := statuses.lookup("open"); ok {
+		return status
+	}
+	return domain.StatusTodo
+}
+
+func deref(u *githubUser) []githubUser {
+	if u == nil {
+		return nil
+	}
+	return []githubUser{*u}
+}
@@ -0,0 +1,139 @@
+// Package importer reads tasks from CSV and JSON Lines files and from the
+// JSON exports of other trackers. It only parses rows; the task service
+// validates them like any other task.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"slices"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatJSONL:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, use csv or jsonl", s)
+	}
+}
+
+// FormatOf returns the format of a Content-Type header, if it names one.
+func FormatOf(contentType string) (Format, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, true
+	case "application/jsonl", "application/x-ndjson", "application/x-jsonlines":
+		return FormatJSONL, true
+	default:
+		return "", false
+	}
+}
+
+// Field is a task field that can be imported, named like in the JSON API.
+type Field string
+
+const (
+	FieldTitle     Field = "title"
+	FieldStatus    Field = "status"
+	FieldAssignees Field = "assignees"
+	FieldWatchers  Field = "watchers"
+)
+
+var Fields = []Field{FieldTitle, FieldStatus, FieldAssignees, FieldWatchers}
+
+// Mapping names the CSV column or JSON key each field is read from. A field
+// without an entry is read from the column named like the field, so files
+// written by the export import as they are.
+type Mapping map[Field]string
+
+// ParseMapping parses a comma separated list of field:column pairs, e.g.
+// "title:Summary,assignees:Owner".
+func ParseMapping(s string) (Mapping, error) {
+	mapping := Mapping{}
+	if strings.TrimSpace(s) == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		name, column, ok := strings.Cut(pair, ":")
+		field := Field(strings.TrimSpace(name))
+		column = strings.TrimSpace(column)
+
+		if !ok || column == "" {
+			return nil, fmt.Errorf("invalid mapping %q, use field:column", pair)
+		}
+		if !slices.Contains(Fields, field) {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		if _, dup := mapping[field]; dup {
+			return nil, fmt.Errorf("field %q mapped twice", name)
+		}
+
+		mapping[field] = column
+	}
+
+	return mapping, nil
+}
+
+func (m Mapping) source(field Field) string {
+	if column, ok := m[field]; ok {
+		return column
+	}
+	return string(field)
+}
+
+// Row is a task read from a file.
+type Row struct {
+	// Line is where the row starts in the file, counting from 1. Rows of
+	// tracker exports count items instead.
+	Line int
+	// ExternalID is the ID of the item in the tracker it was exported from.
+	ExternalID string
+	Title      string
+	// Status is empty when the row has none, so the default applies.
+	Status    string
+	Assignees []string
+	Watchers  []string
+	// Err is why the row could not be read; the other fields are unset.
+	Err error
+}
+
+// Read parses every row of r. A malformed row is returned with Err set and
+// does not stop the others; Read fails only when the file as a whole is
+// unusable, such as a CSV header without the title column.
+func Read(r io.Reader, format Format, mapping Mapping) ([]Row, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r, mapping)
+	case FormatJSONL:
+		return readJSONL(r, mapping)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// splitUsers reads a list of users written as one text, as the export does.
+func splitUsers(s string) []string {
+	var users []string
+	for _, u := range strings.Split(s, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			users = append(users, u)
+		}
+	}
+	return users
+}
@@ -0,0 +1,121 @@
+package importer_test
+
+import (
+	"graph-task-service/internal/importer"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead_CSV(t *testing.T) {
+	// The header of the export, with a byte order mark and a broken row.
+	file := "\ufeffid,title,status,assignees,watchers,created_at\n" +
+		`1,"quoted ""title"", with comma",todo,"alice, bob",,2026-03-01T23:30:00Z` + "\n" +
+		"2,plain,,,carol\n" +
+		`3,"bad "quote,done,,,` + "\n" +
+		"4,short\n"
+
+	rows, err := importer.Read(strings.NewReader(file), importer.FormatCSV, nil)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, importer.Row{
+		Line:      2,
+		Title:     `quoted "title", with comma`,
+		Status:    "todo",
+		Assignees: []string{"alice", "bob"},
+	}, rows[0])
+	assert.Equal(t, importer.Row{Line: 3, Title: "plain", Watchers: []string{"carol"}}, rows[1])
+	assert.Equal(t, 4, rows[2].Line)
+	assert.Error(t, rows[2].Err)
+	assert.Equal(t, importer.Row{Line: 5, Title: "short"}, rows[3])
+}
+
+func TestRead_CSVUnescapesFormulas(t *testing.T) {
+	file := "title,assignees\n" +
+		`"'=SUM(A1:A2)","'@alice, -bob"` + "\n" +
+		"'quoted',\n"
+
+	rows, err := importer.Read(strings.NewReader(file), importer.FormatCSV, nil)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, "=SUM(A1:A2)", rows[0].Title)
+	assert.Equal(t, []string{"@alice", "-bob"}, rows[0].Assignees)
+	assert.Equal(t, "'quoted'", rows[1].Title)
+}
+
+func TestRead_CSVMapping(t *testing.T) {
+	file := "Summary,State,Owner\nFix login,done,dave\n"
+
+	mapping, err := importer.ParseMapping("title:Summary, status:State,assignees:Owner")
+	require.NoError(t, err)
+
+	rows, err := importer.Read(strings.NewReader(file), importer.FormatCSV, mapping)
+	require.NoError(t, err)
+	assert.Equal(t, []importer.Row{{Line: 2, Title: "Fix login", Status: "done", Assignees: []string{"dave"}}}, rows)
+}
+
+func TestRead_CSVMissingColumns(t *testing.T) {
+	_, err := importer.Read(strings.NewReader("name,state\nx,todo\n"), importer.FormatCSV, nil)
+	assert.ErrorContains(t, err, `column "title"`)
+
+	_, err = importer.Read(strings.NewReader("title\nx\n"), importer.FormatCSV, importer.Mapping{importer.FieldStatus: "State"})
+	assert.ErrorContains(t, err, `column "State"`)
+
+	_, err = importer.Read(strings.NewReader(""), importer.FormatCSV, nil)
+	assert.ErrorContains(t, err, "empty")
+}
+
+func TestRead_JSONL(t *testing.T) {
+	file := `{"title":"a","status":"done","assignees":["alice","bob"],"watchers":"carol, dave"}
+
+{"title":"b","status":null}
+{"title":1}
+not json
+{"name":"mapped","watchers":{"x":1}}`
+
+	rows, err := importer.Read(strings.NewReader(file), importer.FormatJSONL, importer.Mapping{importer.FieldWatchers: "followers"})
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+
+	assert.Equal(t, importer.Row{
+		Line:      1,
+		Title:     "a",
+		Status:    "done",
+		Assignees: []string{"alice", "bob"},
+	}, rows[0], "watchers are read from the mapped key only")
+	assert.Equal(t, importer.Row{Line: 3, Title: "b"}, rows[1])
+
+	assert.Equal(t, 4, rows[2].Line)
+	assert.ErrorContains(t, rows[2].Err, "title must be a string")
+	assert.Equal(t, 5, rows[3].Line)
+	assert.ErrorContains(t, rows[3].Err, "invalid JSON")
+	assert.Equal(t, importer.Row{Line: 6}, rows[4], "unmapped keys are ignored")
+}
+
+func TestParseMapping(t *testing.T) {
+	for _, s := range []string{"title", "title:", "owner:Owner", "title:a,title:b"} {
+		_, err := importer.ParseMapping(s)
+		assert.Error(t, err, s)
+	}
+
+	mapping, err := importer.ParseMapping("")
+	require.NoError(t, err)
+	assert.Empty(t, mapping)
+}
+
+func TestFormatOf(t *testing.T) {
+	format, ok := importer.FormatOf("text/csv; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, importer.FormatCSV, format)
+
+	format, ok = importer.FormatOf("application/x-ndjson")
+	assert.True(t, ok)
+	assert.Equal(t, importer.FormatJSONL, format)
+
+	_, ok = importer.FormatOf("application/octet-stream")
+	assert.False(t, ok)
+}
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"io"
+)
+
+type jiraUser struct {
+	AccountID    string `json:"accountId"`
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+// login names a Jira user: Jira Cloud hides the user name, and the email
+// address is hidden by some privacy settings.
+func (u *jiraUser) login() string {
+	switch {
+	case u == nil:
+		return ""
+	case u.EmailAddress != "":
+		return u.EmailAddress
+	case u.Name != "":
+		return u.Name
+	default:
+		return u.AccountID
+	}
+}
+
+type jiraIssue struct {
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Fields struct {
+		Summary string `json:"summary"`
+		Status  *struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+		Assignee *jiraUser `json:"assignee"`
+		Reporter *jiraUser `json:"reporter"`
+	} `json:"fields"`
+}
+
+// readJira reads the issues of a Jira search result, {"issues": [...]}, or
+// a plain array of issues. The assignee is the only assignee and the
+// reporter, whom Jira makes a watcher, the watcher. Statuses without an
+// entry in statuses follow their status category.
+func readJira(r io.Reader, statuses StatusMap) ([]Row, error) {
+	var raw json.RawMessage
+	if err := decodeExport(r, TrackerJira, &raw); err != nil {
+		return nil, err
+	}
+
+	var issues []jiraIssue
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		if err := json.Unmarshal(raw, &issues); err != nil {
+			return nil, fmt.Errorf("invalid jira export: %w", err)
+		}
+	} else {
+		var result struct {
+			Issues []jiraIssue `json:"issues"`
+		}
+		if err := json.Unmarshal(raw, &result); err != nil {
+			return nil, fmt.Errorf("invalid jira export: %w", err)
+		}
+		issues = result.Issues
+	}
+
+	rows := make([]Row, 0, len(issues))
+
+	for i, issue := range issues {
+		row := Row{
+			Line:       i + 1,
+			ExternalID: issue.ID,
+			Title:      issue.Fields.Summary,
+		}
+
+		if issue.ID == "" {
+			row.Err = fmt.Errorf("issue %s has no id", issue.Key)
+		} else if status, err := jiraStatus(issue, statuses); err != nil {
+			row.Err = err
+		} else {
+			row.Status = string(status)
+		}
+
+		if login := issue.Fields.Assignee.login(); login != "" {
+			row.Assignees = []string{login}
+		}
+		if login := issue.Fields.Reporter.login(); login != "" {
+			row.Watchers = []string{login}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func jiraStatus(issue jiraIssue, statuses StatusMap) (domain.TaskStatus, error) {
+	s := issue.Fields.Status
+	if s == nil {
+		return domain.StatusTodo, nil
+	}
+
+	if status, ok := statuses.lookup(s.Name); ok {
+		return status, nil
+	}
+
+	switch s.StatusCategory.Key {
+	case "new":
+		return domain.StatusTodo, nil
+	case "indeterminate":
+		return domain.StatusInProgress, nil
+	case "done":
+		return domain.StatusDone, nil
+	default:
+		return "", fmt.Errorf("issue %s: unknown status %q, map it to a task status", issue.Key, s.Name)
+	}
+}
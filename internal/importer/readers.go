@@ -0,0 +1,181 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readCSV expects a header row naming the columns. Columns that no field
+// is read from are ignored, so exported files import as they are.
+func readCSV(r io.Reader, mapping Mapping) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	// Spreadsheet programs start UTF-8 files with a byte order mark.
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	index := make(map[Field]int, len(Fields))
+	for _, field := range Fields {
+		i, ok := columns[mapping.source(field)]
+		if !ok {
+			_, mapped := mapping[field]
+			if field == FieldTitle || mapped {
+				return nil, fmt.Errorf("column %q of %s not found in header", mapping.source(field), field)
+			}
+			continue
+		}
+		index[field] = i
+	}
+
+	cell := func(record []string, field Field) string {
+		i, ok := index[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return unescapeFormula(record[i])
+	}
+
+	var rows []Row
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		if parseErr := (*csv.ParseError)(nil); errors.As(err, &parseErr) {
+			rows = append(rows, Row{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		rows = append(rows, Row{
+			Line:      line,
+			Title:     cell(record, FieldTitle),
+			Status:    strings.TrimSpace(cell(record, FieldStatus)),
+			Assignees: splitUsers(cell(record, FieldAssignees)),
+			Watchers:  splitUsers(cell(record, FieldWatchers)),
+		})
+	}
+}
+
+// unescapeFormula drops the quote the export puts before text that
+// spreadsheets would evaluate as a formula.
+func unescapeFormula(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+// readJSONL reads one JSON object per line; blank lines are skipped. Users
+// are arrays of strings or, as in CSV, one comma separated string.
+func readJSONL(r io.Reader, mapping Mapping) ([]Row, error) {
+	br := bufio.NewReader(r)
+
+	var rows []Row
+
+	for line := 1; ; line++ {
+		data, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		if len(bytes.TrimSpace(data)) > 0 {
+			row, rowErr := parseJSONRow(data, mapping)
+			row.Line = line
+			row.Err = rowErr
+			rows = append(rows, row)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+	}
+}
+
+func parseJSONRow(data []byte, mapping Mapping) (Row, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return Row{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var (
+		row Row
+		err error
+	)
+
+	if row.Title, err = jsonString(object, mapping, FieldTitle); err != nil {
+		return Row{}, err
+	}
+	if row.Status, err = jsonString(object, mapping, FieldStatus); err != nil {
+		return Row{}, err
+	}
+	if row.Assignees, err = jsonUsers(object, mapping, FieldAssignees); err != nil {
+		return Row{}, err
+	}
+	if row.Watchers, err = jsonUsers(object, mapping, FieldWatchers); err != nil {
+		return Row{}, err
+	}
+
+	return row, nil
+}
+
+func jsonString(object map[string]json.RawMessage, mapping Mapping, field Field) (string, error) {
+	raw, ok := object[mapping.source(field)]
+	if !ok {
+		return "", nil
+	}
+
+	var s *string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", fmt.Errorf("%s must be a string", mapping.source(field))
+	}
+	if s == nil {
+		return "", nil
+	}
+
+	return *s, nil
+}
+
+func jsonUsers(object map[string]json.RawMessage, mapping Mapping, field Field) ([]string, error) {
+	raw, ok := object[mapping.source(field)]
+	if !ok {
+		return nil, nil
+	}
+
+	var users []string
+	if err := json.Unmarshal(raw, &users); err == nil {
+		return users, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return splitUsers(s), nil
+	}
+
+	return nil, fmt.Errorf("%s must be an array of strings", mapping.source(field))
+}
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"io"
+	"strings"
+)
+
+// Tracker is another task tracker whose JSON export can be imported.
+type Tracker string
+
+const (
+	TrackerJira   Tracker = "jira"
+	TrackerTrello Tracker = "trello"
+	TrackerGitHub Tracker = "github"
+)
+
+func ParseTracker(s string) (Tracker, error) {
+	switch t := Tracker(strings.ToLower(s)); t {
+	case TrackerJira, TrackerTrello, TrackerGitHub:
+		return t, nil
+	default:
+		return "", fmt.Errorf("unsupported tracker %q, use jira, trello or github", s)
+	}
+}
+
+// StatusMap maps status names of a tracker, compared case-insensitively,
+// onto task statuses. It takes precedence over the defaults of each
+// tracker: Jira status categories, Trello list names and GitHub states and
+// labels.
+type StatusMap map[string]domain.TaskStatus
+
+// ParseStatusMap parses a comma separated list of name=status pairs, e.g.
+// "In Review=in_progress,Won't Do=done".
+func ParseStatusMap(s string) (StatusMap, error) {
+	statuses := StatusMap{}
+	if strings.TrimSpace(s) == "" {
+		return statuses, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		name, status, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		st := domain.TaskStatus(strings.TrimSpace(status))
+
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid status mapping %q, use name=status", pair)
+		}
+		if !domain.IsValidStatus(st) {
+			return nil, fmt.Errorf("invalid status %q for %q", status, name)
+		}
+
+		statuses[strings.ToLower(name)] = st
+	}
+
+	return statuses, nil
+}
+
+func (m StatusMap) lookup(name string) (domain.TaskStatus, bool) {
+	status, ok := m[strings.ToLower(strings.TrimSpace(name))]
+	return status, ok
+}
+
+// ReadTracker parses the JSON export of tracker. Every item becomes a row
+// with the item's ID as ExternalID and a status from statuses or the
+// tracker's defaults.
+func ReadTracker(r io.Reader, tracker Tracker, statuses StatusMap) ([]Row, error) {
+	switch tracker {
+	case TrackerJira:
+		return readJira(r, statuses)
+	case TrackerTrello:
+		return readTrello(r, statuses)
+	case TrackerGitHub:
+		return readGitHub(r, statuses)
+	default:
+		return nil, fmt.Errorf("unsupported tracker %q", tracker)
+	}
+}
+
+// containsAny reports whether name contains one of words, ignoring case.
+func containsAny(name string, words ...string) bool {
+	name = strings.ToLower(name)
+	for _, w := range words {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeExport decodes a whole export file into v.
+func decodeExport(r io.Reader, tracker Tracker, v any) error {
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("invalid %s export: %w", tracker, err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package importer_test
+
+import (
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/importer"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatusMap(t *testing.T) {
+	statuses, err := importer.ParseStatusMap("In Review=in_progress, Won't Do = done")
+	require.NoError(t, err)
+	assert.Equal(t, importer.StatusMap{"in review": domain.StatusInProgress, "won't do": domain.StatusDone}, statuses)
+
+	_, err = importer.ParseStatusMap("Review")
+	assert.ErrorContains(t, err, "name=status")
+
+	_, err = importer.ParseStatusMap("Review=reviewing")
+	assert.ErrorContains(t, err, "invalid status")
+}
+
+func TestReadTracker_Jira(t *testing.T) {
+	file := `{"issues":[
+		{"id":"10001","key":"PRJ-1","fields":{"summary":"Login fails",
+			"status":{"name":"Open","statusCategory":{"key":"new"}},
+			"assignee":{"accountId":"a1","emailAddress":"alice@example.com"},
+			"reporter":{"accountId":"b2"}}},
+		{"id":"10002","key":"PRJ-2","fields":{"summary":"Review copy",
+			"status":{"name":"In Review","statusCategory":{"key":"indeterminate"}}}},
+		{"id":"10003","key":"PRJ-3","fields":{"summary":"Odd",
+			"status":{"name":"Parked","statusCategory":{"key":"undefined"}}}}
+	]}`
+
+	rows, err := importer.ReadTracker(strings.NewReader(file), importer.TrackerJira, importer.StatusMap{"in review": domain.StatusDone})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, importer.Row{
+		Line:       1,
+		ExternalID: "10001",
+		Title:      "Login fails",
+		Status:     "todo",
+		Assignees:  []string{"alice@example.com"},
+		Watchers:   []string{"b2"},
+	}, rows[0])
+	assert.Equal(t, "done", rows[1].Status)
+	assert.ErrorContains(t, rows[2].Err, `"Parked"`)
+
+	// A bare array of issues reads the same.
+	rows, err = importer.ReadTracker(strings.NewReader(`[{"id":"1","fields":{"summary":"x"}}]`), importer.TrackerJira, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []importer.Row{{Line: 1, ExternalID: "1", Title: "x", Status: "todo"}}, rows)
+}
+
+func TestReadTracker_Trello(t *testing.T) {
+	file := `{
+		"lists":[{"id":"l1","name":"Backlog"},{"id":"l2","name":"Doing"},{"id":"l3","name":"Shipped 🚀"},{"id":"l4","name":"QA"}],
+		"members":[{"id":"m1","username":"alice"}],
+		"cards":[
+			{"id":"c1","name":"Plan","idList":"l1","idMembers":["m1","m9"]},
+			{"id":"c2","name":"Build","idList":"l2"},
+			{"id":"c3","name":"Launch","idList":"l3"},
+			{"id":"c4","name":"Test","idList":"l4"},
+			{"id":"c5","name":"Old","idList":"l1","closed":true},
+			{"id":"c6","name":"Lost","idList":"l9"}
+		]}`
+
+	rows, err := importer.ReadTracker(strings.NewReader(file), importer.TrackerTrello, importer.StatusMap{"qa": domain.StatusInProgress})
+	require.NoError(t, err)
+	require.Len(t, rows, 6)
+
+	assert.Equal(t, importer.Row{
+		Line:       1,
+		ExternalID: "c1",
+		Title:      "Plan",
+		Status:     "todo",
+		Assignees:  []string{"alice", "m9"},
+	}, rows[0])
+	assert.Equal(t, "in_progress", rows[1].Status)
+	assert.Equal(t, "done", rows[2].Status)
+	assert.Equal(t, "in_progress", rows[3].Status)
+	assert.Equal(t, "done", rows[4].Status)
+	assert.ErrorContains(t, rows[5].Err, "unknown list")
+}
+
+func TestReadTracker_GitHub(t *testing.T) {
+	file := `[
+		{"id":1,"number":1,"html_url":"https://github.com/o/r/issues/1","title":"Crash","state":"open",
+			"assignees":[{"login":"alice"}],"assignee":{"login":"alice"},"user":{"login":"bob"}},
+		{"id":2,"number":2,"html_url":"https://github.com/o/r/pull/2","title":"Fix","state":"open","pull_request":{"url":"x"}},
+		{"id":3,"number":3,"html_url":"https://github.com/o/r/issues/3","title":"Slow","state":"open","labels":[{"name":"WIP"}]},
+		{"id":4,"number":4,"html_url":"https://github.com/o/r/issues/4","title":"Typo","state":"closed"},
+		{"number":5,"url":"https://github.com/o/r/issues/5","title":"Docs","state":"OPEN","labels":[{"name":"blocked"}],"author":{"login":"carol"}}
+	]`
+
+	rows, err := importer.ReadTracker(strings.NewReader(file), importer.TrackerGitHub, importer.StatusMap{"closed": domain.StatusDone, "blocked": domain.StatusInProgress})
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, importer.Row{
+		Line:       1,
+		ExternalID: "https://github.com/o/r/issues/1",
+		Title:      "Crash",
+		Status:     "todo",
+		Assignees:  []string{"alice"},
+		Watchers:   []string{"bob"},
+	}, rows[0])
+	assert.Equal(t, "in_progress", rows[1].Status)
+	assert.Equal(t, "done", rows[2].Status)
+	assert.Equal(t, importer.Row{
+		Line:       5,
+		ExternalID: "https://github.com/o/r/issues/5",
+		Title:      "Docs",
+		Status:     "in_progress",
+		Watchers:   []string{"carol"},
+	}, rows[3])
+}
+
+func TestReadTracker_Invalid(t *testing.T) {
+	_, err := importer.ReadTracker(strings.NewReader("{"), importer.TrackerTrello, nil)
+	assert.ErrorContains(t, err, "invalid trello export")
+
+	_, err = importer.ParseTracker("asana")
+	assert.ErrorContains(t, err, "unsupported tracker")
+}
@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"fmt"
+	"graph-task-service/internal/domain"
+	"io"
+)
+
+type trelloBoard struct {
+	Lists []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"lists"`
+	Cards []struct {
+		ID        string   `json:"id"`
+		Name      string   `json:"name"`
+		IDList    string   `json:"idList"`
+		IDMembers []string `json:"idMembers"`
+		Closed    bool     `json:"closed"`
+	} `json:"cards"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"members"`
+}
+
+// readTrello reads the cards of a board exported as JSON. A card's members
+// are its assignees and its list decides the status: lists without an
+// entry in statuses are matched by name, and archived cards are done.
+func readTrello(r io.Reader, statuses StatusMap) ([]Row, error) {
+	var board trelloBoard
+	if err := decodeExport(r, TrackerTrello, &board); err != nil {
+		return nil, err
+	}
+
+	lists := make(map[string]string, len(board.Lists))
+	for _, l := range board.Lists {
+		lists[l.ID] = l.Name
+	}
+
+	usernames := make(map[string]string, len(board.Members))
+	for _, m := range board.Members {
+		usernames[m.ID] = m.Username
+	}
+
+	rows := make([]Row, 0, len(board.Cards))
+
+	for i, card := range board.Cards {
+		row := Row{
+			Line:       i + 1,
+			ExternalID: card.ID,
+			Title:      card.Name,
+		}
+
+		if card.ID == "" {
+			row.Err = fmt.Errorf("card %q has no id", card.Name)
+		}
+
+		list, ok := lists[card.IDList]
+		switch {
+		case card.Closed:
+			row.Status = string(domain.StatusDone)
+		case !ok:
+			row.Err = fmt.Errorf("card %q is on unknown list %s", card.Name, card.IDList)
+		default:
+			row.Status = string(trelloStatus(list, statuses))
+		}
+
+		for _, id := range card.IDMembers {
+			if name := usernames[id]; name != "" {
+				row.Assignees = append(row.Assignees, name)
+			} else {
+				row.Assignees = append(row.Assignees, id)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func trelloStatus(list string, statuses StatusMap) domain.TaskStatus {
+	if status, ok := statuses.lookup(list); ok {
+		return status
+	}
+
+	switch {
+	case containsAny(list, "done", "complete", "closed", "finished", "shipped", "released"):
+		return domain.StatusDone
+	case containsAny(list, "progress", "doing", "review", "testing", "wip"):
+		return domain.StatusInProgress
+	default:
+		return domain.StatusTodo
+	}
+}
@@ -0,0 +1,51 @@
+package middelware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHeader is the default header carrying the authenticated username set
+// by the upstream gateway.
+const UserHeader = "X-User"
+
+type userKey struct{}
+
+// Authenticate copies the caller identity from UserHeader into the request context.
+// Requests without the header pass through anonymously.
+func Authenticate() gin.HandlerFunc {
+	return AuthenticateHeader(UserHeader)
+}
+
+// AuthenticateHeader is Authenticate reading the identity from header.
+func AuthenticateHeader(header string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user := c.GetHeader(header); user != "" {
+			c.Request = c.Request.WithContext(WithUser(c.Request.Context(), user))
+		}
+		c.Next()
+	}
+}
+
+// RequireUser rejects anonymous requests with 401. It must run after
+// Authenticate.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := UserFromContext(c.Request.Context()); !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+func UserFromContext(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(userKey{}).(string)
+	return user, ok && user != ""
+}
@@ -0,0 +1,40 @@
+package middelware_test
+
+import (
+	"graph-task-service/internal/middelware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(middelware.AuthenticateHeader("X-Forwarded-User"))
+	r.Use(middelware.RequireUser())
+	r.GET("/whoami", func(c *gin.Context) {
+		user, _ := middelware.UserFromContext(c.Request.Context())
+		c.String(http.StatusOK, user)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/whoami", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set(middelware.UserHeader, "alice")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "only the configured header counts")
+
+	req = httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set("X-Forwarded-User", "alice")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "alice", w.Body.String())
+}
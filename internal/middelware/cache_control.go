@@ -0,0 +1,23 @@
+package middelware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControl sets the Cache-Control header of responses to policy, and
+// Vary to the request headers the response depends on. Handlers may still
+// override both.
+func CacheControl(policy string, vary ...string) gin.HandlerFunc {
+	varyHeader := strings.Join(vary, ", ")
+
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", policy)
+		if varyHeader != "" {
+			c.Header("Vary", varyHeader)
+		}
+
+		c.Next()
+	}
+}
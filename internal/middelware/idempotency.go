@@ -0,0 +1,162 @@
+package middelware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/logging"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayHeader is set on responses replayed from a stored record.
+	IdempotentReplayHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency makes mutating requests that carry an Idempotency-Key header
+// safe to retry. The first request with a key runs normally and its response
+// is stored for ttl; retries with the same key and body get the stored
+// response back, while reusing the key with a different body is rejected
+// with 422. While the first request runs, retries get 409; if it dies
+// without storing a response, the key is free again after lease. Keys are
+// scoped to the authenticated user.
+func Idempotency(repo domain.IdempotencyRepository, ttl, lease time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "idempotency key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if maxErr := (*http.MaxBytesError)(nil); errors.As(err, &maxErr) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		scopedKey := scopeKey(ctx, key)
+		fingerprint := fingerprintRequest(c.Request, body)
+
+		existing, reserved, err := repo.Reserve(ctx, scopedKey, fingerprint, lease)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !reserved {
+			replay(c, existing, fingerprint)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// Use a fresh context: the request context may already be cancelled,
+		// and a key left reserved would block retries until it expires.
+		storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			err = repo.Release(storeCtx, scopedKey)
+		} else {
+			err = repo.Complete(storeCtx, scopedKey, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes(), ttl)
+		}
+
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("store idempotency key", "idempotency_key", key, "error", err)
+		}
+	}
+}
+
+func replay(c *gin.Context, record *domain.IdempotencyRecord, fingerprint string) {
+	if !record.Completed {
+		// Retry-After tells clients this conflict clears up, unlike one
+		// raised by the request itself.
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error": "a request with this idempotency key is still in progress",
+		})
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "idempotency key was already used with a different request",
+		})
+		return
+	}
+
+	c.Header(IdempotentReplayHeader, "true")
+	if record.ContentType == "" {
+		c.Status(record.StatusCode)
+		c.Writer.WriteHeaderNow()
+		c.Abort()
+		return
+	}
+	c.Data(record.StatusCode, record.ContentType, record.Body)
+	c.Abort()
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func scopeKey(ctx context.Context, key string) string {
+	user, _ := UserFromContext(ctx)
+	return user + ":" + key
+}
+
+// fingerprintRequest identifies a request by method, path and body.
+func fingerprintRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.Path)
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,180 @@
+package middelware_test
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/middelware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryIdempotency struct {
+	mu      sync.Mutex
+	records map[string]*domain.IdempotencyRecord
+}
+
+func newMemoryIdempotency() *memoryIdempotency {
+	return &memoryIdempotency{records: make(map[string]*domain.IdempotencyRecord)}
+}
+
+func (m *memoryIdempotency) Reserve(ctx context.Context, key string, fingerprint string, lease time.Duration) (*domain.IdempotencyRecord, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r, ok := m.records[key]; ok && r.ExpiresAt.After(time.Now()) {
+		copied := *r
+		return &copied, false, nil
+	}
+
+	m.records[key] = &domain.IdempotencyRecord{Key: key, Fingerprint: fingerprint, ExpiresAt: time.Now().Add(lease)}
+	return nil, true, nil
+}
+
+func (m *memoryIdempotency) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := m.records[key]
+	r.Completed = true
+	r.StatusCode = statusCode
+	r.ContentType = contentType
+	r.Body = body
+	r.ExpiresAt = time.Now().Add(ttl)
+	return nil
+}
+
+func (m *memoryIdempotency) Release(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+	return nil
+}
+
+func (m *memoryIdempotency) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func setupIdempotentRouter(repo domain.IdempotencyRepository, status *int) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	r := gin.New()
+	r.Use(middelware.Authenticate())
+	r.Use(middelware.Idempotency(repo, time.Hour, time.Minute))
+	r.POST("/tasks", func(c *gin.Context) {
+		calls++
+		c.JSON(*status, gin.H{"call": calls})
+	})
+
+	return r, &calls
+}
+
+func post(r http.Handler, key string, body string, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(middelware.IdempotencyKeyHeader, key)
+	}
+	if user != "" {
+		req.Header.Set(middelware.UserHeader, user)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_ReplaysResponse(t *testing.T) {
+	status := http.StatusCreated
+	r, calls := setupIdempotentRouter(newMemoryIdempotency(), &status)
+
+	first := post(r, "k1", `{"title":"a"}`, "")
+	second := post(r, "k1", `{"title":"a"}`, "")
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(middelware.IdempotentReplayHeader))
+}
+
+func TestIdempotency_RejectsDifferentBody(t *testing.T) {
+	status := http.StatusCreated
+	r, calls := setupIdempotentRouter(newMemoryIdempotency(), &status)
+
+	post(r, "k1", `{"title":"a"}`, "")
+	rec := post(r, "k1", `{"title":"b"}`, "")
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	status := http.StatusInternalServerError
+	r, calls := setupIdempotentRouter(newMemoryIdempotency(), &status)
+
+	post(r, "k1", `{}`, "")
+	status = http.StatusCreated
+	rec := post(r, "k1", `{}`, "")
+
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestIdempotency_KeysAreScopedPerUser(t *testing.T) {
+	status := http.StatusCreated
+	r, calls := setupIdempotentRouter(newMemoryIdempotency(), &status)
+
+	post(r, "k1", `{}`, "alice")
+	post(r, "k1", `{}`, "bob")
+	post(r, "k1", `{}`, "")
+
+	assert.Equal(t, 3, *calls)
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	repo := newMemoryIdempotency()
+	_, _, _ = repo.Reserve(context.Background(), ":k1", "", time.Hour)
+
+	status := http.StatusCreated
+	r, calls := setupIdempotentRouter(repo, &status)
+
+	rec := post(r, "k1", ``, "")
+
+	assert.Equal(t, 0, *calls)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+}
+
+func TestIdempotency_TakesOverAnAbandonedKey(t *testing.T) {
+	repo := newMemoryIdempotency()
+	_, _, _ = repo.Reserve(context.Background(), ":k1", "", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	status := http.StatusCreated
+	r, calls := setupIdempotentRouter(repo, &status)
+
+	rec := post(r, "k1", ``, "")
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestIdempotency_KeepsResponsesForTheTTL(t *testing.T) {
+	repo := newMemoryIdempotency()
+
+	status := http.StatusCreated
+	r, calls := setupIdempotentRouter(repo, &status)
+
+	post(r, "k1", `{}`, "")
+
+	assert.Equal(t, 1, *calls)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), repo.records[":k1"].ExpiresAt, time.Minute)
+}
@@ -0,0 +1,75 @@
+package middelware
+
+import (
+	"graph-task-service/internal/logging"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Logger stores a per-request logger, tagged with the request and trace IDs,
+// in the request context and writes one access log line per request. It must
+// run after RequestID and Tracing.
+func Logger(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		ctx := c.Request.Context()
+		logger := base.With("request_id", RequestIDFromContext(ctx))
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			logger = logger.With("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+		}
+
+		c.Request = c.Request.WithContext(logging.WithLogger(ctx, logger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("response_size", c.Writer.Size()),
+		}
+
+		if user, ok := UserFromContext(c.Request.Context()); ok {
+			attrs = append(attrs, slog.String("user", user))
+		}
+
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns panics into 500 responses and logs them with the request
+// logger instead of gin's plain text writer.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err any) {
+		// Handlers abort a response already under way, such as a failing
+		// export, with http.ErrAbortHandler; the server then drops the
+		// connection instead of ending the body normally.
+		if err == http.ErrAbortHandler {
+			panic(err)
+		}
+		logging.FromContext(c.Request.Context()).Error("panic recovered", "panic", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	})
+}
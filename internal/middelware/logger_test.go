@@ -0,0 +1,103 @@
+package middelware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"graph-task-service/internal/logging"
+	"graph-task-service/internal/middelware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoggedRouter(buf *bytes.Buffer) *gin.Engine {
+	logger, _ := logging.New("debug", buf)
+
+	r := gin.New()
+	r.Use(middelware.RequestID())
+	r.Use(middelware.Logger(logger))
+	r.Use(middelware.Recovery())
+
+	r.GET("/ping", func(c *gin.Context) {
+		logging.FromContext(c.Request.Context()).Info("handling")
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	return r
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var entries []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var entry map[string]any
+		require.NoError(t, dec.Decode(&entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRequestID_HonorsIncomingHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	r := newLoggedRouter(&buf)
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(middelware.RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "req-123", w.Header().Get(middelware.RequestIDHeader))
+
+	entries := decodeLines(t, &buf)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, "req-123", entry["request_id"])
+	}
+	assert.Equal(t, "/ping", entries[1]["route"])
+	assert.EqualValues(t, http.StatusOK, entries[1]["status"])
+}
+
+func TestRequestID_GeneratesWhenMissingOrInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := newLoggedRouter(&bytes.Buffer{})
+
+	for _, incoming := range []string{"", "bad id\nwith newline"} {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		if incoming != "" {
+			req.Header.Set(middelware.RequestIDHeader, incoming)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		id := w.Header().Get(middelware.RequestIDHeader)
+		assert.Len(t, id, 32)
+		assert.NotEqual(t, incoming, id)
+	}
+}
+
+func TestRecovery_LogsPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	r := newLoggedRouter(&buf)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	entries := decodeLines(t, &buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "panic recovered", entries[0]["msg"])
+	assert.Equal(t, "ERROR", entries[1]["level"])
+}
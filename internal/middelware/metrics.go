@@ -1 +1,29 @@
 package middelware
+
+import (
+	"graph-task-service/internal/observability"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request counts and latency per route template, e.g.
+// "/tasks/:id" rather than the raw path, to keep label cardinality bounded.
+func Metrics(m *observability.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.RequestsTotal.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.RequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
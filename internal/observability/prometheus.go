@@ -1 +1,139 @@
 package observability
+
+import (
+	"context"
+	"database/sql"
+	"graph-task-service/internal/domain"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors of the service. Each instance has
+// its own registry so tests can create as many as they need.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	RequestsTotal   *prometheus.CounterVec
+	RequestDuration *prometheus.HistogramVec
+	ServiceDuration *prometheus.HistogramVec
+	ServiceErrors   *prometheus.CounterVec
+	CacheRequests   *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		RequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests processed, by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+
+		RequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency, by method, route template and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+
+		ServiceDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "service_operation_duration_seconds",
+			Help:    "Latency of task service operations.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation"}),
+
+		ServiceErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "service_operation_errors_total",
+			Help: "Task service operations that returned an error.",
+		}, []string{"operation"}),
+
+		CacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_requests_total",
+			Help: "Cache lookups, by cache and result (hit or miss).",
+		}, []string{"cache", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.RequestsTotal,
+		m.RequestDuration,
+		m.ServiceDuration,
+		m.ServiceErrors,
+		m.CacheRequests,
+	)
+
+	return m
+}
+
+// RegisterDB exports the connection pool statistics of db as go_sql_* metrics.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterTaskCounter exports the tasks_count gauge, computed from counter on
+// every scrape.
+func (m *Metrics) RegisterTaskCounter(counter domain.TaskCounter) {
+	m.registry.MustRegister(&taskCountCollector{counter: counter})
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveOperation records the duration and outcome of a service operation
+// started at start.
+func (m *Metrics) ObserveOperation(operation string, start time.Time, err error) {
+	m.ServiceDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.ServiceErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+func (m *Metrics) CacheHit(cache string) {
+	m.CacheRequests.WithLabelValues(cache, "hit").Inc()
+}
+
+func (m *Metrics) CacheMiss(cache string) {
+	m.CacheRequests.WithLabelValues(cache, "miss").Inc()
+}
+
+var taskCountDesc = prometheus.NewDesc(
+	"tasks_count",
+	"Number of tasks, by status.",
+	[]string{"status"},
+	nil,
+)
+
+type taskCountCollector struct {
+	counter domain.TaskCounter
+}
+
+func (c *taskCountCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- taskCountDesc
+}
+
+func (c *taskCountCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	counts, err := c.counter.CountByStatus(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "collect tasks_count", "error", err)
+		ch <- prometheus.NewInvalidMetric(taskCountDesc, err)
+		return
+	}
+
+	// Report every known status so absent ones show up as zero.
+	for _, status := range []domain.TaskStatus{
+		domain.StatusTodo,
+		domain.StatusInProgress,
+		domain.StatusDone,
+	} {
+		ch <- prometheus.MustNewConstMetric(taskCountDesc, prometheus.GaugeValue, float64(counts[status]), string(status))
+	}
+}
@@ -0,0 +1,62 @@
+package observability_test
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/middelware"
+	"graph-task-service/internal/observability"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedCounter map[domain.TaskStatus]int
+
+func (c fixedCounter) CountByStatus(ctx context.Context) (map[domain.TaskStatus]int, error) {
+	return c, nil
+}
+
+func scrape(t *testing.T, m *observability.Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetricsMiddleware_UsesRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := observability.NewMetrics()
+
+	r := gin.New()
+	r.Use(middelware.Metrics(m))
+	r.GET("/tasks/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	for _, path := range []string{"/tasks/1", "/tasks/2", "/nope"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.RequestsTotal.WithLabelValues("GET", "/tasks/:id", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.RequestsTotal.WithLabelValues("GET", "unmatched", "404")))
+	assert.Contains(t, scrape(t, m), `http_request_duration_seconds_count{method="GET",route="/tasks/:id",status="404"} 2`)
+}
+
+func TestTaskCounter_ReportsEveryStatus(t *testing.T) {
+	m := observability.NewMetrics()
+	m.RegisterTaskCounter(fixedCounter{domain.StatusTodo: 3})
+
+	body := scrape(t, m)
+
+	assert.Contains(t, body, `tasks_count{status="todo"} 3`)
+	assert.Contains(t, body, `tasks_count{status="done"} 0`)
+}
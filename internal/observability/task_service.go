@@ -0,0 +1,163 @@
+package observability
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/service"
+	"time"
+)
+
+type instrumentedTaskService struct {
+	next    service.TaskService
+	metrics *Metrics
+}
+
+// InstrumentTaskService wraps every call to next in a span and records its
+// latency and errors.
+func InstrumentTaskService(next service.TaskService, metrics *Metrics) service.TaskService {
+	return &instrumentedTaskService{next: next, metrics: metrics}
+}
+
+func (s *instrumentedTaskService) start(
+	ctx context.Context,
+	operation string,
+) (context.Context, func(error)) {
+
+	start := time.Now()
+	ctx, span := Tracer().Start(ctx, "taskService."+operation)
+
+	return ctx, func(err error) {
+		s.metrics.ObserveOperation(operation, start, err)
+		EndSpan(span, err)
+	}
+}
+
+func (s *instrumentedTaskService) CreateTask(
+	ctx context.Context,
+	title string,
+	assignees []string,
+	watchers []string,
+	status *domain.TaskStatus,
+) (task *domain.Task, err error) {
+
+	ctx, done := s.start(ctx, "CreateTask")
+	defer func() { done(err) }()
+
+	return s.next.CreateTask(ctx, title, assignees, watchers, status)
+}
+
+func (s *instrumentedTaskService) GetTask(
+	ctx context.Context,
+	id string,
+) (task *domain.Task, err error) {
+
+	ctx, done := s.start(ctx, "GetTask")
+	defer func() { done(err) }()
+
+	return s.next.GetTask(ctx, id)
+}
+
+func (s *instrumentedTaskService) GetTasks(
+	ctx context.Context,
+	ids []string,
+) (tasks []*domain.Task, err error) {
+
+	ctx, done := s.start(ctx, "GetTasks")
+	defer func() { done(err) }()
+
+	return s.next.GetTasks(ctx, ids)
+}
+
+func (s *instrumentedTaskService) ListTasks(
+	ctx context.Context,
+	filter domain.TaskFilter,
+) (tasks []*domain.Task, err error) {
+
+	ctx, done := s.start(ctx, "ListTasks")
+	defer func() { done(err) }()
+
+	return s.next.ListTasks(ctx, filter)
+}
+
+func (s *instrumentedTaskService) StreamTasks(
+	ctx context.Context,
+	filter domain.TaskFilter,
+	fn func(*domain.Task) error,
+) (err error) {
+
+	ctx, done := s.start(ctx, "StreamTasks")
+	defer func() { done(err) }()
+
+	return s.next.StreamTasks(ctx, filter, fn)
+}
+
+func (s *instrumentedTaskService) UpdateStatus(
+	ctx context.Context,
+	id string,
+	status domain.TaskStatus,
+) (task *domain.Task, err error) {
+
+	ctx, done := s.start(ctx, "UpdateStatus")
+	defer func() { done(err) }()
+
+	return s.next.UpdateStatus(ctx, id, status)
+}
+
+func (s *instrumentedTaskService) DeleteTask(
+	ctx context.Context,
+	id string,
+) (err error) {
+
+	ctx, done := s.start(ctx, "DeleteTask")
+	defer func() { done(err) }()
+
+	return s.next.DeleteTask(ctx, id)
+}
+
+func (s *instrumentedTaskService) AddAssignee(
+	ctx context.Context,
+	id string,
+	user string,
+) (task *domain.Task, err error) {
+
+	ctx, done := s.start(ctx, "AddAssignee")
+	defer func() { done(err) }()
+
+	return s.next.AddAssignee(ctx, id, user)
+}
+
+func (s *instrumentedTaskService) RemoveAssignee(
+	ctx context.Context,
+	id string,
+	user string,
+) (task *domain.Task, err error) {
+
+	ctx, done := s.start(ctx, "RemoveAssignee")
+	defer func() { done(err) }()
+
+	return s.next.RemoveAssignee(ctx, id, user)
+}
+
+func (s *instrumentedTaskService) AddWatcher(
+	ctx context.Context,
+	id string,
+	user string,
+) (task *domain.Task, err error) {
+
+	ctx, done := s.start(ctx, "AddWatcher")
+	defer func() { done(err) }()
+
+	return s.next.AddWatcher(ctx, id, user)
+}
+
+func (s *instrumentedTaskService) RemoveWatcher(
+	ctx context.Context,
+	id string,
+	user string,
+) (task *domain.Task, err error) {
+
+	ctx, done := s.start(ctx, "RemoveWatcher")
+	defer func() { done(err) }()
+
+	return s.next.RemoveWatcher(ctx, id, user)
+}
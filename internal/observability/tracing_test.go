@@ -0,0 +1,92 @@
+package observability_test
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/middelware"
+	"graph-task-service/internal/observability"
+	"graph-task-service/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// stubTaskService answers GetTask and fails for id "missing".
+type stubTaskService struct {
+	service.TaskService
+}
+
+func (stubTaskService) GetTask(ctx context.Context, id string) (*domain.Task, error) {
+	if id == "missing" {
+		return nil, service.ErrTaskNotFound
+	}
+	return &domain.Task{ID: id}, nil
+}
+
+func useInMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return exporter
+}
+
+func TestTracing_PropagatesTraceparentThroughLayers(t *testing.T) {
+	exporter := useInMemoryTracing(t)
+	gin.SetMode(gin.TestMode)
+
+	svc := observability.InstrumentTaskService(stubTaskService{}, observability.NewMetrics())
+
+	r := gin.New()
+	r.Use(middelware.Tracing())
+	r.GET("/tasks/:id", observability.TraceHandler("TaskHandler.GetByID", func(c *gin.Context) {
+		_, err := svc.GetTask(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/missing", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	byName := make(map[string]tracetest.SpanStub)
+	for _, s := range spans {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", s.SpanContext.TraceID().String())
+		byName[s.Name] = s
+	}
+
+	server := byName["GET /tasks/:id"]
+	handler := byName["TaskHandler.GetByID"]
+	call := byName["taskService.GetTask"]
+
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Equal(t, server.SpanContext.SpanID(), handler.Parent.SpanID())
+	assert.Equal(t, handler.SpanContext.SpanID(), call.Parent.SpanID())
+	assert.Equal(t, "Error", call.Status.Code.String())
+}
+
+func TestSetupTracing_RejectsUnknownExporter(t *testing.T) {
+	_, err := observability.SetupTracing(context.Background(), observability.TracingConfig{Exporter: "zipkin"}, nil)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"graph-task-service/internal/domain"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// IdempotencyKeyHeader carries the event ID on Kafka messages.
+const IdempotencyKeyHeader = "idempotency-key"
+
+// kafkaWriter is the subset of *kafka.Writer used by KafkaPublisher.
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// KafkaPublisher writes events keyed by task ID, so all events of a task land
+// on the same partition in order.
+type KafkaPublisher struct {
+	writer kafkaWriter
+}
+
+func NewKafkaPublisher(writer kafkaWriter) *KafkaPublisher {
+	return &KafkaPublisher{writer: writer}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event domain.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.TaskID),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: IdempotencyKeyHeader, Value: []byte(event.ID)},
+			{Key: "event-type", Value: []byte(event.Type)},
+		},
+	})
+}
@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"graph-task-service/internal/domain"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// jetStream is the subset of jetstream.JetStream used by NATSPublisher.
+type jetStream interface {
+	PublishMsg(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error)
+}
+
+// NATSPublisher publishes events to "<subject>.<event type>" on JetStream,
+// which needs a stream capturing those subjects. Publish returns once the
+// stream acknowledged the event, so the relay only marks stored events
+// published. The event ID is sent as Nats-Msg-Id so the stream deduplicates
+// redeliveries.
+type NATSPublisher struct {
+	js      jetStream
+	subject string
+}
+
+func NewNATSPublisher(js jetStream, subject string) *NATSPublisher {
+	return &NATSPublisher{js: js, subject: subject}
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event domain.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(p.subject + "." + string(event.Type))
+	msg.Header.Set(jetstream.MsgIDHeader, event.ID)
+	msg.Data = data
+
+	_, err = p.js.PublishMsg(ctx, msg)
+	return err
+}
@@ -0,0 +1,214 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/outbox"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutbox is a fake domain.OutboxRepository that hands out every
+// unpublished message on each claim.
+type memoryOutbox struct {
+	messages  []*domain.OutboxMessage
+	retryIn   []time.Duration
+	published map[string]bool
+}
+
+func newMemoryOutbox(events ...domain.TaskEvent) *memoryOutbox {
+	o := &memoryOutbox{published: make(map[string]bool)}
+	for _, e := range events {
+		o.messages = append(o.messages, &domain.OutboxMessage{Event: e})
+	}
+	return o
+}
+
+func (o *memoryOutbox) ClaimBatch(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	var out []*domain.OutboxMessage
+	for _, m := range o.messages {
+		if !o.published[m.Event.ID] && len(out) < limit {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (o *memoryOutbox) MarkPublished(ctx context.Context, id string) error {
+	o.published[id] = true
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(ctx context.Context, id string, lastErr string, retryIn time.Duration) error {
+	for _, m := range o.messages {
+		if m.Event.ID == id {
+			m.Attempts++
+			m.LastError = &lastErr
+		}
+	}
+	o.retryIn = append(o.retryIn, retryIn)
+	return nil
+}
+
+func (o *memoryOutbox) DeletePublished(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return 0, nil
+}
+
+type flakyPublisher struct {
+	failures int
+	inner    domain.EventPublisher
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, event domain.TaskEvent) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker unavailable")
+	}
+	return p.inner.Publish(ctx, event)
+}
+
+func testConfig() outbox.RelayConfig {
+	cfg := outbox.DefaultRelayConfig()
+	cfg.BaseBackoff = time.Second
+	cfg.MaxBackoff = 3 * time.Second
+	return cfg
+}
+
+func TestRelay_PublishesInOrder(t *testing.T) {
+	store := newMemoryOutbox(
+		domain.TaskEvent{ID: "e1", Type: domain.EventTaskCreated, TaskID: "t1"},
+		domain.TaskEvent{ID: "e2", Type: domain.EventTaskDeleted, TaskID: "t1"},
+	)
+	sink := outbox.NewMemoryPublisher()
+
+	n, err := outbox.NewRelay(store, sink, testConfig()).ProcessBatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	events := sink.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "e1", events[0].ID)
+	assert.Equal(t, "e2", events[1].ID)
+}
+
+func TestRelay_RetriesWithBackoffUntilPublished(t *testing.T) {
+	store := newMemoryOutbox(domain.TaskEvent{ID: "e1", Type: domain.EventTaskCreated})
+	sink := outbox.NewMemoryPublisher()
+	relay := outbox.NewRelay(store, &flakyPublisher{failures: 3, inner: sink}, testConfig())
+
+	for i := 0; i < 4; i++ {
+		_, err := relay.ProcessBatch(context.Background())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, store.retryIn)
+	assert.True(t, store.published["e1"])
+	assert.Len(t, sink.Events(), 1)
+}
+
+func TestMemoryPublisher_DropsRedeliveries(t *testing.T) {
+	sink := outbox.NewMemoryPublisher()
+	event := domain.TaskEvent{ID: "e1", Type: domain.EventTaskCreated}
+
+	require.NoError(t, sink.Publish(context.Background(), event))
+	require.NoError(t, sink.Publish(context.Background(), event))
+
+	assert.Len(t, sink.Events(), 1)
+}
+
+func TestMemoryPublisher_KeepsTheLatestEvents(t *testing.T) {
+	sink := outbox.NewMemoryPublisher()
+
+	for i := range 10001 {
+		event := domain.TaskEvent{ID: "e" + strconv.Itoa(i), Type: domain.EventTaskCreated}
+		require.NoError(t, sink.Publish(context.Background(), event))
+	}
+
+	events := sink.Events()
+	require.Len(t, events, 10000)
+	assert.Equal(t, "e1", events[0].ID)
+	assert.Equal(t, "e10000", events[len(events)-1].ID)
+}
+
+func TestMultiPublisher_ReportsFailures(t *testing.T) {
+	sink := outbox.NewMemoryPublisher()
+	multi := outbox.MultiPublisher{
+		sink,
+		&flakyPublisher{failures: 1, inner: outbox.NewMemoryPublisher()},
+	}
+
+	err := multi.Publish(context.Background(), domain.TaskEvent{ID: "e1"})
+
+	assert.Error(t, err)
+	assert.Len(t, sink.Events(), 1)
+}
+
+type fakeJetStream struct {
+	msgs []*nats.Msg
+	err  error
+}
+
+func (f *fakeJetStream) PublishMsg(ctx context.Context, m *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.msgs = append(f.msgs, m)
+	return &jetstream.PubAck{Stream: "TASKS", Sequence: uint64(len(f.msgs))}, nil
+}
+
+func TestNATSPublisher_SetsMessageID(t *testing.T) {
+	js := &fakeJetStream{}
+	pub := outbox.NewNATSPublisher(js, "tasks")
+
+	err := pub.Publish(context.Background(), domain.TaskEvent{ID: "e1", Type: domain.EventTaskCreated, TaskID: "t1"})
+
+	require.NoError(t, err)
+	require.Len(t, js.msgs, 1)
+	assert.Equal(t, "tasks.task.created", js.msgs[0].Subject)
+	assert.Equal(t, "e1", js.msgs[0].Header.Get(jetstream.MsgIDHeader))
+
+	var got domain.TaskEvent
+	require.NoError(t, json.Unmarshal(js.msgs[0].Data, &got))
+	assert.Equal(t, "t1", got.TaskID)
+}
+
+func TestNATSPublisher_FailsWithoutAck(t *testing.T) {
+	js := &fakeJetStream{err: jetstream.ErrNoStreamResponse}
+	pub := outbox.NewNATSPublisher(js, "tasks")
+
+	err := pub.Publish(context.Background(), domain.TaskEvent{ID: "e1", Type: domain.EventTaskCreated, TaskID: "t1"})
+
+	require.ErrorIs(t, err, jetstream.ErrNoStreamResponse)
+}
+
+type fakeKafka struct {
+	msgs []kafka.Message
+}
+
+func (f *fakeKafka) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.msgs = append(f.msgs, msgs...)
+	return nil
+}
+
+func TestKafkaPublisher_KeysByTask(t *testing.T) {
+	writer := &fakeKafka{}
+	pub := outbox.NewKafkaPublisher(writer)
+
+	err := pub.Publish(context.Background(), domain.TaskEvent{ID: "e1", Type: domain.EventTaskDeleted, TaskID: "t1"})
+
+	require.NoError(t, err)
+	require.Len(t, writer.msgs, 1)
+	assert.Equal(t, []byte("t1"), writer.msgs[0].Key)
+	assert.Equal(t, outbox.IdempotencyKeyHeader, writer.msgs[0].Headers[0].Key)
+	assert.Equal(t, []byte("e1"), writer.msgs[0].Headers[0].Value)
+}
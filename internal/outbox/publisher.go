@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"sync"
+)
+
+// memoryPublisherLimit is how many events a MemoryPublisher keeps.
+const memoryPublisherLimit = 10000
+
+// MemoryPublisher keeps the latest published events in memory, dropping
+// redeliveries of an event ID it still holds. It is meant for tests and local
+// runs.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	seen   map[string]struct{}
+	events []domain.TaskEvent
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{seen: make(map[string]struct{})}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event domain.TaskEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.seen[event.ID]; ok {
+		return nil
+	}
+
+	p.seen[event.ID] = struct{}{}
+	p.events = append(p.events, event)
+
+	if len(p.events) > memoryPublisherLimit {
+		delete(p.seen, p.events[0].ID)
+		p.events = p.events[1:]
+	}
+
+	return nil
+}
+
+// Events returns the distinct events it holds, in order.
+func (p *MemoryPublisher) Events() []domain.TaskEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]domain.TaskEvent(nil), p.events...)
+}
+
+// MultiPublisher publishes every event to each of its publishers. When one of
+// them fails the whole publish is retried, so the others may see the event
+// more than once; they are expected to deduplicate on the event ID.
+type MultiPublisher []domain.EventPublisher
+
+func (m MultiPublisher) Publish(ctx context.Context, event domain.TaskEvent) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,110 @@
+package outbox
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"log/slog"
+	"time"
+)
+
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease is how long a claimed message stays invisible to other relays.
+	Lease       time.Duration
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Retention is how long published messages are kept before being pruned.
+	Retention time.Duration
+}
+
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		Lease:        30 * time.Second,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   5 * time.Minute,
+		Retention:    7 * 24 * time.Hour,
+	}
+}
+
+// Relay moves events from the outbox to a publisher. A message is marked
+// published only after Publish returns, so delivery is at-least-once: a crash
+// in between republishes the message with the same Event.ID, which consumers
+// use as an idempotency key.
+type Relay struct {
+	outbox    domain.OutboxRepository
+	publisher domain.EventPublisher
+	cfg       RelayConfig
+}
+
+func NewRelay(
+	outbox domain.OutboxRepository,
+	publisher domain.EventPublisher,
+	cfg RelayConfig,
+) *Relay {
+	return &Relay{outbox: outbox, publisher: publisher, cfg: cfg}
+}
+
+// Run relays messages every PollInterval until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.ProcessBatch(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "outbox relay", "error", err)
+		}
+
+		if _, err := r.outbox.DeletePublished(ctx, r.cfg.Retention); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "outbox relay: prune", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch publishes one batch of due messages and returns how many were
+// published successfully.
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	messages, err := r.outbox.ClaimBatch(ctx, r.cfg.BatchSize, r.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+
+	for _, m := range messages {
+		if err := r.publisher.Publish(ctx, m.Event); err != nil {
+			retryIn := r.backoff(m.Attempts)
+			if err := r.outbox.MarkFailed(ctx, m.Event.ID, err.Error(), retryIn); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := r.outbox.MarkPublished(ctx, m.Event.ID); err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// backoff doubles BaseBackoff for every previous attempt, capped at MaxBackoff.
+func (r *Relay) backoff(previousAttempts int) time.Duration {
+	delay := r.cfg.BaseBackoff
+	for i := 0; i < previousAttempts; i++ {
+		delay *= 2
+		if delay >= r.cfg.MaxBackoff {
+			return r.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedis returns a Store on client whose keys all start with prefix, so
+// several services can share one Redis.
+func NewRedis(client redis.UniversalClient, prefix string) Store {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+func (s *redisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = s.prefix + key
+	}
+
+	return s.client.Del(ctx, prefixed...).Err()
+}
+
+// Counter and Incr seed a missing counter with the current time in
+// nanoseconds: a counter evicted by Redis comes back larger than before
+// instead of restarting at a value older entries were stored under.
+func (s *redisStore) Counter(ctx context.Context, key string) (int64, error) {
+	var get *redis.StringCmd
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, s.prefix+key, time.Now().UnixNano(), 0)
+		get = pipe.Get(ctx, s.prefix+key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return get.Int64()
+}
+
+func (s *redisStore) Incr(ctx context.Context, key string) (int64, error) {
+	var incr *redis.IntCmd
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, s.prefix+key, time.Now().UnixNano(), 0)
+		incr = pipe.Incr(ctx, s.prefix+key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
@@ -0,0 +1,133 @@
+// Package cache puts a read-through cache in front of a
+// domain.TaskRepository. Entries live in a Store: an in-process LRU or
+// Redis, which replicas share.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps encoded cache entries. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the value at key and whether it was found. Callers must
+	// not modify the returned slice.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Counter returns the counter at key. Counters only grow, also when a
+	// store loses them, and are used to version entries.
+	Counter(ctx context.Context, key string) (int64, error)
+	// Incr increments the counter at key and returns its new value.
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+type lruStore struct {
+	mu       sync.Mutex
+	size     int
+	order    *list.List
+	entries  map[string]*list.Element
+	counters map[string]int64
+	now      func() time.Time
+}
+
+// NewLRU returns an in-process Store holding at most size entries; the least
+// recently used one is evicted first. Counters are not entries and are never
+// evicted.
+func NewLRU(size int) Store {
+	return &lruStore{
+		size:     max(size, 1),
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		counters: make(map[string]int64),
+		now:      time.Now,
+	}
+}
+
+func (s *lruStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !s.now().Before(entry.expiresAt) {
+		s.remove(elem)
+		return nil, false, nil
+	}
+
+	s.order.MoveToFront(elem)
+
+	return entry.value, true, nil
+}
+
+func (s *lruStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &lruEntry{
+		key:       key,
+		value:     append([]byte(nil), value...),
+		expiresAt: s.now().Add(ttl),
+	}
+
+	if elem, ok := s.entries[key]; ok {
+		elem.Value = entry
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	s.entries[key] = s.order.PushFront(entry)
+
+	for s.order.Len() > s.size {
+		s.remove(s.order.Back())
+	}
+
+	return nil
+}
+
+func (s *lruStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := s.entries[key]; ok {
+			s.remove(elem)
+		}
+	}
+
+	return nil
+}
+
+func (s *lruStore) Counter(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.counters[key], nil
+}
+
+func (s *lruStore) Incr(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counters[key]++
+
+	return s.counters[key], nil
+}
+
+func (s *lruStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,122 @@
+package cache_test
+
+import (
+	"context"
+	"graph-task-service/internal/repository/cache"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedisStore(t *testing.T) (cache.Store, *miniredis.Miniredis) {
+	srv := miniredis.RunT(t)
+	// No retries, so tests of an unavailable store fail fast.
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	return cache.NewRedis(client, "test:"), srv
+}
+
+func TestStores(t *testing.T) {
+	for name, newStore := range map[string]func(t *testing.T) cache.Store{
+		"lru": func(*testing.T) cache.Store { return cache.NewLRU(10) },
+		"redis": func(t *testing.T) cache.Store {
+			store, _ := newRedisStore(t)
+			return store
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+
+			_, found, err := store.Get(ctx, "a")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			require.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+			require.NoError(t, store.Set(ctx, "b", []byte("2"), time.Minute))
+
+			value, found, err := store.Get(ctx, "a")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "1", string(value))
+
+			require.NoError(t, store.Delete(ctx, "a", "b", "missing"))
+			_, found, err = store.Get(ctx, "b")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			start, err := store.Counter(ctx, "gen")
+			require.NoError(t, err)
+
+			next, err := store.Incr(ctx, "gen")
+			require.NoError(t, err)
+			assert.Equal(t, start+1, next)
+
+			current, err := store.Counter(ctx, "gen")
+			require.NoError(t, err)
+			assert.Equal(t, next, current)
+		})
+	}
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewLRU(2)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("a"), time.Minute))
+	require.NoError(t, store.Set(ctx, "b", []byte("b"), time.Minute))
+
+	_, _, _ = store.Get(ctx, "a")
+	require.NoError(t, store.Set(ctx, "c", []byte("c"), time.Minute))
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		_, found, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, want, found, key)
+	}
+}
+
+func TestLRU_Expires(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewLRU(10)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("a"), 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+
+	_, found, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestRedis_Expires(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newRedisStore(t)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("a"), time.Minute))
+	assert.True(t, srv.Exists("test:a"), "keys are prefixed")
+
+	srv.FastForward(2 * time.Minute)
+
+	_, found, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestRedis_CounterGrowsAfterEviction(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newRedisStore(t)
+
+	before, err := store.Incr(ctx, "gen")
+	require.NoError(t, err)
+
+	srv.FlushAll()
+
+	after, err := store.Counter(ctx, "gen")
+	require.NoError(t, err)
+	assert.Greater(t, after, before)
+}
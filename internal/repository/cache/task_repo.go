@@ -0,0 +1,407 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/logging"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache names reported to Metrics.
+const (
+	CacheTask = "task"
+	CacheList = "task_list"
+)
+
+// listGeneration versions cached lists. Every write increments it, so lists
+// cached before the write are no longer looked up and expire on their own.
+const listGeneration = "tasks:list:generation"
+
+// taskGeneration is incremented by every write that evicts tasks. A task
+// load that sees it change may have read the task before the write and
+// raced the eviction, so its entry is dropped again.
+const taskGeneration = "tasks:generation"
+
+// Metrics counts cache lookups.
+type Metrics interface {
+	CacheHit(cache string)
+	CacheMiss(cache string)
+}
+
+type Options struct {
+	// TTL bounds how long a task is cached, ListTTL how long a list is.
+	// They also bound staleness caused by writes the cache does not see,
+	// e.g. from other replicas sharing an in-process store.
+	TTL     time.Duration
+	ListTTL time.Duration
+	// InTx reports whether ctx carries a unit of work. Its reads go to the
+	// repository, so they see its own writes and are not cached.
+	InTx func(ctx context.Context) bool
+	// AfterCommit defers fn until the unit of work of ctx has committed;
+	// invalidating earlier would let a concurrent read cache the previous
+	// version again. Without it fn runs right away.
+	AfterCommit func(ctx context.Context, fn func())
+	Metrics     Metrics
+}
+
+type taskRepository struct {
+	next   domain.TaskRepository
+	store  Store
+	opts   Options
+	flight singleflight.Group
+}
+
+// NewTaskRepository caches GetByID, GetByIDs and List of next in store. Concurrent
+// misses of the same key share one load. Update and Delete evict the task,
+// and every write invalidates all cached lists. Store errors are logged and
+// the call goes to next, so an unavailable cache only costs latency.
+func NewTaskRepository(next domain.TaskRepository, store Store, opts Options) domain.TaskRepository {
+	if opts.InTx == nil {
+		opts.InTx = func(context.Context) bool { return false }
+	}
+	if opts.AfterCommit == nil {
+		opts.AfterCommit = func(_ context.Context, fn func()) { fn() }
+	}
+	if opts.Metrics == nil {
+		opts.Metrics = noMetrics{}
+	}
+
+	return &taskRepository{next: next, store: store, opts: opts}
+}
+
+func (r *taskRepository) Create(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	created, err := r.next.Create(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(ctx)
+
+	return created, nil
+}
+
+func (r *taskRepository) CreateMany(ctx context.Context, tasks []*domain.Task) ([]*domain.Task, error) {
+	created, err := domain.CreateTasks(ctx, r.next, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(ctx)
+
+	return created, nil
+}
+
+func (r *taskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	if r.opts.InTx(ctx) {
+		return r.next.GetByID(ctx, id)
+	}
+
+	var task *domain.Task
+
+	err := r.cached(ctx, CacheTask, taskKey(id), r.opts.TTL, taskGeneration, &task, func(ctx context.Context) (any, error) {
+		return r.next.GetByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// GetByIDs serves the cached tasks and loads the others from next in one
+// batch, caching them like GetByID.
+func (r *taskRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Task, error) {
+	if r.opts.InTx(ctx) {
+		return domain.GetTasks(ctx, r.next, ids)
+	}
+
+	var (
+		tasks  []*domain.Task
+		misses []string
+		seen   = make(map[string]bool, len(ids))
+	)
+
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		value, found, err := r.store.Get(ctx, taskKey(id))
+		if err != nil {
+			r.storeFailed(ctx, "get", err)
+		}
+
+		var task *domain.Task
+		if found && json.Unmarshal(value, &task) == nil {
+			r.opts.Metrics.CacheHit(CacheTask)
+			tasks = append(tasks, task)
+			continue
+		}
+
+		r.opts.Metrics.CacheMiss(CacheTask)
+		misses = append(misses, id)
+	}
+
+	if len(misses) == 0 {
+		return tasks, nil
+	}
+
+	generation, counted := r.counter(ctx, taskGeneration)
+
+	loaded, err := domain.GetTasks(ctx, r.next, misses)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(loaded))
+
+	for _, task := range loaded {
+		tasks = append(tasks, task)
+		if !counted {
+			continue
+		}
+
+		value, err := json.Marshal(task)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.store.Set(ctx, taskKey(task.ID), value, r.opts.TTL); err != nil {
+			r.storeFailed(ctx, "set", err)
+		}
+		keys = append(keys, taskKey(task.ID))
+	}
+
+	if counted {
+		r.evictIfChanged(ctx, taskGeneration, generation, keys...)
+	}
+
+	return tasks, nil
+}
+
+func (r *taskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	if r.opts.InTx(ctx) {
+		return r.next.List(ctx, filter)
+	}
+
+	generation, err := r.store.Counter(ctx, listGeneration)
+	if err != nil {
+		r.storeFailed(ctx, "read list generation", err)
+		return r.next.List(ctx, filter)
+	}
+
+	key := "tasks:list:" + strconv.FormatInt(generation, 10) + ":" + FilterKey(filter)
+
+	var tasks []*domain.Task
+
+	err = r.cached(ctx, CacheList, key, r.opts.ListTTL, "", &tasks, func(ctx context.Context) (any, error) {
+		return r.next.List(ctx, filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// Stream reads through: streamed results are too large to cache.
+func (r *taskRepository) Stream(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error {
+	return domain.StreamTasks(ctx, r.next, filter, fn)
+}
+
+func (r *taskRepository) Update(ctx context.Context, task *domain.Task) error {
+	if err := r.next.Update(ctx, task); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, task.ID)
+
+	return nil
+}
+
+func (r *taskRepository) Delete(ctx context.Context, id string) error {
+	if err := r.next.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, id)
+
+	return nil
+}
+
+// cached decodes the entry at key into dest. On a miss, load runs once for
+// all concurrent callers and its result is stored for ttl. Errors of load,
+// such as domain.ErrNotFound, are not cached. When generation is set, the
+// result is only kept if that counter did not change during the load.
+func (r *taskRepository) cached(
+	ctx context.Context,
+	cache, key string,
+	ttl time.Duration,
+	generation string,
+	dest any,
+	load func(ctx context.Context) (any, error),
+) error {
+
+	value, found, err := r.store.Get(ctx, key)
+	if err != nil {
+		r.storeFailed(ctx, "get", err)
+	}
+	if found && json.Unmarshal(value, dest) == nil {
+		r.opts.Metrics.CacheHit(cache)
+		return nil
+	}
+
+	r.opts.Metrics.CacheMiss(cache)
+
+	ch := r.flight.DoChan(key, func() (any, error) {
+		// The load is shared, so one caller giving up must not cancel it
+		// for the others.
+		ctx := context.WithoutCancel(ctx)
+
+		before, counted := int64(0), true
+		if generation != "" {
+			before, counted = r.counter(ctx, generation)
+		}
+
+		result, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+
+		if !counted {
+			return value, nil
+		}
+
+		if err := r.store.Set(ctx, key, value, ttl); err != nil {
+			r.storeFailed(ctx, "set", err)
+		}
+
+		if generation != "" {
+			r.evictIfChanged(ctx, generation, before, key)
+		}
+
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return res.Err
+		}
+		// Every caller decodes its own copy of the shared result.
+		return json.Unmarshal(res.Val.([]byte), dest)
+	}
+}
+
+// counter reads the counter at key. It reports false if the store failed,
+// in which case nothing guarded by the counter may be cached.
+func (r *taskRepository) counter(ctx context.Context, key string) (int64, bool) {
+	value, err := r.store.Counter(ctx, key)
+	if err != nil {
+		r.storeFailed(ctx, "read generation", err)
+		return 0, false
+	}
+	return value, true
+}
+
+// evictIfChanged deletes keys, just stored from a load that started when
+// the counter at generation was before, if a write has since incremented
+// it: the write's eviction may have run before the entries were stored.
+func (r *taskRepository) evictIfChanged(ctx context.Context, generation string, before int64, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	after, ok := r.counter(ctx, generation)
+	if ok && after == before {
+		return
+	}
+
+	if err := r.store.Delete(ctx, keys...); err != nil {
+		r.storeFailed(ctx, "delete", err)
+	}
+}
+
+// invalidate evicts the tasks with ids and all cached lists once the write
+// is committed. The task generation is incremented before the eviction, so
+// a load storing a task after the eviction sees it changed.
+func (r *taskRepository) invalidate(ctx context.Context, ids ...string) {
+	r.opts.AfterCommit(ctx, func() {
+		ctx := context.WithoutCancel(ctx)
+
+		if len(ids) > 0 {
+			if _, err := r.store.Incr(ctx, taskGeneration); err != nil {
+				r.storeFailed(ctx, "bump task generation", err)
+			}
+		}
+
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = taskKey(id)
+			// Later reads must not join a load that started before the write.
+			r.flight.Forget(keys[i])
+		}
+
+		if err := r.store.Delete(ctx, keys...); err != nil {
+			r.storeFailed(ctx, "delete", err)
+		}
+
+		if _, err := r.store.Incr(ctx, listGeneration); err != nil {
+			r.storeFailed(ctx, "bump list generation", err)
+		}
+	})
+}
+
+func (r *taskRepository) storeFailed(ctx context.Context, op string, err error) {
+	logging.FromContext(ctx).WarnContext(ctx, "task cache unavailable", "operation", op, "error", err)
+}
+
+func taskKey(id string) string {
+	return "task:" + id
+}
+
+// FilterKey identifies the result of filter: filters selecting the same
+// tasks in the same order have the same key.
+func FilterKey(filter domain.TaskFilter) string {
+	values := url.Values{}
+
+	if filter.Status != nil {
+		values.Set("status", string(*filter.Status))
+	}
+	if filter.Assignee != nil {
+		values.Set("assignee", *filter.Assignee)
+	}
+	if filter.Watcher != nil {
+		values.Set("watcher", *filter.Watcher)
+	}
+	if filter.Participant != nil {
+		values.Set("participant", *filter.Participant)
+	}
+	// Repositories treat a limit or offset below one as none.
+	if filter.Limit > 0 {
+		values.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		values.Set("offset", strconv.Itoa(filter.Offset))
+	}
+
+	// Encode sorts by name.
+	return values.Encode()
+}
+
+type noMetrics struct{}
+
+func (noMetrics) CacheHit(string)  {}
+func (noMetrics) CacheMiss(string) {}
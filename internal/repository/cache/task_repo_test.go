@@ -0,0 +1,364 @@
+package cache_test
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/repository/cache"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/repository/repotest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOptions = cache.Options{TTL: time.Minute, ListTTL: time.Minute}
+
+// countingRepo counts the reads reaching the wrapped repository. When gate
+// is set, GetByID waits for it to close.
+type countingRepo struct {
+	domain.TaskRepository
+	gets  atomic.Int32
+	lists atomic.Int32
+	gate  chan struct{}
+}
+
+func (r *countingRepo) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	r.gets.Add(1)
+	if r.gate != nil {
+		<-r.gate
+	}
+	return r.TaskRepository.GetByID(ctx, id)
+}
+
+func (r *countingRepo) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	r.lists.Add(1)
+	return r.TaskRepository.List(ctx, filter)
+}
+
+// slowLoadRepo reads the task of the first GetByID, then signals read and
+// waits for gate before returning it, like a load that read a row just
+// before a concurrent write committed.
+type slowLoadRepo struct {
+	domain.TaskRepository
+	calls atomic.Int32
+	read  chan struct{}
+	gate  chan struct{}
+}
+
+func (r *slowLoadRepo) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	task, err := r.TaskRepository.GetByID(ctx, id)
+	if r.calls.Add(1) == 1 {
+		close(r.read)
+		<-r.gate
+	}
+	return task, err
+}
+
+type countingMetrics struct {
+	mu     sync.Mutex
+	hits   map[string]int
+	misses map[string]int
+}
+
+func newCountingMetrics() *countingMetrics {
+	return &countingMetrics{hits: map[string]int{}, misses: map[string]int{}}
+}
+
+func (m *countingMetrics) CacheHit(c string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hits[c]++
+}
+
+func (m *countingMetrics) CacheMiss(c string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.misses[c]++
+}
+
+func TestTaskRepository_Conformance(t *testing.T) {
+	t.Run("lru", func(t *testing.T) {
+		repotest.TestTaskRepository(t, func(t *testing.T) domain.TaskRepository {
+			return cache.NewTaskRepository(memory.NewTaskRepository(), cache.NewLRU(100), testOptions)
+		})
+	})
+
+	t.Run("redis", func(t *testing.T) {
+		repotest.TestTaskRepository(t, func(t *testing.T) domain.TaskRepository {
+			store, _ := newRedisStore(t)
+			return cache.NewTaskRepository(memory.NewTaskRepository(), store, testOptions)
+		})
+	})
+}
+
+func TestTaskRepository_GetByID(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepo{TaskRepository: memory.NewTaskRepository()}
+	metrics := newCountingMetrics()
+
+	opts := testOptions
+	opts.Metrics = metrics
+	repo := cache.NewTaskRepository(next, cache.NewLRU(100), opts)
+
+	created, err := repo.Create(ctx, &domain.Task{Title: "cached", Status: domain.StatusTodo})
+	require.NoError(t, err)
+
+	for range 3 {
+		found, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "cached", found.Title)
+	}
+
+	assert.EqualValues(t, 1, next.gets.Load())
+	assert.Equal(t, 2, metrics.hits[cache.CacheTask])
+	assert.Equal(t, 1, metrics.misses[cache.CacheTask])
+
+	created.Title = "updated"
+	require.NoError(t, repo.Update(ctx, created))
+
+	found, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", found.Title, "Update evicts the task")
+
+	require.NoError(t, repo.Delete(ctx, created.ID))
+
+	_, err = repo.GetByID(ctx, created.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound, "Delete evicts the task")
+
+	_, err = repo.GetByID(ctx, created.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.EqualValues(t, 4, next.gets.Load(), "not found is not cached")
+}
+
+func TestTaskRepository_LoadRacingUpdateIsNotCached(t *testing.T) {
+	ctx := context.Background()
+	next := &slowLoadRepo{
+		TaskRepository: memory.NewTaskRepository(),
+		read:           make(chan struct{}),
+		gate:           make(chan struct{}),
+	}
+	repo := cache.NewTaskRepository(next, cache.NewLRU(100), testOptions)
+
+	created, err := repo.Create(ctx, &domain.Task{Title: "before", Status: domain.StatusTodo})
+	require.NoError(t, err)
+
+	loaded := make(chan *domain.Task)
+	go func() {
+		task, err := repo.GetByID(ctx, created.ID)
+		assert.NoError(t, err)
+		loaded <- task
+	}()
+
+	<-next.read
+	created.Title = "after"
+	require.NoError(t, repo.Update(ctx, created))
+	close(next.gate)
+
+	assert.Equal(t, "before", (<-loaded).Title)
+
+	found, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "after", found.Title, "the racing load does not cache its stale task")
+}
+
+func TestTaskRepository_GetByIDs(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepo{TaskRepository: memory.NewTaskRepository()}
+	repo := cache.NewTaskRepository(next, cache.NewLRU(100), testOptions)
+
+	first, err := repo.Create(ctx, &domain.Task{Title: "first", Status: domain.StatusTodo})
+	require.NoError(t, err)
+	second, err := repo.Create(ctx, &domain.Task{Title: "second", Status: domain.StatusTodo})
+	require.NoError(t, err)
+
+	_, err = repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+
+	tasks, err := domain.GetTasks(ctx, repo, []string{first.ID, second.ID, "missing"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"first", "second"}, []string{tasks[0].Title, tasks[1].Title})
+	assert.EqualValues(t, 3, next.gets.Load(), "only the misses reach next")
+
+	tasks, err = domain.GetTasks(ctx, repo, []string{first.ID, second.ID})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+	assert.EqualValues(t, 3, next.gets.Load(), "loaded tasks are cached")
+}
+
+func TestTaskRepository_List(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepo{TaskRepository: memory.NewTaskRepository()}
+	store, _ := newRedisStore(t)
+	repo := cache.NewTaskRepository(next, store, testOptions)
+
+	todo := domain.StatusTodo
+	alice := "alice"
+
+	created, err := repo.Create(ctx, &domain.Task{Title: "one", Status: todo, Assignees: []string{alice}})
+	require.NoError(t, err)
+
+	list := func(filter domain.TaskFilter) []*domain.Task {
+		tasks, err := repo.List(ctx, filter)
+		require.NoError(t, err)
+		return tasks
+	}
+
+	assert.Len(t, list(domain.TaskFilter{Status: &todo}), 1)
+	// Another pointer to the same value and a negative offset select the
+	// same tasks, so they share the cached result.
+	sameTodo := domain.StatusTodo
+	assert.Len(t, list(domain.TaskFilter{Status: &sameTodo, Offset: -1}), 1)
+	assert.EqualValues(t, 1, next.lists.Load())
+
+	assert.Len(t, list(domain.TaskFilter{Assignee: &alice}), 1)
+	assert.EqualValues(t, 2, next.lists.Load(), "other filters are cached apart")
+
+	_, err = repo.Create(ctx, &domain.Task{Title: "two", Status: todo})
+	require.NoError(t, err)
+	assert.Len(t, list(domain.TaskFilter{Status: &todo}), 2, "Create invalidates lists")
+
+	created.Status = domain.StatusDone
+	require.NoError(t, repo.Update(ctx, created))
+	assert.Len(t, list(domain.TaskFilter{Status: &todo}), 1, "Update invalidates lists")
+
+	require.NoError(t, repo.Delete(ctx, created.ID))
+	assert.Empty(t, list(domain.TaskFilter{Assignee: &alice}), "Delete invalidates lists")
+
+	_, err = domain.CreateTasks(ctx, repo, []*domain.Task{{Title: "three", Status: todo}, {Title: "four", Status: todo}})
+	require.NoError(t, err)
+	assert.Len(t, list(domain.TaskFilter{Status: &todo}), 3, "CreateMany invalidates lists")
+}
+
+func TestTaskRepository_ConcurrentMissesLoadOnce(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepo{TaskRepository: memory.NewTaskRepository()}
+	repo := cache.NewTaskRepository(next, cache.NewLRU(100), testOptions)
+
+	created, err := next.Create(ctx, &domain.Task{Title: "hot", Status: domain.StatusTodo})
+	require.NoError(t, err)
+
+	next.gate = make(chan struct{})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan *domain.Task, callers)
+
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			task, err := repo.GetByID(ctx, created.ID)
+			assert.NoError(t, err)
+			results <- task
+		}()
+	}
+
+	// Let every caller reach the shared load before it completes.
+	require.Eventually(t, func() bool { return next.gets.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(next.gate)
+
+	wg.Wait()
+	close(results)
+
+	assert.EqualValues(t, 1, next.gets.Load())
+
+	var tasks []*domain.Task
+	for task := range results {
+		tasks = append(tasks, task)
+	}
+	require.Len(t, tasks, callers)
+	assert.NotSame(t, tasks[0], tasks[1], "callers get their own copy")
+}
+
+func TestTaskRepository_UnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepo{TaskRepository: memory.NewTaskRepository()}
+
+	var pending []func()
+	inTx := func(ctx context.Context) bool { return ctx.Value(txKey{}) != nil }
+
+	opts := testOptions
+	opts.InTx = inTx
+	opts.AfterCommit = func(ctx context.Context, fn func()) {
+		if inTx(ctx) {
+			pending = append(pending, fn)
+			return
+		}
+		fn()
+	}
+	repo := cache.NewTaskRepository(next, cache.NewLRU(100), opts)
+
+	created, err := repo.Create(ctx, &domain.Task{Title: "before", Status: domain.StatusTodo})
+	require.NoError(t, err)
+
+	_, err = repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+
+	txCtx := context.WithValue(ctx, txKey{}, true)
+
+	_, err = repo.GetByID(txCtx, created.ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, next.gets.Load(), "reads in a unit of work bypass the cache")
+
+	created.Title = "after"
+	require.NoError(t, repo.Update(txCtx, created))
+	require.Len(t, pending, 1, "invalidation waits for the commit")
+
+	found, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "before", found.Title, "uncommitted writes keep the cache")
+
+	pending[0]()
+
+	found, err = repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "after", found.Title)
+}
+
+func TestTaskRepository_StoreUnavailable(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newRedisStore(t)
+	repo := cache.NewTaskRepository(memory.NewTaskRepository(), store, testOptions)
+
+	created, err := repo.Create(ctx, &domain.Task{Title: "resilient", Status: domain.StatusTodo})
+	require.NoError(t, err)
+
+	srv.Close()
+
+	found, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "resilient", found.Title)
+
+	tasks, err := repo.List(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	created.Title = "still works"
+	require.NoError(t, repo.Update(ctx, created))
+}
+
+func TestFilterKey(t *testing.T) {
+	todo := domain.StatusTodo
+	alice := "alice"
+	empty := ""
+
+	assert.Equal(t, "", cache.FilterKey(domain.TaskFilter{Limit: -5, Offset: 0}))
+	assert.Equal(t, "limit=10&status=todo", cache.FilterKey(domain.TaskFilter{Status: &todo, Limit: 10}))
+	assert.Equal(t, "assignee=alice&offset=3", cache.FilterKey(domain.TaskFilter{Assignee: &alice, Offset: 3}))
+	assert.NotEqual(t,
+		cache.FilterKey(domain.TaskFilter{}),
+		cache.FilterKey(domain.TaskFilter{Watcher: &empty}),
+		"an empty user is a filter",
+	)
+	assert.NotEqual(t,
+		cache.FilterKey(domain.TaskFilter{Watcher: &alice}),
+		cache.FilterKey(domain.TaskFilter{Participant: &alice}),
+	)
+}
+
+type txKey struct{}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"sync"
+)
+
+type refKey struct {
+	source     string
+	externalID string
+}
+
+type externalRefRepository struct {
+	mu   sync.Mutex
+	refs map[refKey]string
+}
+
+// NewExternalRefRepository keeps the links of imported items in memory.
+// Unlike the database repositories it keeps links to deleted tasks; the
+// import recreates such tasks either way.
+func NewExternalRefRepository() domain.ExternalRefRepository {
+	return &externalRefRepository{refs: make(map[refKey]string)}
+}
+
+func (r *externalRefRepository) Get(
+	ctx context.Context,
+	source, externalID string,
+) (*domain.ExternalRef, error) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taskID, ok := r.refs[refKey{source, externalID}]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	return &domain.ExternalRef{Source: source, ExternalID: externalID, TaskID: taskID}, nil
+}
+
+// Lock does nothing: there are no units of work in memory.
+func (r *externalRefRepository) Lock(
+	ctx context.Context,
+	source string,
+) error {
+
+	return nil
+}
+
+func (r *externalRefRepository) Put(
+	ctx context.Context,
+	ref *domain.ExternalRef,
+) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refs[refKey{ref.Source, ref.ExternalID}] = ref.TaskID
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/repository/repotest"
+	"testing"
+)
+
+func TestExternalRefRepository_Conformance(t *testing.T) {
+	repotest.TestExternalRefRepository(t, func(t *testing.T) (domain.ExternalRefRepository, domain.TaskRepository) {
+		return memory.NewExternalRefRepository(), memory.NewTaskRepository()
+	})
+}
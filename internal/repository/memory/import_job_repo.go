@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type importJobRepository struct {
+	mu   sync.Mutex
+	jobs map[string]domain.ImportJob
+}
+
+// NewImportJobRepository keeps import jobs in memory, so they are known to
+// the instance that runs them only and forgotten on restart. It hands out
+// copies.
+func NewImportJobRepository() domain.ImportJobRepository {
+	return &importJobRepository{jobs: make(map[string]domain.ImportJob)}
+}
+
+func (r *importJobRepository) Create(
+	ctx context.Context,
+	job *domain.ImportJob,
+) (*domain.ImportJob, error) {
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	job.ID = uuid.NewString()
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs[job.ID] = cloneImportJob(job)
+
+	return job, nil
+}
+
+func (r *importJobRepository) GetByID(
+	ctx context.Context,
+	id string,
+) (*domain.ImportJob, error) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.jobs[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	job := cloneImportJob(&stored)
+	return &job, nil
+}
+
+func (r *importJobRepository) Update(
+	ctx context.Context,
+	job *domain.ImportJob,
+) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.jobs[job.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	job.CreatedAt = stored.CreatedAt
+	job.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	r.jobs[job.ID] = cloneImportJob(job)
+
+	return nil
+}
+
+func (r *importJobRepository) Touch(
+	ctx context.Context,
+	id string,
+) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.jobs[id]
+	if ok && unfinished(stored.Status) {
+		stored.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		r.jobs[id] = stored
+	}
+
+	return nil
+}
+
+func (r *importJobRepository) FailStale(
+	ctx context.Context,
+	olderThan time.Duration,
+	reason string,
+) (int64, error) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	var failed int64
+	for id, job := range r.jobs {
+		if !unfinished(job.Status) || !job.UpdatedAt.Before(now.Add(-olderThan)) {
+			continue
+		}
+
+		msg := reason
+		job.Status = domain.ImportFailed
+		job.Error = &msg
+		job.FinishedAt = &now
+		job.UpdatedAt = now
+		r.jobs[id] = job
+		failed++
+	}
+
+	return failed, nil
+}
+
+func unfinished(status domain.ImportStatus) bool {
+	return status == domain.ImportPending || status == domain.ImportRunning
+}
+
+func cloneImportJob(job *domain.ImportJob) domain.ImportJob {
+	c := *job
+	c.Result.Errors = slices.Clone(job.Result.Errors)
+	if job.Error != nil {
+		msg := *job.Error
+		c.Error = &msg
+	}
+	if job.FinishedAt != nil {
+		at := *job.FinishedAt
+		c.FinishedAt = &at
+	}
+	return c
+}
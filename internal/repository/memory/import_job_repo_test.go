@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/repository/repotest"
+	"testing"
+)
+
+func TestImportJobRepository_Conformance(t *testing.T) {
+	repotest.TestImportJobRepository(t, func(t *testing.T) domain.ImportJobRepository {
+		return memory.NewImportJobRepository()
+	})
+}
@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type storedTask struct {
+	task domain.Task
+	// seq orders tasks created within the same microsecond.
+	seq int64
+}
+
+type taskRepository struct {
+	mu    sync.RWMutex
+	tasks map[string]*storedTask
+	seq   int64
+}
+
+// NewTaskRepository keeps tasks in memory with the semantics of the
+// Postgres repository: newest first, domain.ErrNotFound for unknown ids and
+// timestamps at microsecond precision. It is safe for concurrent use and
+// hands out copies, so callers never share state with the store. Task
+// events are not recorded. It also implements domain.TaskCounter and
+// domain.TaskBatchGetter.
+func NewTaskRepository() domain.TaskRepository {
+	return &taskRepository{tasks: make(map[string]*storedTask)}
+}
+
+func (r *taskRepository) Create(
+	ctx context.Context,
+	task *domain.Task,
+) (*domain.Task, error) {
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	task.ID = uuid.NewString()
+	task.Assignees = cloneUsers(task.Assignees)
+	task.Watchers = cloneUsers(task.Watchers)
+	task.CreatedAt = now
+	task.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	r.tasks[task.ID] = &storedTask{task: cloneTask(task), seq: r.seq}
+
+	return task, nil
+}
+
+func (r *taskRepository) GetByID(
+	ctx context.Context,
+	id string,
+) (*domain.Task, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.tasks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	task := cloneTask(&stored.task)
+	return &task, nil
+}
+
+func (r *taskRepository) GetByIDs(
+	ctx context.Context,
+	ids []string,
+) ([]*domain.Task, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*domain.Task, 0, len(ids))
+	for _, id := range uniqueIDs(ids) {
+		if stored, ok := r.tasks[id]; ok {
+			task := cloneTask(&stored.task)
+			tasks = append(tasks, &task)
+		}
+	}
+
+	return tasks, nil
+}
+
+func (r *taskRepository) List(
+	ctx context.Context,
+	filter domain.TaskFilter,
+) ([]*domain.Task, error) {
+
+	r.mu.RLock()
+	matches := make([]*storedTask, 0, len(r.tasks))
+	for _, stored := range r.tasks {
+		if filter.Matches(&stored.task) {
+			matches = append(matches, stored)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(matches, func(a, b *storedTask) int {
+		if c := b.task.CreatedAt.Compare(a.task.CreatedAt); c != 0 {
+			return c
+		}
+		return int(b.seq - a.seq)
+	})
+
+	if filter.Offset > 0 {
+		matches = matches[min(filter.Offset, len(matches)):]
+	}
+	if filter.Limit > 0 {
+		matches = matches[:min(filter.Limit, len(matches))]
+	}
+
+	var tasks []*domain.Task
+	for _, stored := range matches {
+		task := cloneTask(&stored.task)
+		tasks = append(tasks, &task)
+	}
+
+	return tasks, nil
+}
+
+func (r *taskRepository) Update(
+	ctx context.Context,
+	task *domain.Task,
+) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tasks[task.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	task.Assignees = cloneUsers(task.Assignees)
+	task.Watchers = cloneUsers(task.Watchers)
+	task.CreatedAt = stored.task.CreatedAt
+	task.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	stored.task = cloneTask(task)
+
+	return nil
+}
+
+func (r *taskRepository) Delete(
+	ctx context.Context,
+	id string,
+) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[id]; !ok {
+		return domain.ErrNotFound
+	}
+
+	delete(r.tasks, id)
+
+	return nil
+}
+
+func (r *taskRepository) CountByStatus(
+	ctx context.Context,
+) (map[domain.TaskStatus]int, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[domain.TaskStatus]int)
+	for _, stored := range r.tasks {
+		counts[stored.task.Status]++
+	}
+
+	return counts, nil
+}
+
+// uniqueIDs returns ids without duplicates.
+func uniqueIDs(ids []string) []string {
+	unique := slices.Clone(ids)
+	slices.Sort(unique)
+	return slices.Compact(unique)
+}
+
+func cloneTask(task *domain.Task) domain.Task {
+	clone := *task
+	clone.Assignees = cloneUsers(task.Assignees)
+	clone.Watchers = cloneUsers(task.Watchers)
+	return clone
+}
+
+// cloneUsers copies users and, like the NOT NULL array columns of the
+// Postgres schema, turns nil into an empty list.
+func cloneUsers(users []string) []string {
+	return append([]string{}, users...)
+}
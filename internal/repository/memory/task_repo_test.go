@@ -0,0 +1,32 @@
+package memory_test
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/repository/repotest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskRepository_Conformance(t *testing.T) {
+	repotest.TestTaskRepository(t, func(t *testing.T) domain.TaskRepository {
+		return memory.NewTaskRepository()
+	})
+}
+
+func TestTaskRepository_CountByStatus(t *testing.T) {
+	repo := memory.NewTaskRepository()
+	ctx := context.Background()
+
+	for _, status := range []domain.TaskStatus{domain.StatusTodo, domain.StatusTodo, domain.StatusDone} {
+		_, err := repo.Create(ctx, &domain.Task{Title: "t", Status: status})
+		require.NoError(t, err)
+	}
+
+	counts, err := repo.(domain.TaskCounter).CountByStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[domain.TaskStatus]int{domain.StatusTodo: 2, domain.StatusDone: 1}, counts)
+}
@@ -1,20 +1,128 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"log/slog"
+	"strconv"
+	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
-func NewDB(dbURL string) (*sql.DB, error) {
-	db, err := sql.Open("pgx", dbURL)
+// DBConfig tunes the connection pool with database/sql semantics (zero means
+// unlimited, except for MaxIdleConns where it keeps no idle connection) and
+// how NewDB waits for the database to come up.
+type DBConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// StatementTimeout makes Postgres cancel any statement running longer.
+	// Zero disables it.
+	StatementTimeout time.Duration
+
+	// ConnectAttempts is how often NewDB tries to reach the database,
+	// waiting ConnectBackoff after the first failure and doubling the wait
+	// up to maxConnectBackoff.
+	ConnectAttempts int
+	ConnectBackoff  time.Duration
+}
+
+const maxConnectBackoff = 10 * time.Second
+
+func NewDB(ctx context.Context, dbURL string, cfg DBConfig) (*sql.DB, error) {
+	connConfig, err := pgx.ParseConfig(dbURL)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := db.Ping(); err != nil {
+	if cfg.StatementTimeout > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	db := stdlib.OpenDB(*connConfig)
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if err := ping(ctx, db.PingContext, cfg.ConnectAttempts, cfg.ConnectBackoff); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
+
+// NewPool opens a native pgx connection pool, used by the pgx task
+// repository. MaxIdleConns has no pgxpool counterpart and is ignored.
+func NewPool(ctx context.Context, dbURL string, cfg DBConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxOpenConns > 0 {
+		poolConfig.MaxConns = int32(cfg.MaxOpenConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
+	}
+	if cfg.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ping(ctx, pool.Ping, cfg.ConnectAttempts, cfg.ConnectBackoff); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+// ping retries until the database answers, so the service survives
+// Postgres starting a few seconds after it.
+func ping(ctx context.Context, pingFn func(context.Context) error, attempts int, backoff time.Duration) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = pingFn(ctx); err == nil {
+			return nil
+		}
+
+		if attempt >= attempts {
+			return err
+		}
+
+		slog.WarnContext(ctx, "database not reachable",
+			"attempt", attempt,
+			"max_attempts", attempts,
+			"retry_in", backoff,
+			"error", err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
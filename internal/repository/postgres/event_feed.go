@@ -0,0 +1,270 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"graph-task-service/internal/domain"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// eventsChannel is the LISTEN/NOTIFY channel carrying outbox sequence numbers.
+const eventsChannel = "task_events"
+
+// feedPageSize is how many events the feed reads from the outbox at once.
+const feedPageSize = 1000
+
+// gapRecheckInterval is how often Listen looks again at a gap in seq that
+// a running transaction may still fill, unless a notification comes first.
+const gapRecheckInterval = 200 * time.Millisecond
+
+// eventFeed hands out outbox events in seq order. seq is drawn when an
+// event is inserted, not when it commits, so events commit out of seq order
+// and rolled back inserts leave gaps for good. An event past a gap is held
+// back until the gap is final: a writer holding a missing seq drew it
+// before the event past the gap committed, so the gap is final once every
+// transaction running when it was seen has ended.
+type eventFeed struct {
+	db    *sql.DB
+	dbURL string
+	// settled is the seq up to which Listen has handed out every event; the
+	// gaps below it are final.
+	settled atomic.Int64
+}
+
+// NewEventFeed returns a feed that LISTENs on a dedicated connection opened
+// from dbURL and loads event bodies from the outbox through db.
+func NewEventFeed(db *sql.DB, dbURL string) domain.TaskEventFeed {
+	return &eventFeed{db: db, dbURL: dbURL}
+}
+
+// snapshot holds the bounds of the transaction ids of a snapshot: every
+// transaction below xmin has ended, none from xmax on had started.
+type snapshot struct {
+	xmin, xmax int64
+}
+
+// cursor is where Listen is in the outbox.
+type cursor struct {
+	// seq is the last seq handed out.
+	seq int64
+	// final is the seq below which missing seqs are known to be final.
+	final int64
+	// Missing seqs below pendingBelow are final once the xmin of a snapshot
+	// reaches pendingXmax, the xmax of the snapshot they were missing in.
+	// pendingBelow is 0 while no gap is waited for.
+	pendingBelow int64
+	pendingXmax  int64
+	// startXmax is the xmax of the snapshot Listen started in; until it
+	// has passed, events below seq may still commit.
+	startXmax int64
+}
+
+func (c *cursor) waiting() bool {
+	return c.pendingBelow > 0 || c.startXmax > 0
+}
+
+func (f *eventFeed) Listen(
+	ctx context.Context,
+	handle func(*domain.SequencedEvent),
+) error {
+
+	c, err := f.start(ctx)
+	if err != nil {
+		return err
+	}
+
+	backoff := time.Second
+
+	for {
+		err := f.listen(ctx, &c, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		slog.WarnContext(ctx, "event feed disconnected", "error", err, "retry_in", backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// start places the cursor at the newest event, so only events committed
+// from now on are handed out.
+func (f *eventFeed) start(ctx context.Context) (cursor, error) {
+	var c cursor
+	err := f.db.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(seq), 0), pg_snapshot_xmax(pg_current_snapshot())::text::bigint
+		FROM outbox_events
+	`).Scan(&c.seq, &c.startXmax)
+
+	return c, err
+}
+
+// listen runs one LISTEN session. Notifications only wake it up: events are
+// read from the outbox after the cursor, so after (re)connecting it first
+// catches up on everything committed while it was not listening.
+func (f *eventFeed) listen(
+	ctx context.Context,
+	c *cursor,
+	handle func(*domain.SequencedEvent),
+) error {
+
+	conn, err := pgx.Connect(ctx, f.dbURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+eventsChannel); err != nil {
+		return err
+	}
+
+	for {
+		if err := f.advance(ctx, c, handle); err != nil {
+			return err
+		}
+
+		waitCtx, cancel := ctx, context.CancelFunc(func() {})
+		if c.waiting() {
+			waitCtx, cancel = context.WithTimeout(ctx, gapRecheckInterval)
+		}
+		_, err := conn.WaitForNotification(waitCtx)
+		cancel()
+
+		if err != nil && (ctx.Err() != nil || !pgconn.Timeout(err)) {
+			return err
+		}
+	}
+}
+
+// advance hands out the events after the cursor until it reaches the last
+// one or a gap that may still be filled.
+func (f *eventFeed) advance(
+	ctx context.Context,
+	c *cursor,
+	handle func(*domain.SequencedEvent),
+) error {
+
+	for {
+		snap, events, err := f.page(ctx, c.seq, feedPageSize)
+		if err != nil {
+			return err
+		}
+
+		if c.startXmax > 0 && snap.xmin >= c.startXmax {
+			c.startXmax = 0
+		}
+		if c.pendingBelow > 0 && snap.xmin >= c.pendingXmax {
+			c.final = max(c.final, c.pendingBelow)
+			c.pendingBelow = 0
+		}
+		if c.startXmax == 0 {
+			f.settled.Store(c.seq)
+		}
+
+		for _, e := range events {
+			if e.Seq != c.seq+1 && e.Seq > c.final {
+				if e.Seq > c.pendingBelow {
+					c.pendingBelow = events[len(events)-1].Seq + 1
+					c.pendingXmax = snap.xmax
+				}
+				return nil
+			}
+
+			c.seq = e.Seq
+			if c.startXmax == 0 {
+				f.settled.Store(c.seq)
+			}
+			handle(e)
+		}
+
+		if len(events) < feedPageSize {
+			return nil
+		}
+	}
+}
+
+// Since returns the events after seq that are settled: those up to where
+// Listen has come, and past that as long as no seq is missing.
+func (f *eventFeed) Since(
+	ctx context.Context,
+	seq int64,
+	limit int,
+) ([]*domain.SequencedEvent, error) {
+
+	settled := f.settled.Load()
+
+	_, events, err := f.page(ctx, seq, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	last := seq
+	for i, e := range events {
+		if e.Seq > settled && e.Seq != last+1 {
+			return events[:i], nil
+		}
+		last = e.Seq
+	}
+
+	return events, nil
+}
+
+// page reads up to limit events after seq, oldest first, together with the
+// snapshot they were read in.
+func (f *eventFeed) page(
+	ctx context.Context,
+	seq int64,
+	limit int,
+) (snapshot, []*domain.SequencedEvent, error) {
+
+	rows, err := f.db.QueryContext(ctx, `
+		SELECT pg_snapshot_xmin(s)::text::bigint, pg_snapshot_xmax(s)::text::bigint, e.seq, e.payload
+		FROM pg_current_snapshot() s
+		LEFT JOIN LATERAL (
+			SELECT seq, payload FROM outbox_events WHERE seq > $1 ORDER BY seq LIMIT $2
+		) e ON true
+		ORDER BY e.seq
+	`, seq, limit)
+	if err != nil {
+		return snapshot{}, nil, err
+	}
+	defer rows.Close()
+
+	var (
+		snap   snapshot
+		events []*domain.SequencedEvent
+	)
+
+	for rows.Next() {
+		var (
+			eventSeq sql.NullInt64
+			payload  []byte
+		)
+		if err := rows.Scan(&snap.xmin, &snap.xmax, &eventSeq, &payload); err != nil {
+			return snapshot{}, nil, err
+		}
+		if !eventSeq.Valid {
+			continue
+		}
+
+		event := &domain.SequencedEvent{Seq: eventSeq.Int64}
+		if err := json.Unmarshal(payload, &event.Event); err != nil {
+			return snapshot{}, nil, err
+		}
+		events = append(events, event)
+	}
+
+	return snap, events, rows.Err()
+}
@@ -0,0 +1,167 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/repository/postgres"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenFeed runs the Listen of feed until t ends and returns the events it
+// hands out. It returns once Listen hands out events.
+func listenFeed(t *testing.T, feed domain.TaskEventFeed) <-chan *domain.SequencedEvent {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan *domain.SequencedEvent, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = feed.Listen(ctx, func(e *domain.SequencedEvent) { events <- e })
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// Events committed before Listen starts are not handed out, so commit
+	// until one is.
+	for {
+		createTask(t, "warm up")
+		select {
+		case <-events:
+			return events
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func createTask(t *testing.T, title string) *domain.Task {
+	t.Helper()
+
+	task, err := testRepo.Create(context.Background(), &domain.Task{Title: title, Status: domain.StatusTodo})
+	require.NoError(t, err)
+	return task
+}
+
+func receive(t *testing.T, events <-chan *domain.SequencedEvent) *domain.SequencedEvent {
+	t.Helper()
+
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event handed out")
+		return nil
+	}
+}
+
+func TestEventFeed_HoldsBackEventsPastAnUncommittedSeq(t *testing.T) {
+	truncateTasks(t)
+
+	feed := postgres.NewEventFeed(testDB, testDSN)
+	events := listenFeed(t, feed)
+	ctx := context.Background()
+
+	settled, err := feed.Since(ctx, 0, 100)
+	require.NoError(t, err)
+	last := settled[len(settled)-1].Seq
+
+	tx := postgres.NewTxManager(testDB, domain.IsolationReadCommitted, fastRetry)
+	inserted := make(chan struct{})
+	release := make(chan struct{})
+	slow := make(chan error, 1)
+	go func() {
+		slow <- tx.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := testRepo.Create(ctx, &domain.Task{Title: "slow", Status: domain.StatusTodo}); err != nil {
+				return err
+			}
+			close(inserted)
+			<-release
+			return nil
+		})
+	}()
+	<-inserted
+
+	// The faster writer draws the next seq and commits first.
+	createTask(t, "fast")
+
+	select {
+	case e := <-events:
+		t.Fatalf("event %d handed out past the uncommitted one", e.Seq)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	since, err := feed.Since(ctx, last, 100)
+	require.NoError(t, err)
+	assert.Empty(t, since)
+
+	close(release)
+	require.NoError(t, <-slow)
+
+	first, second := receive(t, events), receive(t, events)
+	assert.Equal(t, "slow", first.Event.Task.Title)
+	assert.Equal(t, "fast", second.Event.Task.Title)
+	assert.Equal(t, first.Seq+1, second.Seq)
+
+	since, err = feed.Since(ctx, last, 100)
+	require.NoError(t, err)
+	require.Len(t, since, 2)
+	assert.Equal(t, first.Seq, since[0].Seq)
+}
+
+func TestEventFeed_SkipsTheSeqOfARollback(t *testing.T) {
+	truncateTasks(t)
+
+	feed := postgres.NewEventFeed(testDB, testDSN)
+	events := listenFeed(t, feed)
+	ctx := context.Background()
+
+	errRollback := errors.New("rollback")
+	tx := postgres.NewTxManager(testDB, domain.IsolationReadCommitted, fastRetry)
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := testRepo.Create(ctx, &domain.Task{Title: "rolled back", Status: domain.StatusTodo}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	createTask(t, "after")
+
+	e := receive(t, events)
+	assert.Equal(t, "after", e.Event.Task.Title)
+}
+
+func TestEventFeed_CatchesUpPastAPage(t *testing.T) {
+	truncateTasks(t)
+
+	feed := postgres.NewEventFeed(testDB, testDSN)
+	ctx := context.Background()
+
+	// The bulk creator writes the events of a whole import in one
+	// transaction.
+	tasks := make([]*domain.Task, 1500)
+	for i := range tasks {
+		tasks[i] = &domain.Task{Title: "bulk", Status: domain.StatusTodo}
+	}
+
+	events := listenFeed(t, feed)
+
+	_, err := testPgxRepo.(domain.TaskBulkCreator).CreateMany(ctx, tasks)
+	require.NoError(t, err)
+
+	var last int64
+	for range tasks {
+		e := receive(t, events)
+		if last > 0 {
+			require.Equal(t, last+1, e.Seq)
+		}
+		last = e.Seq
+	}
+}
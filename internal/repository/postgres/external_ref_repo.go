@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"graph-task-service/internal/domain"
+)
+
+type externalRefRepository struct {
+	db *sql.DB
+}
+
+// NewExternalRefRepository stores the links of imported items to their
+// tasks. Deleting a task deletes its links.
+func NewExternalRefRepository(db *sql.DB) domain.ExternalRefRepository {
+	return &externalRefRepository{db: db}
+}
+
+func (r *externalRefRepository) Get(
+	ctx context.Context,
+	source, externalID string,
+) (*domain.ExternalRef, error) {
+
+	ref := domain.ExternalRef{Source: source, ExternalID: externalID}
+
+	err := dbFrom(ctx, r.db).QueryRowContext(
+		ctx,
+		`SELECT task_id FROM task_external_refs WHERE source = $1 AND external_id = $2`,
+		source,
+		externalID,
+	).Scan(&ref.TaskID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ref, nil
+}
+
+// Lock takes a transaction-level advisory lock on source.
+func (r *externalRefRepository) Lock(
+	ctx context.Context,
+	source string,
+) error {
+
+	_, err := dbFrom(ctx, r.db).ExecContext(
+		ctx,
+		`SELECT pg_advisory_xact_lock(hashtext('task_external_refs'), hashtext($1))`,
+		source,
+	)
+	return err
+}
+
+func (r *externalRefRepository) Put(
+	ctx context.Context,
+	ref *domain.ExternalRef,
+) error {
+
+	query := `
+		INSERT INTO task_external_refs (source, external_id, task_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (source, external_id) DO UPDATE SET task_id = EXCLUDED.task_id
+	`
+
+	_, err := dbFrom(ctx, r.db).ExecContext(ctx, query, ref.Source, ref.ExternalID, ref.TaskID)
+	return err
+}
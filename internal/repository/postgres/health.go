@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PingCheck verifies a connection can be obtained and used.
+func PingCheck(db *sql.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// MigrationCheck fails while the database schema is older or newer than
+// the one this binary was built for.
+func MigrationCheck(db *sql.DB, expected int64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var version int64
+		err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+		if err != nil {
+			return fmt.Errorf("read schema version: %w", err)
+		}
+
+		if version != expected {
+			return fmt.Errorf("schema version %d, expected %d", version, expected)
+		}
+		return nil
+	}
+}
+
+// PoolSaturationCheck fails when at least threshold (0..1] of the maximum
+// open connections are in use. Pools without a limit always pass.
+func PoolSaturationCheck(db *sql.DB, threshold float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		stats := db.Stats()
+		if stats.MaxOpenConnections <= 0 {
+			return nil
+		}
+
+		usage := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		if usage >= threshold {
+			return fmt.Errorf(
+				"connection pool saturated: %d of %d in use, %d waits",
+				stats.InUse, stats.MaxOpenConnections, stats.WaitCount,
+			)
+		}
+		return nil
+	}
+}
+
+// PgxPoolSaturationCheck is PoolSaturationCheck for a native pgx pool.
+func PgxPoolSaturationCheck(pool *pgxpool.Pool, threshold float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		stats := pool.Stat()
+		if stats.MaxConns() <= 0 {
+			return nil
+		}
+
+		usage := float64(stats.AcquiredConns()) / float64(stats.MaxConns())
+		if usage >= threshold {
+			return fmt.Errorf(
+				"connection pool saturated: %d of %d in use, %d waits",
+				stats.AcquiredConns(), stats.MaxConns(), stats.EmptyAcquireCount(),
+			)
+		}
+		return nil
+	}
+}
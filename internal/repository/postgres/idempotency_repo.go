@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"graph-task-service/internal/domain"
+	"time"
+)
+
+// reserveAttempts bounds how often Reserve tries again when the key is
+// released between its insert and its read.
+const reserveAttempts = 3
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) domain.IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) Reserve(
+	ctx context.Context,
+	key string,
+	fingerprint string,
+	lease time.Duration,
+) (*domain.IdempotencyRecord, bool, error) {
+
+	// An expired record, or one whose request died before its lease ran
+	// out, is taken over as if it did not exist.
+	query := `
+		INSERT INTO idempotency_keys (key, fingerprint, expires_at)
+		VALUES ($1, $2, now() + make_interval(secs => $3))
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    status_code = NULL,
+		    content_type = NULL,
+		    response_body = NULL,
+		    created_at = now(),
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()
+		RETURNING key
+	`
+
+	// A Release between the insert and the read leaves nothing to read; the
+	// key is free again then, so the reserve is retried.
+	for range reserveAttempts {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
+		var reserved string
+		err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, key, fingerprint, lease.Seconds()).Scan(&reserved)
+		if err == nil {
+			return nil, true, nil
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, false, err
+		}
+
+		existing, err := r.get(ctx, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		return existing, false, nil
+	}
+
+	return nil, false, errors.New("idempotency key keeps being released")
+}
+
+func (r *idempotencyRepository) get(
+	ctx context.Context,
+	key string,
+) (*domain.IdempotencyRecord, error) {
+
+	var (
+		record      domain.IdempotencyRecord
+		statusCode  sql.NullInt64
+		contentType sql.NullString
+	)
+
+	query := `
+		SELECT key, fingerprint, status_code, content_type, response_body, expires_at
+		FROM idempotency_keys
+		WHERE key = $1
+	`
+
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, key).Scan(
+		&record.Key,
+		&record.Fingerprint,
+		&statusCode,
+		&contentType,
+		&record.Body,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Completed = statusCode.Valid
+	record.StatusCode = int(statusCode.Int64)
+	record.ContentType = contentType.String
+
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Complete(
+	ctx context.Context,
+	key string,
+	statusCode int,
+	contentType string,
+	body []byte,
+	ttl time.Duration,
+) error {
+
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1,
+		    content_type = $2,
+		    response_body = $3,
+		    expires_at = now() + make_interval(secs => $5)
+		WHERE key = $4
+	`
+
+	_, err := dbFrom(ctx, r.db).ExecContext(ctx, query, statusCode, contentType, body, key, ttl.Seconds())
+	return err
+}
+
+func (r *idempotencyRepository) Release(
+	ctx context.Context,
+	key string,
+) error {
+
+	_, err := dbFrom(ctx, r.db).ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1`, key)
+	return err
+}
+
+func (r *idempotencyRepository) DeleteExpired(
+	ctx context.Context,
+) (int64, error) {
+
+	res, err := dbFrom(ctx, r.db).ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"graph-task-service/internal/domain"
+	"time"
+)
+
+type importJobRepository struct {
+	db *sql.DB
+}
+
+func NewImportJobRepository(db *sql.DB) domain.ImportJobRepository {
+	return &importJobRepository{db: db}
+}
+
+// rowError is the stored form of a domain.ImportRowError.
+type rowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func encodeRowErrors(errs []domain.ImportRowError) ([]byte, error) {
+	stored := make([]rowError, len(errs))
+	for i, e := range errs {
+		stored[i] = rowError(e)
+	}
+	return json.Marshal(stored)
+}
+
+func (r *importJobRepository) Create(
+	ctx context.Context,
+	job *domain.ImportJob,
+) (*domain.ImportJob, error) {
+
+	rowErrors, err := encodeRowErrors(job.Result.Errors)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO import_jobs (created_by, status, dry_run, row_count, invalid_count, created_count, row_errors, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = dbFrom(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		job.CreatedBy,
+		string(job.Status),
+		job.Result.DryRun,
+		job.Result.Rows,
+		job.Result.Invalid,
+		job.Result.Created,
+		rowErrors,
+		job.Error,
+	).Scan(
+		&job.ID,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (r *importJobRepository) GetByID(
+	ctx context.Context,
+	id string,
+) (*domain.ImportJob, error) {
+
+	var (
+		job       domain.ImportJob
+		status    string
+		rowErrors []byte
+		message   sql.NullString
+		finished  sql.NullTime
+	)
+
+	query := `
+		SELECT id, created_by, status, dry_run, row_count, invalid_count, created_count, row_errors, error,
+		       created_at, updated_at, finished_at
+		FROM import_jobs
+		WHERE id = $1
+	`
+
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&job.ID,
+		&job.CreatedBy,
+		&status,
+		&job.Result.DryRun,
+		&job.Result.Rows,
+		&job.Result.Invalid,
+		&job.Result.Created,
+		&rowErrors,
+		&message,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&finished,
+	)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	var stored []rowError
+	if err := json.Unmarshal(rowErrors, &stored); err != nil {
+		return nil, err
+	}
+	for _, e := range stored {
+		job.Result.Errors = append(job.Result.Errors, domain.ImportRowError(e))
+	}
+
+	job.Status = domain.ImportStatus(status)
+	if message.Valid {
+		job.Error = &message.String
+	}
+	if finished.Valid {
+		job.FinishedAt = &finished.Time
+	}
+
+	return &job, nil
+}
+
+func (r *importJobRepository) Update(
+	ctx context.Context,
+	job *domain.ImportJob,
+) error {
+
+	rowErrors, err := encodeRowErrors(job.Result.Errors)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE import_jobs
+		SET status = $1,
+		    invalid_count = $2,
+		    created_count = $3,
+		    row_errors = $4,
+		    error = $5,
+		    finished_at = $6,
+		    updated_at = now()
+		WHERE id = $7
+	`
+
+	res, err := dbFrom(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		string(job.Status),
+		job.Result.Invalid,
+		job.Result.Created,
+		rowErrors,
+		job.Error,
+		job.FinishedAt,
+		job.ID,
+	)
+
+	if err != nil {
+		return notFound(err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *importJobRepository) Touch(
+	ctx context.Context,
+	id string,
+) error {
+
+	query := `
+		UPDATE import_jobs
+		SET updated_at = now()
+		WHERE id = $1 AND status IN ('pending', 'running')
+	`
+
+	_, err := dbFrom(ctx, r.db).ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *importJobRepository) FailStale(
+	ctx context.Context,
+	olderThan time.Duration,
+	reason string,
+) (int64, error) {
+
+	query := `
+		UPDATE import_jobs
+		SET status = 'failed',
+		    error = $2,
+		    finished_at = now(),
+		    updated_at = now()
+		WHERE status IN ('pending', 'running')
+		  AND updated_at < now() - make_interval(secs => $1)
+	`
+
+	res, err := dbFrom(ctx, r.db).ExecContext(ctx, query, olderThan.Seconds(), reason)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
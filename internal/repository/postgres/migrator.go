@@ -0,0 +1,430 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// migrationLockID is the pg_advisory_lock key held while migrating, so
+// replicas starting together apply each migration once.
+const migrationLockID int64 = 0x7461736b6d6967 // "taskmig"
+
+// legacyBaselineVersion is the schema the former inline RunMigrations
+// created: the tasks table with a single assignee, as in migration 1.
+// Databases set up that way are adopted at this version, so the later
+// migrations still run on them.
+const legacyBaselineVersion = 1
+
+var (
+	ErrChecksumMismatch = errors.New("applied migration was modified")
+	ErrUnknownMigration = errors.New("database has a migration unknown to this binary")
+)
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+	// Modified is set when the file no longer matches what was applied.
+	Modified bool
+}
+
+// LoadMigrations reads NNN_name.up.sql and NNN_name.down.sql files from
+// fsys, ordered by version. Every version needs an up file; down files are
+// only required to revert.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", entry.Name())
+		}
+
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+			sum := sha256.Sum256(body)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return int(a.Version - b.Version)
+	})
+
+	return migrations, nil
+}
+
+// Migrator applies and reverts migrations, recording them in
+// schema_migrations. Each migration runs in its own transaction.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest is the version the schema has once every migration is applied.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration in order and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		status, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range status {
+			if s.Applied {
+				continue
+			}
+			if err := m.apply(ctx, conn, s.Migration); err != nil {
+				return err
+			}
+			applied = append(applied, s.Migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		status, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(status) - 1; i >= 0 && len(reverted) < steps; i-- {
+			if !status[i].Applied {
+				continue
+			}
+			if err := m.revert(ctx, conn, status[i].Migration); err != nil {
+				return err
+			}
+			reverted = append(reverted, status[i].Migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Redo reverts and re-applies the newest applied migration.
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		status, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(status) - 1; i >= 0; i-- {
+			if !status[i].Applied {
+				continue
+			}
+
+			migration := status[i].Migration
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			redone = &migration
+			return nil
+		}
+		return nil
+	})
+
+	return redone, err
+}
+
+// Status lists every known migration and whether it is applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var status []MigrationStatus
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		var err error
+		status, err = m.status(ctx, conn)
+		return err
+	})
+
+	return status, err
+}
+
+// locked runs fn on one connection holding the migration advisory lock.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		// The lock is released with the session should this fail.
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+	}()
+
+	if err := m.prepare(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// prepare creates schema_migrations and adopts databases created before
+// migrations were versioned.
+func (m *Migrator) prepare(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL DEFAULT '',
+		checksum TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)
+	`)
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var legacy bool
+	err = conn.QueryRowContext(ctx, `
+	SELECT to_regclass('tasks') IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM schema_migrations)
+	`).Scan(&legacy)
+	if err != nil || !legacy {
+		return err
+	}
+
+	slog.WarnContext(ctx, "adopting unversioned schema", "version", legacyBaselineVersion)
+
+	for _, migration := range m.migrations {
+		if migration.Version > legacyBaselineVersion {
+			break
+		}
+
+		_, err := conn.ExecContext(ctx, `
+		INSERT INTO schema_migrations (version, name, checksum)
+		VALUES ($1, $2, $3)
+		`, migration.Version, migration.Name, migration.Checksum)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) status(ctx context.Context, conn *sql.Conn) ([]MigrationStatus, error) {
+	rows, err := conn.QueryContext(ctx, `
+	SELECT version, checksum, applied_at
+	FROM schema_migrations
+	ORDER BY version
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type appliedRow struct {
+		checksum  string
+		appliedAt time.Time
+	}
+
+	applied := make(map[int64]appliedRow)
+	for rows.Next() {
+		var (
+			version int64
+			row     appliedRow
+		)
+		if err := rows.Scan(&version, &row.checksum, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		s := MigrationStatus{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.appliedAt
+			s.Applied = true
+			s.AppliedAt = &appliedAt
+			s.Modified = row.checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		status = append(status, s)
+	}
+
+	for version := range applied {
+		return status, fmt.Errorf("%w: version %d", ErrUnknownMigration, version)
+	}
+
+	return status, nil
+}
+
+// verify returns the status, refusing to go on when applied files were
+// edited or the database is ahead of this binary.
+func (m *Migrator) verify(ctx context.Context, conn *sql.Conn) ([]MigrationStatus, error) {
+	status, err := m.status(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range status {
+		if s.Modified {
+			return nil, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, s.Version, s.Name)
+		}
+	}
+
+	return status, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	start := time.Now()
+
+	err := inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO schema_migrations (version, name, checksum)
+		VALUES ($1, $2, $3)
+		`, migration.Version, migration.Name, migration.Checksum)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("apply migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	slog.InfoContext(ctx, "migration applied",
+		"version", migration.Version,
+		"name", migration.Name,
+		"duration", time.Since(start),
+	)
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+	}
+
+	err := inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("revert migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	slog.InfoContext(ctx, "migration reverted", "version", migration.Version, "name", migration.Name)
+	return nil
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Migrations may legitimately outlast the statement timeout of the
+	// application.
+	if _, err := tx.ExecContext(ctx, `SET LOCAL statement_timeout = 0`); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
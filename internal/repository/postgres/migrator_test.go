@@ -0,0 +1,132 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"graph-task-service/internal/repository/postgres"
+	"graph-task-service/migration"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_column.up.sql":     {Data: []byte("ALTER TABLE t ADD COLUMN c INT;")},
+		"002_add_column.down.sql":   {Data: []byte("ALTER TABLE t DROP COLUMN c;")},
+		"001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (id INT);")},
+		"001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"README.md":                 {Data: []byte("ignored")},
+	}
+
+	migrations, err := postgres.LoadMigrations(fsys)
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_table", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE t;", migrations[0].Down)
+	assert.Equal(t, int64(2), migrations[1].Version)
+	assert.Len(t, migrations[1].Checksum, 64)
+	assert.NotEqual(t, migrations[0].Checksum, migrations[1].Checksum)
+}
+
+func TestLoadMigrations_MissingUp(t *testing.T) {
+	fsys := fstest.MapFS{
+		"001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+
+	_, err := postgres.LoadMigrations(fsys)
+	assert.ErrorContains(t, err, "missing up file")
+}
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := postgres.LoadMigrations(migration.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, int64(i+1), m.Version, "versions must be contiguous")
+		assert.NotEmpty(t, m.Down, "migration %d_%s has no down file", m.Version, m.Name)
+	}
+}
+
+// openSchema returns a pool on the empty schema name of the test database,
+// which is dropped when tb ends.
+func openSchema(tb testing.TB, name string) *sql.DB {
+	tb.Helper()
+	requireDB(tb)
+
+	_, err := testDB.Exec(`DROP SCHEMA IF EXISTS ` + name + ` CASCADE; CREATE SCHEMA ` + name)
+	require.NoError(tb, err)
+
+	cfg, err := pgx.ParseConfig(testDSN)
+	require.NoError(tb, err)
+	// public stays on the path for the uuid-ossp functions.
+	cfg.RuntimeParams["search_path"] = name + ", public"
+
+	db := stdlib.OpenDB(*cfg)
+	tb.Cleanup(func() {
+		_ = db.Close()
+		_, _ = testDB.Exec(`DROP SCHEMA ` + name + ` CASCADE`)
+	})
+
+	return db
+}
+
+// baselineSchema is what RunMigrations of the first release created, before
+// migrations were versioned.
+const baselineSchema = `
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS tasks (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	title TEXT NOT NULL,
+	status TEXT NOT NULL,
+	assignee TEXT,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee ON tasks(assignee);
+`
+
+func TestMigrator_AdoptsBaselineSchema(t *testing.T) {
+	db := openSchema(t, "migrator_baseline")
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, baselineSchema)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO tasks (title, status, assignee) VALUES ('t', 'todo', 'alice')`)
+	require.NoError(t, err)
+
+	migrator, err := postgres.NewMigrator(db, migration.FS)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, applied)
+	assert.Equal(t, int64(2), applied[0].Version)
+
+	var assignees string
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT array_to_string(assignees, ',') FROM tasks`).Scan(&assignees))
+	assert.Equal(t, "alice", assignees)
+
+	for _, table := range []string{"webhooks", "outbox_events", "idempotency_keys", "import_jobs"} {
+		var exists bool
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists))
+		assert.True(t, exists, table)
+	}
+
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, s := range status {
+		assert.True(t, s.Applied, "%d_%s", s.Version, s.Name)
+		assert.False(t, s.Modified, "%d_%s", s.Version, s.Name)
+	}
+}
@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"graph-task-service/internal/domain"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) domain.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// insertOutboxEvent records an event for task inside the transaction q, so
+// the event is stored if and only if the task change commits.
+func insertOutboxEvent(
+	ctx context.Context,
+	tx queryer,
+	eventType domain.EventType,
+	task *domain.Task,
+) error {
+
+	event, payload, err := newTaskEvent(eventType, task)
+	if err != nil {
+		return err
+	}
+
+	var seq int64
+
+	query := `INSERT INTO outbox_events (id, event_type, task_id, payload) VALUES ($1, $2, $3, $4) RETURNING seq`
+
+	qctx, span := startQuerySpan(ctx, "taskRepository.insertOutboxEvent", query)
+
+	err = tx.QueryRowContext(
+		qctx,
+		query,
+		event.ID,
+		event.Type,
+		event.TaskID,
+		payload,
+	).Scan(&seq)
+
+	endQuerySpan(span, rowCount(err), err)
+
+	if err != nil {
+		return err
+	}
+
+	// NOTIFY is transactional: listeners only hear about the event on commit.
+	_, err = tx.ExecContext(
+		ctx,
+		`SELECT pg_notify($1, $2)`,
+		eventsChannel,
+		strconv.FormatInt(seq, 10),
+	)
+
+	return err
+}
+
+// newTaskEvent builds the event for a change of task together with the
+// JSON payload stored in the outbox.
+func newTaskEvent(eventType domain.EventType, task *domain.Task) (domain.TaskEvent, []byte, error) {
+	event := domain.TaskEvent{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		TaskID:     task.ID,
+		Task:       task,
+		OccurredAt: time.Now().UTC(),
+	}
+
+	payload, err := json.Marshal(event)
+	return event, payload, err
+}
+
+func (r *outboxRepository) ClaimBatch(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]*domain.OutboxMessage, error) {
+
+	query := `
+		UPDATE outbox_events
+		SET next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM outbox_events
+			WHERE published_at IS NULL AND next_attempt_at <= now()
+			ORDER BY seq
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING seq, payload, attempts, last_error, created_at
+	`
+
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*domain.OutboxMessage
+
+	for rows.Next() {
+		var (
+			m       domain.OutboxMessage
+			payload []byte
+		)
+		if err := rows.Scan(
+			&m.Seq,
+			&payload,
+			&m.Attempts,
+			&m.LastError,
+			&m.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(payload, &m.Event); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not preserve the subquery order.
+	slices.SortFunc(messages, func(a, b *domain.OutboxMessage) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkPublished(
+	ctx context.Context,
+	id string,
+) error {
+
+	_, err := dbFrom(ctx, r.db).ExecContext(
+		ctx,
+		`UPDATE outbox_events SET published_at = now(), attempts = attempts + 1, last_error = NULL WHERE id = $1`,
+		id,
+	)
+	return err
+}
+
+func (r *outboxRepository) MarkFailed(
+	ctx context.Context,
+	id string,
+	lastErr string,
+	retryIn time.Duration,
+) error {
+
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1,
+		    last_error = $1,
+		    next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id = $3
+	`
+
+	_, err := dbFrom(ctx, r.db).ExecContext(ctx, query, lastErr, retryIn.Seconds(), id)
+	return err
+}
+
+func (r *outboxRepository) DeletePublished(
+	ctx context.Context,
+	olderThan time.Duration,
+) (int64, error) {
+
+	res, err := dbFrom(ctx, r.db).ExecContext(
+		ctx,
+		`DELETE FROM outbox_events WHERE published_at < now() - make_interval(secs => $1)`,
+		olderThan.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
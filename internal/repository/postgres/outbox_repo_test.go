@@ -0,0 +1,46 @@
+package postgres_test
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/repository/postgres"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRepository_ClaimBatchInSeqOrder(t *testing.T) {
+	truncateTasks(t)
+
+	ctx := context.Background()
+
+	// Events written in one transaction share created_at.
+	tx := postgres.NewTxManager(testDB, domain.IsolationReadCommitted, fastRetry)
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		created, err := testRepo.Create(ctx, &domain.Task{Title: "outbox", Status: domain.StatusTodo})
+		if err != nil {
+			return err
+		}
+		created.Title = "renamed"
+		created.Status = domain.StatusDone
+		return testRepo.Update(ctx, created)
+	})
+	require.NoError(t, err)
+
+	messages, err := postgres.NewOutboxRepository(testDB).ClaimBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+
+	var types []domain.EventType
+	for i, m := range messages {
+		if i > 0 {
+			require.Greater(t, m.Seq, messages[i-1].Seq)
+		}
+		types = append(types, m.Event.Type)
+	}
+	require.Equal(t, []domain.EventType{
+		domain.EventTaskCreated,
+		domain.EventTaskUpdated,
+		domain.EventTaskStatusChanged,
+	}, types)
+}
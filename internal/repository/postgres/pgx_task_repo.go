@@ -0,0 +1,579 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Names of the statements the pgx task repository prepares on every
+// connection it uses.
+const (
+	stmtTaskGet     = "task_get"
+	stmtTaskGetMany = "task_get_many"
+	stmtTaskList    = "task_list"
+	stmtTaskInsert  = "task_insert"
+	stmtTaskUpdate  = "task_update"
+	stmtTaskDelete  = "task_delete"
+	stmtOutboxEvent = "task_outbox_event"
+)
+
+var taskStatements = []struct{ name, sql string }{
+	{stmtTaskGet, `
+		SELECT id, title, status, assignees, watchers, created_at, updated_at
+		FROM tasks
+		WHERE id = $1
+	`},
+	{stmtTaskGetMany, `
+		SELECT id, title, status, assignees, watchers, created_at, updated_at
+		FROM tasks
+		WHERE id = ANY($1)
+	`},
+	// NULL parameters disable a criterion, so one statement serves every
+	// filter. Users are matched by containment so the GIN indexes apply.
+	{stmtTaskList, `
+		SELECT id, title, status, assignees, watchers, created_at, updated_at
+		FROM tasks
+		WHERE ($1::text IS NULL OR status = $1)
+		  AND ($2::text IS NULL OR assignees @> ARRAY[$2::text])
+		  AND ($3::text IS NULL OR watchers @> ARRAY[$3::text])
+		  AND ($4::text IS NULL OR assignees @> ARRAY[$4::text] OR watchers @> ARRAY[$4::text])
+		ORDER BY created_at DESC, id DESC
+		LIMIT $5 OFFSET $6
+	`},
+	{stmtTaskInsert, `
+		INSERT INTO tasks (id, title, status, assignees, watchers, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+	`},
+	// Locks the row, updates it and records its events in one statement:
+	// an update event, then a status change event when the status differs
+	// from the locked one. The count forces the notify to be evaluated.
+	{stmtTaskUpdate, `
+		WITH previous AS (
+			SELECT id, status FROM tasks WHERE id = $1 FOR UPDATE
+		), updated AS (
+			UPDATE tasks t
+			SET title = $2, status = $3, assignees = $4, watchers = $5, updated_at = $6
+			FROM previous
+			WHERE t.id = previous.id
+			RETURNING previous.status AS previous_status
+		), event AS (
+			INSERT INTO outbox_events (id, event_type, task_id, payload)
+			SELECT e.id, e.event_type, $1::text, e.payload
+			FROM updated,
+			     (VALUES (1, $7::uuid, $8::text, $9::jsonb), (2, $10::uuid, $11::text, $12::jsonb)) e(n, id, event_type, payload)
+			WHERE e.n = 1 OR previous_status <> $3
+			ORDER BY e.n
+			RETURNING seq
+		)
+		SELECT previous_status,
+		       (SELECT count(*) FROM (SELECT pg_notify('` + eventsChannel + `', seq::text) FROM event ORDER BY seq) notified)
+		FROM updated
+	`},
+	{stmtTaskDelete, `
+		DELETE FROM tasks
+		WHERE id = $1
+		RETURNING id, title, status, assignees, watchers, created_at, updated_at
+	`},
+	{stmtOutboxEvent, `
+		WITH event AS (
+			INSERT INTO outbox_events (id, event_type, task_id, payload)
+			VALUES ($1, $2, $3, $4)
+			RETURNING seq
+		)
+		SELECT pg_notify('` + eventsChannel + `', seq::text) FROM event
+	`},
+}
+
+type pgxTaskRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgxTaskRepository stores tasks through a native pgx pool. It behaves
+// like NewTaskRepository but needs fewer round trips: statements are
+// prepared once per connection, creations are pipelined in a batch and
+// updates run as a single statement. It also implements
+// domain.TaskBulkCreator with COPY.
+func NewPgxTaskRepository(pool *pgxpool.Pool) domain.TaskRepository {
+	return &pgxTaskRepository{pool: pool}
+}
+
+// acquire returns the connection of the caller's unit of work, or a pooled
+// one, with the task statements prepared. Preparing is lazy rather than
+// done in AfterConnect because the pool may be opened before migrations
+// have created the tables; pgx skips statements that are already prepared
+// on the connection.
+func (r *pgxTaskRepository) acquire(ctx context.Context) (*pgx.Conn, func(), error) {
+	var (
+		conn    *pgx.Conn
+		release = func() {}
+	)
+
+	if state := txFromContext(ctx); state != nil {
+		if state.conn == nil {
+			return nil, nil, errors.New("unit of work does not run on a pgx connection")
+		}
+		conn = state.conn
+	} else {
+		pooled, err := r.pool.Acquire(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		conn, release = pooled.Conn(), pooled.Release
+	}
+
+	for _, s := range taskStatements {
+		if _, err := conn.Prepare(ctx, s.name, s.sql); err != nil {
+			release()
+			return nil, nil, err
+		}
+	}
+
+	return conn, release, nil
+}
+
+// pgxQuerier is a pgx connection or transaction.
+type pgxQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error)
+}
+
+// inTx runs fn on the connection of the caller's unit of work, whose
+// transaction already makes fn atomic, or in a new transaction.
+func (r *pgxTaskRepository) inTx(ctx context.Context, fn func(q pgxQuerier) error) error {
+	conn, release, err := r.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if txFromContext(ctx) != nil {
+		return fn(conn)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// statementText returns the SQL of a prepared statement for span attributes.
+func statementText(name string) string {
+	for _, s := range taskStatements {
+		if s.name == name {
+			return s.sql
+		}
+	}
+	return name
+}
+
+// taskTimestamp is the creation and update time of tasks written by this repository,
+// at the precision Postgres stores.
+func taskTimestamp() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (r *pgxTaskRepository) Create(
+	ctx context.Context,
+	task *domain.Task,
+) (*domain.Task, error) {
+
+	task.ID = uuid.NewString()
+	task.Assignees = orEmpty(task.Assignees)
+	task.Watchers = orEmpty(task.Watchers)
+	task.CreatedAt = taskTimestamp()
+	task.UpdatedAt = task.CreatedAt
+
+	event, payload, err := newTaskEvent(domain.EventTaskCreated, task)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Outside a unit of work the statements of a batch share one implicit
+	// transaction: the event is stored if and only if the task is.
+	batch := &pgx.Batch{}
+	batch.Queue(stmtTaskInsert, task.ID, task.Title, task.Status, task.Assignees, task.Watchers, task.CreatedAt)
+	batch.Queue(stmtOutboxEvent, event.ID, event.Type, event.TaskID, payload)
+
+	qctx, span := startQuerySpan(ctx, "pgxTaskRepository.Create", statementText(stmtTaskInsert))
+
+	err = conn.SendBatch(qctx, batch).Close()
+
+	endQuerySpan(span, rowCount(err), err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// CreateMany copies tasks and their creation events in one transaction.
+func (r *pgxTaskRepository) CreateMany(
+	ctx context.Context,
+	tasks []*domain.Task,
+) ([]*domain.Task, error) {
+
+	if len(tasks) == 0 {
+		return tasks, nil
+	}
+
+	createdAt := taskTimestamp()
+	taskRows := make([][]any, 0, len(tasks))
+	eventRows := make([][]any, 0, len(tasks))
+	eventIDs := make([]string, 0, len(tasks))
+
+	for _, task := range tasks {
+		task.ID = uuid.NewString()
+		task.Assignees = orEmpty(task.Assignees)
+		task.Watchers = orEmpty(task.Watchers)
+		task.CreatedAt = createdAt
+		task.UpdatedAt = createdAt
+
+		event, payload, err := newTaskEvent(domain.EventTaskCreated, task)
+		if err != nil {
+			return nil, err
+		}
+
+		taskRows = append(taskRows, []any{task.ID, task.Title, string(task.Status), task.Assignees, task.Watchers, createdAt, createdAt})
+		eventRows = append(eventRows, []any{event.ID, string(event.Type), event.TaskID, payload})
+		eventIDs = append(eventIDs, event.ID)
+	}
+
+	err := r.inTx(ctx, func(q pgxQuerier) error {
+		qctx, span := startQuerySpan(ctx, "pgxTaskRepository.CreateMany", "COPY tasks")
+		copied, err := q.CopyFrom(
+			qctx,
+			pgx.Identifier{"tasks"},
+			[]string{"id", "title", "status", "assignees", "watchers", "created_at", "updated_at"},
+			pgx.CopyFromRows(taskRows),
+		)
+		endQuerySpan(span, copied, err)
+
+		if err != nil {
+			return err
+		}
+
+		qctx, span = startQuerySpan(ctx, "pgxTaskRepository.CreateMany.outbox", "COPY outbox_events")
+		copied, err = q.CopyFrom(
+			qctx,
+			pgx.Identifier{"outbox_events"},
+			[]string{"id", "event_type", "task_id", "payload"},
+			pgx.CopyFromRows(eventRows),
+		)
+		endQuerySpan(span, copied, err)
+
+		if err != nil {
+			return err
+		}
+
+		// COPY does not run the notify of stmtOutboxEvent, so listeners are
+		// told about the copied events here, in sequence order.
+		_, err = q.Exec(
+			ctx,
+			`SELECT pg_notify($1, seq::text) FROM outbox_events WHERE id = ANY($2) ORDER BY seq`,
+			eventsChannel,
+			eventIDs,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (r *pgxTaskRepository) GetByID(
+	ctx context.Context,
+	id string,
+) (*domain.Task, error) {
+
+	conn, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	qctx, span := startQuerySpan(ctx, "pgxTaskRepository.GetByID", statementText(stmtTaskGet))
+
+	task, err := scanTask(conn.QueryRow(qctx, stmtTaskGet, id))
+
+	endQuerySpan(span, rowCount(err), noRowsIsNotAnError(err))
+
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return task, nil
+}
+
+// GetByIDs implements domain.TaskBatchGetter with one statement.
+func (r *pgxTaskRepository) GetByIDs(
+	ctx context.Context,
+	ids []string,
+) (_ []*domain.Task, err error) {
+
+	ids = uuids(ids)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	conn, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	qctx, span := startQuerySpan(ctx, "pgxTaskRepository.GetByIDs", statementText(stmtTaskGetMany))
+
+	var tasks []*domain.Task
+	defer func() { endQuerySpan(span, int64(len(tasks)), err) }()
+
+	rows, err := conn.Query(qctx, stmtTaskGetMany, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Task, error) {
+		return scanTask(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (r *pgxTaskRepository) List(
+	ctx context.Context,
+	filter domain.TaskFilter,
+) (_ []*domain.Task, err error) {
+
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	conn, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	qctx, span := startQuerySpan(ctx, "pgxTaskRepository.List", statementText(stmtTaskList))
+
+	var tasks []*domain.Task
+	defer func() { endQuerySpan(span, int64(len(tasks)), err) }()
+
+	rows, err := conn.Query(
+		qctx,
+		stmtTaskList,
+		filter.Status,
+		filter.Assignee,
+		filter.Watcher,
+		filter.Participant,
+		limit,
+		max(filter.Offset, 0),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Task, error) {
+		return scanTask(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// Stream implements domain.TaskStreamer like the database/sql repository:
+// the list statement runs as a server-side cursor fetched in batches.
+func (r *pgxTaskRepository) Stream(
+	ctx context.Context,
+	filter domain.TaskFilter,
+	fn func(*domain.Task) error,
+) (err error) {
+
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	query := "DECLARE task_stream NO SCROLL CURSOR FOR " + statementText(stmtTaskList)
+
+	qctx, span := startQuerySpan(ctx, "pgxTaskRepository.Stream", query)
+
+	var streamed int64
+	defer func() { endQuerySpan(span, streamed, err) }()
+
+	return r.inTx(qctx, func(q pgxQuerier) error {
+		_, err := q.Exec(
+			qctx,
+			query,
+			filter.Status,
+			filter.Assignee,
+			filter.Watcher,
+			filter.Participant,
+			limit,
+			max(filter.Offset, 0),
+		)
+		if err != nil {
+			return err
+		}
+		defer q.Exec(context.WithoutCancel(qctx), "CLOSE task_stream")
+
+		fetch := "FETCH " + strconv.Itoa(streamBatchSize) + " FROM task_stream"
+
+		for {
+			rows, err := q.Query(qctx, fetch)
+			if err != nil {
+				return err
+			}
+
+			n := 0
+			for rows.Next() {
+				task, err := scanTask(rows)
+				if err == nil {
+					n++
+					err = fn(task)
+				}
+				if err != nil {
+					rows.Close()
+					return err
+				}
+			}
+			rows.Close()
+
+			streamed += int64(n)
+			if err := rows.Err(); err != nil || n < streamBatchSize {
+				return err
+			}
+		}
+	})
+}
+
+func (r *pgxTaskRepository) Update(
+	ctx context.Context,
+	task *domain.Task,
+) error {
+
+	task.UpdatedAt = taskTimestamp()
+
+	updated, updatedPayload, err := newTaskEvent(domain.EventTaskUpdated, task)
+	if err != nil {
+		return err
+	}
+
+	statusChanged, statusChangedPayload, err := newTaskEvent(domain.EventTaskStatusChanged, task)
+	if err != nil {
+		return err
+	}
+
+	conn, release, err := r.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	var (
+		previous domain.TaskStatus
+		notified int64
+	)
+
+	qctx, span := startQuerySpan(ctx, "pgxTaskRepository.Update", statementText(stmtTaskUpdate))
+
+	err = conn.QueryRow(
+		qctx,
+		stmtTaskUpdate,
+		task.ID,
+		task.Title,
+		task.Status,
+		orEmpty(task.Assignees),
+		orEmpty(task.Watchers),
+		task.UpdatedAt,
+		updated.ID,
+		updated.Type,
+		updatedPayload,
+		statusChanged.ID,
+		statusChanged.Type,
+		statusChangedPayload,
+	).Scan(&previous, &notified)
+
+	endQuerySpan(span, rowCount(err), noRowsIsNotAnError(err))
+
+	return notFound(err)
+}
+
+func (r *pgxTaskRepository) Delete(
+	ctx context.Context,
+	id string,
+) error {
+
+	return r.inTx(ctx, func(q pgxQuerier) error {
+		qctx, span := startQuerySpan(ctx, "pgxTaskRepository.Delete", statementText(stmtTaskDelete))
+
+		task, err := scanTask(q.QueryRow(qctx, stmtTaskDelete, id))
+
+		endQuerySpan(span, rowCount(err), noRowsIsNotAnError(err))
+
+		if err != nil {
+			return notFound(err)
+		}
+
+		event, payload, err := newTaskEvent(domain.EventTaskDeleted, task)
+		if err != nil {
+			return err
+		}
+
+		qctx, span = startQuerySpan(ctx, "pgxTaskRepository.insertOutboxEvent", statementText(stmtOutboxEvent))
+		_, err = q.Exec(qctx, stmtOutboxEvent, event.ID, event.Type, event.TaskID, payload)
+		endQuerySpan(span, rowCount(err), err)
+
+		return err
+	})
+}
+
+func scanTask(row pgx.Row) (*domain.Task, error) {
+	var task domain.Task
+
+	err := row.Scan(
+		&task.ID,
+		&task.Title,
+		&task.Status,
+		&task.Assignees,
+		&task.Watchers,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
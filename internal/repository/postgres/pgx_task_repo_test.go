@@ -0,0 +1,313 @@
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func outboxEventTypes(t *testing.T, taskID string) []string {
+	rows, err := testDB.Query(
+		`SELECT event_type FROM outbox_events WHERE task_id = $1 ORDER BY seq`,
+		taskID,
+	)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var types []string
+	for rows.Next() {
+		var eventType string
+		require.NoError(t, rows.Scan(&eventType))
+		types = append(types, eventType)
+	}
+	require.NoError(t, rows.Err())
+
+	return types
+}
+
+func TestPgxTaskRepository_Create_And_Get(t *testing.T) {
+	truncateTasks(t)
+
+	ctx := context.Background()
+
+	created, err := testPgxRepo.Create(ctx, &domain.Task{
+		Title:     "pgx",
+		Status:    domain.StatusTodo,
+		Assignees: []string{"alice"},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+
+	found, err := testPgxRepo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, created, found)
+
+	// Rows written by one implementation are read identically by the other.
+	viaStdlib, err := testRepo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, found, viaStdlib)
+}
+
+func TestPgxTaskRepository_GetByID_NotFound(t *testing.T) {
+	truncateTasks(t)
+
+	task, err := testPgxRepo.GetByID(context.Background(), "00000000-0000-4000-8000-000000000000")
+
+	require.Nil(t, task)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestPgxTaskRepository_List(t *testing.T) {
+	truncateTasks(t)
+
+	ctx := context.Background()
+
+	for i, assignee := range []string{"alice", "bob", "alice"} {
+		_, err := testPgxRepo.Create(ctx, &domain.Task{
+			Title:     fmt.Sprintf("task %d", i),
+			Status:    domain.StatusTodo,
+			Assignees: []string{assignee},
+		})
+		require.NoError(t, err)
+	}
+
+	all, err := testPgxRepo.List(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	require.Equal(t, "task 2", all[0].Title)
+
+	alice := "alice"
+	mine, err := testPgxRepo.List(ctx, domain.TaskFilter{Participant: &alice, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	require.Equal(t, "task 0", mine[0].Title)
+
+	done := domain.StatusDone
+	none, err := testPgxRepo.List(ctx, domain.TaskFilter{Status: &done})
+	require.NoError(t, err)
+	require.Empty(t, none)
+}
+
+func TestPgxTaskRepository_WritesOutboxEvents(t *testing.T) {
+	truncateTasks(t)
+
+	ctx := context.Background()
+
+	created, err := testPgxRepo.Create(ctx, &domain.Task{
+		Title:  "outbox",
+		Status: domain.StatusTodo,
+	})
+	require.NoError(t, err)
+
+	created.Title = "renamed"
+	require.NoError(t, testPgxRepo.Update(ctx, created))
+
+	created.Status = domain.StatusDone
+	require.NoError(t, testPgxRepo.Update(ctx, created))
+
+	found, err := testPgxRepo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "renamed", found.Title)
+	require.Equal(t, domain.StatusDone, found.Status)
+
+	require.NoError(t, testPgxRepo.Delete(ctx, created.ID))
+
+	require.Equal(t, []string{
+		"task.created",
+		"task.updated",
+		"task.updated",
+		"task.status_changed",
+		"task.deleted",
+	}, outboxEventTypes(t, created.ID))
+}
+
+func TestPgxTaskRepository_UpdateDelete_NotFound(t *testing.T) {
+	truncateTasks(t)
+
+	ctx := context.Background()
+	missing := "00000000-0000-4000-8000-000000000000"
+
+	err := testPgxRepo.Update(ctx, &domain.Task{ID: missing, Title: "x", Status: domain.StatusDone})
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	err = testPgxRepo.Delete(ctx, missing)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.Empty(t, outboxEventTypes(t, missing))
+}
+
+func TestPgxTaskRepository_CreateMany(t *testing.T) {
+	truncateTasks(t)
+
+	ctx := context.Background()
+	bulk := testPgxRepo.(domain.TaskBulkCreator)
+
+	tasks := []*domain.Task{
+		{Title: "one", Status: domain.StatusTodo},
+		{Title: "two", Status: domain.StatusDone, Watchers: []string{"carol"}},
+	}
+
+	created, err := bulk.CreateMany(ctx, tasks)
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+
+	for _, task := range created {
+		found, err := testRepo.GetByID(ctx, task.ID)
+		require.NoError(t, err)
+		require.Equal(t, task, found)
+		require.Equal(t, []string{"task.created"}, outboxEventTypes(t, task.ID))
+	}
+}
+
+func TestPgxTaskRepository_CreateManyPagesStably(t *testing.T) {
+	requireDB(t)
+
+	ctx := context.Background()
+	bulk := testPgxRepo.(domain.TaskBulkCreator)
+
+	for name, repo := range map[string]domain.TaskRepository{
+		"stdlib":  testRepo,
+		"pgxpool": testPgxRepo,
+	} {
+		t.Run(name, func(t *testing.T) {
+			truncateTasks(t)
+
+			tasks := make([]*domain.Task, 7)
+			for i := range tasks {
+				tasks[i] = &domain.Task{Title: fmt.Sprint("task ", i), Status: domain.StatusTodo}
+			}
+			_, err := bulk.CreateMany(ctx, tasks)
+			require.NoError(t, err)
+
+			// The tasks of one COPY share created_at.
+			seen := map[string]bool{}
+			for offset := 0; offset < len(tasks); offset += 2 {
+				page, err := repo.List(ctx, domain.TaskFilter{Limit: 2, Offset: offset})
+				require.NoError(t, err)
+				for _, task := range page {
+					require.False(t, seen[task.ID], "task %s listed twice", task.Title)
+					seen[task.ID] = true
+				}
+			}
+			require.Len(t, seen, len(tasks))
+		})
+	}
+}
+
+// The benchmarks compare the database/sql and the pgxpool repositories on
+// the same database:
+//
+//	go test ./internal/repository/postgres -run '^$' -bench TaskRepository
+func benchmarkRepositories(b *testing.B, run func(b *testing.B, repo domain.TaskRepository)) {
+	for _, bm := range []struct {
+		name string
+		repo domain.TaskRepository
+	}{
+		{"stdlib", testRepo},
+		{"pgxpool", testPgxRepo},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			truncateTasks(b)
+			run(b, bm.repo)
+		})
+	}
+}
+
+func BenchmarkTaskRepository_Create(b *testing.B) {
+	benchmarkRepositories(b, func(b *testing.B, repo domain.TaskRepository) {
+		ctx := context.Background()
+
+		for b.Loop() {
+			_, err := repo.Create(ctx, &domain.Task{Title: "bench", Status: domain.StatusTodo})
+			require.NoError(b, err)
+		}
+	})
+}
+
+func BenchmarkTaskRepository_GetByID(b *testing.B) {
+	benchmarkRepositories(b, func(b *testing.B, repo domain.TaskRepository) {
+		ctx := context.Background()
+
+		task, err := repo.Create(ctx, &domain.Task{Title: "bench", Status: domain.StatusTodo})
+		require.NoError(b, err)
+
+		for b.Loop() {
+			_, err := repo.GetByID(ctx, task.ID)
+			require.NoError(b, err)
+		}
+	})
+}
+
+func BenchmarkTaskRepository_List(b *testing.B) {
+	benchmarkRepositories(b, func(b *testing.B, repo domain.TaskRepository) {
+		ctx := context.Background()
+
+		for i := range 100 {
+			_, err := repo.Create(ctx, &domain.Task{
+				Title:     fmt.Sprintf("bench %d", i),
+				Status:    domain.StatusTodo,
+				Assignees: []string{fmt.Sprintf("user%d", i%10)},
+			})
+			require.NoError(b, err)
+		}
+
+		assignee := "user3"
+
+		for b.Loop() {
+			_, err := repo.List(ctx, domain.TaskFilter{Assignee: &assignee, Limit: 20})
+			require.NoError(b, err)
+		}
+	})
+}
+
+func BenchmarkTaskRepository_Update(b *testing.B) {
+	benchmarkRepositories(b, func(b *testing.B, repo domain.TaskRepository) {
+		ctx := context.Background()
+
+		task, err := repo.Create(ctx, &domain.Task{Title: "bench", Status: domain.StatusTodo})
+		require.NoError(b, err)
+
+		statuses := []domain.TaskStatus{domain.StatusInProgress, domain.StatusDone}
+		i := 0
+
+		for b.Loop() {
+			task.Status = statuses[i%len(statuses)]
+			i++
+			require.NoError(b, repo.Update(ctx, task))
+		}
+	})
+}
+
+// BenchmarkTaskRepository_BulkCreate compares 100 single creations with one
+// COPY of 100 tasks.
+func BenchmarkTaskRepository_BulkCreate(b *testing.B) {
+	const size = 100
+
+	newTasks := func() []*domain.Task {
+		tasks := make([]*domain.Task, size)
+		for i := range tasks {
+			tasks[i] = &domain.Task{Title: fmt.Sprintf("bulk %d", i), Status: domain.StatusTodo}
+		}
+		return tasks
+	}
+
+	benchmarkRepositories(b, func(b *testing.B, repo domain.TaskRepository) {
+		ctx := context.Background()
+
+		for b.Loop() {
+			if bulk, ok := repo.(domain.TaskBulkCreator); ok {
+				_, err := bulk.CreateMany(ctx, newTasks())
+				require.NoError(b, err)
+				continue
+			}
+
+			for _, task := range newTasks() {
+				_, err := repo.Create(ctx, task)
+				require.NoError(b, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/logging"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RetryPolicy bounds how often an operation that failed transiently is
+// attempted again. The wait starts at Backoff and doubles on each retry.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, Backoff: 50 * time.Millisecond}
+}
+
+// IsRetryable reports whether err left nothing behind, so the whole
+// operation may run again: serialization failures and deadlocks roll the
+// transaction back, and connection errors raised before anything was sent
+// never reached the server. Connection errors on an established
+// connection, such as admin_shutdown or connection_exception, leave the
+// outcome unknown, e.g. during COMMIT, and are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", // serialization_failure
+			"40P01", // deadlock_detected
+			"57P03": // cannot_connect_now
+			return true
+		}
+	}
+
+	return errors.Is(err, driver.ErrBadConn) || pgconn.SafeToRetry(err)
+}
+
+// Retry runs fn until it succeeds, fails with an error that is not
+// retryable, or policy.MaxAttempts is reached. Inside a unit of work fn runs
+// once: a failed statement aborts the whole transaction, which only its
+// owner can run again.
+func Retry(ctx context.Context, policy RetryPolicy, op string, fn func() error) error {
+	if txFromContext(ctx) != nil {
+		return fn()
+	}
+
+	backoff := policy.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= policy.MaxAttempts || !IsRetryable(err) {
+			return err
+		}
+
+		logging.FromContext(ctx).WarnContext(ctx, "retrying database operation",
+			"operation", op,
+			"attempt", attempt,
+			"retry_in", backoff,
+			"error", err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+type retryingTaskRepository struct {
+	next   domain.TaskRepository
+	policy RetryPolicy
+}
+
+// NewRetryingTaskRepository retries each method of next on transient
+// errors. Outside a unit of work every method of the Postgres repositories
+// runs in its own transaction, so a retry replays the whole transaction.
+func NewRetryingTaskRepository(next domain.TaskRepository, policy RetryPolicy) domain.TaskRepository {
+	return &retryingTaskRepository{next: next, policy: policy}
+}
+
+func (r *retryingTaskRepository) Create(ctx context.Context, task *domain.Task) (created *domain.Task, err error) {
+	err = Retry(ctx, r.policy, "Create", func() error {
+		created, err = r.next.Create(ctx, task)
+		return err
+	})
+	return created, err
+}
+
+// CreateMany is retried when next stores tasks atomically; otherwise the
+// tasks are created one by one, each with its own retries.
+func (r *retryingTaskRepository) CreateMany(ctx context.Context, tasks []*domain.Task) (created []*domain.Task, err error) {
+	if _, ok := r.next.(domain.TaskBulkCreator); !ok {
+		created = make([]*domain.Task, 0, len(tasks))
+		for _, task := range tasks {
+			t, err := r.Create(ctx, task)
+			if err != nil {
+				return nil, err
+			}
+			created = append(created, t)
+		}
+		return created, nil
+	}
+
+	err = Retry(ctx, r.policy, "CreateMany", func() error {
+		created, err = domain.CreateTasks(ctx, r.next, tasks)
+		return err
+	})
+	return created, err
+}
+
+func (r *retryingTaskRepository) GetByID(ctx context.Context, id string) (task *domain.Task, err error) {
+	err = Retry(ctx, r.policy, "GetByID", func() error {
+		task, err = r.next.GetByID(ctx, id)
+		return err
+	})
+	return task, err
+}
+
+func (r *retryingTaskRepository) GetByIDs(ctx context.Context, ids []string) (tasks []*domain.Task, err error) {
+	err = Retry(ctx, r.policy, "GetByIDs", func() error {
+		tasks, err = domain.GetTasks(ctx, r.next, ids)
+		return err
+	})
+	return tasks, err
+}
+
+func (r *retryingTaskRepository) List(ctx context.Context, filter domain.TaskFilter) (tasks []*domain.Task, err error) {
+	err = Retry(ctx, r.policy, "List", func() error {
+		tasks, err = r.next.List(ctx, filter)
+		return err
+	})
+	return tasks, err
+}
+
+func (r *retryingTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	return Retry(ctx, r.policy, "Update", func() error {
+		return r.next.Update(ctx, task)
+	})
+}
+
+func (r *retryingTaskRepository) Delete(ctx context.Context, id string) error {
+	return Retry(ctx, r.policy, "Delete", func() error {
+		return r.next.Delete(ctx, id)
+	})
+}
+
+// Stream is not retried: fn may already have handled part of the tasks.
+func (r *retryingTaskRepository) Stream(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error {
+	return domain.StreamTasks(ctx, r.next, filter, fn)
+}
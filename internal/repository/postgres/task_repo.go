@@ -3,8 +3,12 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"graph-task-service/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type taskRepository struct {
@@ -15,29 +19,57 @@ func NewTaskRepository(db *sql.DB) domain.TaskRepository {
 	return &taskRepository{db: db}
 }
 
+// textArray lets database/sql scan a Postgres text[] column into a string slice.
+func textArray(dst *[]string) sql.Scanner {
+	return pgtype.NewMap().SQLScanner(dst)
+}
+
+// orEmpty keeps nil slices from being written as NULL into NOT NULL array columns.
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 func (r *taskRepository) Create(
 	ctx context.Context,
 	task *domain.Task,
 ) (*domain.Task, error) {
 
+	task.Assignees = orEmpty(task.Assignees)
+	task.Watchers = orEmpty(task.Watchers)
+
 	query := `
-		INSERT INTO tasks (title, status, assignee)
-		VALUES ($1, $2, $3)
+		INSERT INTO tasks (title, status, assignees, watchers)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(
-		ctx,
-		query,
-		task.Title,
-		task.Status,
-		task.Assignee,
-	).Scan(
-		&task.ID,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
+	err := withTx(ctx, r.db, func(q queryer) error {
+		qctx, span := startQuerySpan(ctx, "taskRepository.Create", query)
+
+		err := q.QueryRowContext(
+			qctx,
+			query,
+			task.Title,
+			task.Status,
+			task.Assignees,
+			task.Watchers,
+		).Scan(
+			&task.ID,
+			&task.CreatedAt,
+			&task.UpdatedAt,
+		)
+
+		endQuerySpan(span, rowCount(err), err)
+
+		if err != nil {
+			return err
+		}
 
+		return insertOutboxEvent(ctx, q, domain.EventTaskCreated, task)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -53,38 +85,92 @@ func (r *taskRepository) GetByID(
 	var task domain.Task
 
 	query := `
-		SELECT id, title, status, assignee, created_at, updated_at
+		SELECT id, title, status, assignees, watchers, created_at, updated_at
 		FROM tasks
 		WHERE id = $1
 	`
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	ctx, span := startQuerySpan(ctx, "taskRepository.GetByID", query)
+
+	err := dbFrom(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&task.ID,
 		&task.Title,
 		&task.Status,
-		&task.Assignee,
+		textArray(&task.Assignees),
+		textArray(&task.Watchers),
 		&task.CreatedAt,
 		&task.UpdatedAt,
 	)
 
-	if err == sql.ErrNoRows {
-		return nil, err
-	}
+	endQuerySpan(span, rowCount(err), noRowsIsNotAnError(err))
 
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 
 	return &task, nil
 }
 
-func (r *taskRepository) List(
+// GetByIDs implements domain.TaskBatchGetter with one query.
+func (r *taskRepository) GetByIDs(
 	ctx context.Context,
-	filter domain.TaskFilter,
-) ([]*domain.Task, error) {
+	ids []string,
+) (_ []*domain.Task, err error) {
+
+	ids = uuids(ids)
+	if len(ids) == 0 {
+		return nil, nil
+	}
 
 	query := `
-		SELECT id, title, status, assignee, created_at, updated_at
+		SELECT id, title, status, assignees, watchers, created_at, updated_at
+		FROM tasks
+		WHERE id = ANY($1)
+	`
+
+	ctx, span := startQuerySpan(ctx, "taskRepository.GetByIDs", query)
+
+	var tasks []*domain.Task
+	defer func() { endQuerySpan(span, int64(len(tasks)), err) }()
+
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTaskRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// uuids returns the ids that are UUIDs. No other id names a task, and
+// Postgres would reject the whole array because of one.
+func uuids(ids []string) []string {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		var u pgtype.UUID
+		if u.Scan(id) == nil {
+			valid = append(valid, id)
+		}
+	}
+	return valid
+}
+
+// listQuery builds the SELECT of the tasks matching filter, newest first.
+func listQuery(filter domain.TaskFilter) (string, []any) {
+	query := `
+		SELECT id, title, status, assignees, watchers, created_at, updated_at
 		FROM tasks
 		WHERE 1=1
 	`
@@ -97,13 +183,29 @@ func (r *taskRepository) List(
 		argID++
 	}
 
+	// Containment (@>) rather than = ANY, so the GIN indexes on the user
+	// arrays serve these filters.
 	if filter.Assignee != nil {
-		query += " AND assignee = $" + fmt.Sprint(argID)
+		query += " AND assignees @> ARRAY[$" + fmt.Sprint(argID) + "::text]"
 		args = append(args, *filter.Assignee)
 		argID++
 	}
 
-	query += " ORDER BY created_at DESC"
+	if filter.Watcher != nil {
+		query += " AND watchers @> ARRAY[$" + fmt.Sprint(argID) + "::text]"
+		args = append(args, *filter.Watcher)
+		argID++
+	}
+
+	if filter.Participant != nil {
+		query += " AND (assignees @> ARRAY[$" + fmt.Sprint(argID) + "::text] OR watchers @> ARRAY[$" + fmt.Sprint(argID) + "::text])"
+		args = append(args, *filter.Participant)
+		argID++
+	}
+
+	// Tasks created in one transaction share created_at; id keeps their
+	// order, and so the pages, stable.
+	query += " ORDER BY created_at DESC, id DESC"
 
 	if filter.Limit > 0 {
 		query += " LIMIT $" + fmt.Sprint(argID)
@@ -116,65 +218,176 @@ func (r *taskRepository) List(
 		args = append(args, filter.Offset)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
-	if err != nil {
+	return query, args
+}
+
+func scanTaskRow(rows *sql.Rows) (*domain.Task, error) {
+	var t domain.Task
+	if err := rows.Scan(
+		&t.ID,
+		&t.Title,
+		&t.Status,
+		textArray(&t.Assignees),
+		textArray(&t.Watchers),
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return &t, nil
+}
+
+func (r *taskRepository) List(
+	ctx context.Context,
+	filter domain.TaskFilter,
+) (_ []*domain.Task, err error) {
+
+	query, args := listQuery(filter)
+
+	ctx, span := startQuerySpan(ctx, "taskRepository.List", query)
 
 	var tasks []*domain.Task
+	defer func() { endQuerySpan(span, int64(len(tasks)), err) }()
+
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
 	for rows.Next() {
-		var t domain.Task
-		if err := rows.Scan(
-			&t.ID,
-			&t.Title,
-			&t.Status,
-			&t.Assignee,
-			&t.CreatedAt,
-			&t.UpdatedAt,
-		); err != nil {
+		t, err := scanTaskRow(rows)
+		if err != nil {
 			return nil, err
 		}
-		tasks = append(tasks, &t)
+		tasks = append(tasks, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return tasks, nil
 }
 
+// streamBatchSize is how many rows Stream fetches from its cursor at once.
+const streamBatchSize = 500
+
+// Stream implements domain.TaskStreamer with a server-side cursor, so only
+// one batch of rows is held in memory. The cursor lives in the caller's
+// unit of work or in a transaction of its own and reads one snapshot of the
+// tasks.
+func (r *taskRepository) Stream(
+	ctx context.Context,
+	filter domain.TaskFilter,
+	fn func(*domain.Task) error,
+) (err error) {
+
+	query, args := listQuery(filter)
+
+	ctx, span := startQuerySpan(ctx, "taskRepository.Stream", query)
+
+	var streamed int64
+	defer func() { endQuerySpan(span, streamed, err) }()
+
+	return withTx(ctx, r.db, func(q queryer) error {
+		if _, err := q.ExecContext(ctx, "DECLARE task_stream NO SCROLL CURSOR FOR "+query, args...); err != nil {
+			return err
+		}
+		// Commit closes the cursor too, but a unit of work may go on.
+		defer q.ExecContext(context.WithoutCancel(ctx), "CLOSE task_stream")
+
+		for {
+			n, err := fetchTasks(ctx, q, fn)
+			streamed += int64(n)
+			if err != nil || n < streamBatchSize {
+				return err
+			}
+		}
+	})
+}
+
+// fetchTasks passes the next batch of task_stream to fn and reports how
+// many rows it held.
+func fetchTasks(ctx context.Context, q queryer, fn func(*domain.Task) error) (int, error) {
+	rows, err := q.QueryContext(ctx, "FETCH "+fmt.Sprint(streamBatchSize)+" FROM task_stream")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		t, err := scanTaskRow(rows)
+		if err != nil {
+			return n, err
+		}
+		n++
+		if err := fn(t); err != nil {
+			return n, err
+		}
+	}
+
+	return n, rows.Err()
+}
+
 func (r *taskRepository) Update(
 	ctx context.Context,
 	task *domain.Task,
 ) error {
 
+	lockQuery := `SELECT status FROM tasks WHERE id = $1 FOR UPDATE`
+
 	query := `
 		UPDATE tasks
 		SET title = $1,
 		    status = $2,
-		    assignee = $3,
+		    assignees = $3,
+		    watchers = $4,
 		    updated_at = now()
-		WHERE id = $4
+		WHERE id = $5
+		RETURNING updated_at
 	`
 
-	res, err := r.db.ExecContext(
-		ctx,
-		query,
-		task.Title,
-		task.Status,
-		task.Assignee,
-		task.ID,
-	)
+	return withTx(ctx, r.db, func(q queryer) error {
+		var previous domain.TaskStatus
 
-	if err != nil {
-		return err
-	}
+		qctx, span := startQuerySpan(ctx, "taskRepository.Update.lock", lockQuery)
+		err := q.QueryRowContext(qctx, lockQuery, task.ID).Scan(&previous)
+		endQuerySpan(span, rowCount(err), noRowsIsNotAnError(err))
 
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return sql.ErrNoRows
-	}
+		if err != nil {
+			return notFound(err)
+		}
+
+		qctx, span = startQuerySpan(ctx, "taskRepository.Update", query)
+
+		err = q.QueryRowContext(
+			qctx,
+			query,
+			task.Title,
+			task.Status,
+			orEmpty(task.Assignees),
+			orEmpty(task.Watchers),
+			task.ID,
+		).Scan(&task.UpdatedAt)
+
+		endQuerySpan(span, rowCount(err), err)
 
-	return nil
+		if err != nil {
+			return err
+		}
+
+		if err := insertOutboxEvent(ctx, q, domain.EventTaskUpdated, task); err != nil {
+			return err
+		}
+
+		if previous == task.Status {
+			return nil
+		}
+
+		return insertOutboxEvent(ctx, q, domain.EventTaskStatusChanged, task)
+	})
 }
 
 func (r *taskRepository) Delete(
@@ -182,20 +395,60 @@ func (r *taskRepository) Delete(
 	id string,
 ) error {
 
-	res, err := r.db.ExecContext(
-		ctx,
-		`DELETE FROM tasks WHERE id = $1`,
-		id,
-	)
+	query := `
+		DELETE FROM tasks
+		WHERE id = $1
+		RETURNING id, title, status, assignees, watchers, created_at, updated_at
+	`
 
-	if err != nil {
-		return err
+	return withTx(ctx, r.db, func(q queryer) error {
+		var task domain.Task
+
+		qctx, span := startQuerySpan(ctx, "taskRepository.Delete", query)
+
+		err := q.QueryRowContext(qctx, query, id).Scan(
+			&task.ID,
+			&task.Title,
+			&task.Status,
+			textArray(&task.Assignees),
+			textArray(&task.Watchers),
+			&task.CreatedAt,
+			&task.UpdatedAt,
+		)
+
+		endQuerySpan(span, rowCount(err), noRowsIsNotAnError(err))
+
+		if err != nil {
+			return notFound(err)
+		}
+
+		return insertOutboxEvent(ctx, q, domain.EventTaskDeleted, &task)
+	})
+}
+
+// notFound maps a missing row to domain.ErrNotFound. An id that is not a
+// UUID cannot exist either, so invalid_text_representation maps too.
+func notFound(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.Is(err, sql.ErrNoRows) || errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+		return domain.ErrNotFound
 	}
+	return err
+}
 
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return sql.ErrNoRows
+// rowCount is the number of rows a single-row statement produced.
+func rowCount(err error) int64 {
+	if err != nil {
+		return 0
 	}
+	return 1
+}
 
-	return nil
+// noRowsIsNotAnError keeps lookups of missing tasks from being reported as
+// failed spans. pgx.ErrNoRows matches sql.ErrNoRows too.
+func noRowsIsNotAnError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
 }
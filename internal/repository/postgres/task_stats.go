@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"graph-task-service/internal/domain"
+)
+
+type taskStats struct {
+	db *sql.DB
+}
+
+func NewTaskStats(db *sql.DB) domain.TaskCounter {
+	return &taskStats{db: db}
+}
+
+func (s *taskStats) CountByStatus(
+	ctx context.Context,
+) (map[domain.TaskStatus]int, error) {
+
+	rows, err := dbFrom(ctx, s.db).QueryContext(ctx, `SELECT status, count(*) FROM tasks GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.TaskStatus]int)
+
+	for rows.Next() {
+		var (
+			status domain.TaskStatus
+			count  int
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"graph-task-service/internal/logging"
+	"graph-task-service/internal/observability"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// querySpan is the client span of one SQL statement plus what is needed to
+// log it once it completes.
+type querySpan struct {
+	trace.Span
+	ctx       context.Context
+	name      string
+	statement string
+	start     time.Time
+}
+
+// startQuerySpan opens a client span for one SQL statement. name identifies
+// the repository method, e.g. "taskRepository.List".
+func startQuerySpan(ctx context.Context, name string, statement string) (context.Context, querySpan) {
+	statement = strings.Join(strings.Fields(statement), " ")
+
+	ctx, span := observability.Tracer().Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", statement),
+		),
+	)
+
+	return ctx, querySpan{Span: span, ctx: ctx, name: name, statement: statement, start: time.Now()}
+}
+
+// endQuerySpan records how many rows the statement returned or affected and
+// logs the statement at debug level with the request logger.
+func endQuerySpan(span querySpan, rows int64, err error) {
+	span.SetAttributes(attribute.Int64("db.rows", rows))
+	observability.EndSpan(span.Span, err)
+
+	logger := logging.FromContext(span.ctx)
+	if !logger.Enabled(span.ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("query", span.name),
+		slog.String("statement", span.statement),
+		slog.Int64("rows", rows),
+		slog.Duration("duration", time.Since(span.start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	logger.LogAttrs(span.ctx, slog.LevelDebug, "db query", attrs...)
+}
@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// queryer is what repositories run statements on: the pool, or the
+// transaction of the caller's unit of work.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txState is the unit of work carried in the context.
+type txState struct {
+	tx *sql.Tx
+	// conn is the pgx connection tx runs on, so the pgx task repository can
+	// join the unit of work. It is nil for other drivers.
+	conn *pgx.Conn
+	// depth counts the savepoints enclosing the current call.
+	depth int
+	// afterCommit is shared by the nested states of one transaction.
+	afterCommit *[]func()
+}
+
+type txKey struct{}
+
+func contextWithTx(ctx context.Context, state *txState) context.Context {
+	return context.WithValue(ctx, txKey{}, state)
+}
+
+func txFromContext(ctx context.Context) *txState {
+	state, _ := ctx.Value(txKey{}).(*txState)
+	return state
+}
+
+// InTx reports whether ctx carries a unit of work.
+func InTx(ctx context.Context) bool {
+	return txFromContext(ctx) != nil
+}
+
+// AfterCommit runs fn once the unit of work of ctx has committed, or right
+// away without one. fn is dropped when the transaction rolls back.
+func AfterCommit(ctx context.Context, fn func()) {
+	state := txFromContext(ctx)
+	if state == nil {
+		fn()
+		return
+	}
+	*state.afterCommit = append(*state.afterCommit, fn)
+}
+
+// dbFrom returns the transaction of the caller's unit of work, or db.
+func dbFrom(ctx context.Context, db *sql.DB) queryer {
+	if state := txFromContext(ctx); state != nil {
+		return state.tx
+	}
+	return db
+}
+
+// withTx runs fn in the caller's unit of work or, without one, in a new
+// transaction that is committed when fn succeeds.
+func withTx(ctx context.Context, db *sql.DB, fn func(q queryer) error) error {
+	if state := txFromContext(ctx); state != nil {
+		return fn(state.tx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type txManager struct {
+	db     *sql.DB
+	level  domain.IsolationLevel
+	policy RetryPolicy
+}
+
+// NewTxManager runs units of work on db with level as default isolation.
+// Top-level transactions failing with a retryable error, e.g. a
+// serialization failure under repeatable read, are run again as a whole
+// according to policy, so fn must not have side effects outside the
+// database.
+func NewTxManager(db *sql.DB, level domain.IsolationLevel, policy RetryPolicy) domain.TxManager {
+	return &txManager{db: db, level: level, policy: policy}
+}
+
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.WithinTxLevel(ctx, domain.IsolationDefault, fn)
+}
+
+func (m *txManager) WithinTxLevel(
+	ctx context.Context,
+	level domain.IsolationLevel,
+	fn func(ctx context.Context) error,
+) error {
+
+	if state := txFromContext(ctx); state != nil {
+		return savepoint(ctx, state, fn)
+	}
+
+	if level == domain.IsolationDefault {
+		level = m.level
+	}
+
+	isolation, err := sqlIsolation(level)
+	if err != nil {
+		return err
+	}
+
+	return Retry(ctx, m.policy, "WithinTx", func() error {
+		return m.run(ctx, isolation, fn)
+	})
+}
+
+func (m *txManager) run(
+	ctx context.Context,
+	isolation sql.IsolationLevel,
+	fn func(ctx context.Context) error,
+) error {
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	state := &txState{afterCommit: new([]func())}
+	_ = conn.Raw(func(driverConn any) error {
+		if c, ok := driverConn.(*stdlib.Conn); ok {
+			state.conn = c.Conn()
+		}
+		return nil
+	})
+
+	state.tx, err = conn.BeginTx(ctx, &sql.TxOptions{Isolation: isolation})
+	if err != nil {
+		return err
+	}
+	// Also rolls back when fn panics.
+	defer state.tx.Rollback()
+
+	if err := fn(contextWithTx(ctx, state)); err != nil {
+		return err
+	}
+
+	if err := state.tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, hook := range *state.afterCommit {
+		hook()
+	}
+
+	return nil
+}
+
+// savepoint runs fn nested in the unit of work of state and undoes only
+// fn's changes when it fails.
+func savepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) (err error) {
+	nested := &txState{tx: state.tx, conn: state.conn, depth: state.depth + 1, afterCommit: state.afterCommit}
+	name := "sp_" + strconv.Itoa(nested.depth)
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	released := false
+	defer func() {
+		if released {
+			return
+		}
+		if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil && err != nil {
+			err = errors.Join(err, rbErr)
+		}
+	}()
+
+	if err := fn(contextWithTx(ctx, nested)); err != nil {
+		return err
+	}
+
+	_, err = state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	released = err == nil
+	return err
+}
+
+func sqlIsolation(level domain.IsolationLevel) (sql.IsolationLevel, error) {
+	switch level {
+	case domain.IsolationDefault:
+		return sql.LevelDefault, nil
+	case domain.IsolationReadCommitted:
+		return sql.LevelReadCommitted, nil
+	case domain.IsolationRepeatableRead:
+		return sql.LevelRepeatableRead, nil
+	case domain.IsolationSerializable:
+		return sql.LevelSerializable, nil
+	default:
+		return 0, fmt.Errorf("unsupported isolation level %q", level)
+	}
+}
@@ -0,0 +1,205 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/repository/postgres"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errAbort = errors.New("abort")
+
+func newTestTxManager(t *testing.T) domain.TxManager {
+	requireDB(t)
+	return postgres.NewTxManager(testDB, domain.IsolationReadCommitted, fastRetry)
+}
+
+func countTasks(t *testing.T) int {
+	var n int
+	require.NoError(t, testDB.QueryRow(`SELECT count(*) FROM tasks`).Scan(&n))
+	return n
+}
+
+func TestTxManager_CommitsOnSuccess(t *testing.T) {
+	truncateTasks(t)
+
+	err := newTestTxManager(t).WithinTx(context.Background(), func(ctx context.Context) error {
+		_, err := testRepo.Create(ctx, &domain.Task{Title: "one", Status: domain.StatusTodo})
+		if err != nil {
+			return err
+		}
+		_, err = testRepo.Create(ctx, &domain.Task{Title: "two", Status: domain.StatusTodo})
+		return err
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, countTasks(t))
+}
+
+func TestTxManager_RollsBackOnError(t *testing.T) {
+	for name, repo := range map[string]domain.TaskRepository{
+		"stdlib":  testRepo,
+		"pgxpool": testPgxRepo,
+	} {
+		t.Run(name, func(t *testing.T) {
+			truncateTasks(t)
+
+			err := newTestTxManager(t).WithinTx(context.Background(), func(ctx context.Context) error {
+				created, err := repo.Create(ctx, &domain.Task{Title: "doomed", Status: domain.StatusTodo})
+				require.NoError(t, err)
+
+				// The uncommitted task is visible inside the unit of work.
+				_, err = repo.GetByID(ctx, created.ID)
+				require.NoError(t, err)
+
+				return errAbort
+			})
+
+			require.ErrorIs(t, err, errAbort)
+			assert.Zero(t, countTasks(t))
+
+			var events int
+			require.NoError(t, testDB.QueryRow(`SELECT count(*) FROM outbox_events`).Scan(&events))
+			assert.Zero(t, events)
+		})
+	}
+}
+
+func TestTxManager_RollsBackOnPanic(t *testing.T) {
+	truncateTasks(t)
+
+	assert.Panics(t, func() {
+		_ = newTestTxManager(t).WithinTx(context.Background(), func(ctx context.Context) error {
+			_, err := testRepo.Create(ctx, &domain.Task{Title: "doomed", Status: domain.StatusTodo})
+			require.NoError(t, err)
+			panic("boom")
+		})
+	})
+
+	assert.Zero(t, countTasks(t))
+}
+
+func TestTxManager_NestedSavepointRollsBackOnlyInnerWork(t *testing.T) {
+	truncateTasks(t)
+
+	txm := newTestTxManager(t)
+
+	err := txm.WithinTx(context.Background(), func(ctx context.Context) error {
+		if _, err := testRepo.Create(ctx, &domain.Task{Title: "outer", Status: domain.StatusTodo}); err != nil {
+			return err
+		}
+
+		inner := txm.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := testRepo.Create(ctx, &domain.Task{Title: "inner", Status: domain.StatusTodo}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		require.ErrorIs(t, inner, errAbort)
+
+		return txm.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := testRepo.Create(ctx, &domain.Task{Title: "kept", Status: domain.StatusTodo})
+			return err
+		})
+	})
+	require.NoError(t, err)
+
+	tasks, err := testRepo.List(context.Background(), domain.TaskFilter{})
+	require.NoError(t, err)
+
+	var titles []string
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	assert.ElementsMatch(t, []string{"outer", "kept"}, titles)
+}
+
+func TestTxManager_IsolationLevel(t *testing.T) {
+	txm := newTestTxManager(t)
+
+	// seesConcurrentInsert reports whether a task committed by another
+	// connection during the unit of work shows up in its second read.
+	seesConcurrentInsert := func(level domain.IsolationLevel) bool {
+		truncateTasks(t)
+
+		var before, after []*domain.Task
+		err := txm.WithinTxLevel(context.Background(), level, func(ctx context.Context) error {
+			var err error
+			if before, err = testRepo.List(ctx, domain.TaskFilter{}); err != nil {
+				return err
+			}
+
+			if _, err := testRepo.Create(context.Background(), &domain.Task{Title: "concurrent", Status: domain.StatusTodo}); err != nil {
+				return err
+			}
+
+			after, err = testRepo.List(ctx, domain.TaskFilter{})
+			return err
+		})
+		require.NoError(t, err)
+
+		return len(after) > len(before)
+	}
+
+	assert.True(t, seesConcurrentInsert(domain.IsolationDefault), "read committed by default")
+	assert.True(t, seesConcurrentInsert(domain.IsolationReadCommitted))
+	assert.False(t, seesConcurrentInsert(domain.IsolationRepeatableRead))
+	assert.False(t, seesConcurrentInsert(domain.IsolationSerializable))
+
+	err := txm.WithinTxLevel(context.Background(), "chaos", func(ctx context.Context) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestTxManager_RepositoryRetriesAreLeftToTheUnitOfWork(t *testing.T) {
+	next := &flakyTaskRepo{errs: []error{
+		&pgconn.PgError{Code: "40001"},
+	}}
+	repo := postgres.NewRetryingTaskRepository(next, fastRetry)
+
+	attempts := 0
+	err := newTestTxManager(t).WithinTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		_, err := repo.GetByID(ctx, "42")
+		return err
+	})
+
+	require.NoError(t, err)
+	// The repository call is not retried inside the transaction; the whole
+	// unit of work is.
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestTxManager_AfterCommit(t *testing.T) {
+	tx := newTestTxManager(t)
+	ctx := context.Background()
+
+	var ran []string
+	postgres.AfterCommit(ctx, func() { ran = append(ran, "no tx") })
+	assert.Equal(t, []string{"no tx"}, ran, "runs right away without a unit of work")
+
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		assert.True(t, postgres.InTx(ctx))
+		postgres.AfterCommit(ctx, func() { ran = append(ran, "outer") })
+
+		return tx.WithinTx(ctx, func(ctx context.Context) error {
+			postgres.AfterCommit(ctx, func() { ran = append(ran, "nested") })
+			assert.Len(t, ran, 1, "deferred until commit")
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"no tx", "outer", "nested"}, ran)
+
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		postgres.AfterCommit(ctx, func() { ran = append(ran, "rolled back") })
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+	assert.Len(t, ran, 3, "dropped on rollback")
+}
@@ -0,0 +1,388 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"graph-task-service/internal/domain"
+	"time"
+)
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) domain.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func eventsToStrings(events []domain.EventType) []string {
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		out = append(out, string(e))
+	}
+	return out
+}
+
+func stringsToEvents(values []string) []domain.EventType {
+	out := make([]domain.EventType, 0, len(values))
+	for _, v := range values {
+		out = append(out, domain.EventType(v))
+	}
+	return out
+}
+
+func (r *webhookRepository) Create(
+	ctx context.Context,
+	webhook *domain.Webhook,
+) (*domain.Webhook, error) {
+
+	query := `
+		INSERT INTO webhooks (url, secret, events, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := dbFrom(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		webhook.URL,
+		webhook.Secret,
+		eventsToStrings(webhook.Events),
+		webhook.Active,
+	).Scan(
+		&webhook.ID,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (r *webhookRepository) GetByID(
+	ctx context.Context,
+	id string,
+) (*domain.Webhook, error) {
+
+	query := `
+		SELECT id, url, secret, events, active, created_at, updated_at
+		FROM webhooks
+		WHERE id = $1
+	`
+
+	webhook, err := scanWebhook(dbFrom(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return webhook, nil
+}
+
+func (r *webhookRepository) List(
+	ctx context.Context,
+) ([]*domain.Webhook, error) {
+
+	query := `
+		SELECT id, url, secret, events, active, created_at, updated_at
+		FROM webhooks
+		ORDER BY created_at
+	`
+
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*domain.Webhook
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r *webhookRepository) Update(
+	ctx context.Context,
+	webhook *domain.Webhook,
+) error {
+
+	query := `
+		UPDATE webhooks
+		SET url = $1,
+		    events = $2,
+		    active = $3,
+		    updated_at = now()
+		WHERE id = $4
+	`
+
+	res, err := dbFrom(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		webhook.URL,
+		eventsToStrings(webhook.Events),
+		webhook.Active,
+		webhook.ID,
+	)
+
+	if err != nil {
+		return notFound(err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) Delete(
+	ctx context.Context,
+	id string,
+) error {
+
+	res, err := dbFrom(ctx, r.db).ExecContext(
+		ctx,
+		`DELETE FROM webhooks WHERE id = $1`,
+		id,
+	)
+
+	if err != nil {
+		return notFound(err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(row rowScanner) (*domain.Webhook, error) {
+	var (
+		webhook domain.Webhook
+		events  []string
+	)
+
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		textArray(&events),
+		&webhook.Active,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Events = stringsToEvents(events)
+
+	return &webhook, nil
+}
+
+type webhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookDeliveryRepository(db *sql.DB) domain.WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db}
+}
+
+const deliveryColumns = `
+	id, webhook_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_error, response_code, created_at, delivered_at
+`
+
+func (r *webhookDeliveryRepository) Enqueue(
+	ctx context.Context,
+	deliveries []*domain.WebhookDelivery,
+) error {
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+		RETURNING id, status, next_attempt_at, created_at
+	`
+
+	return withTx(ctx, r.db, func(q queryer) error {
+		for _, d := range deliveries {
+			err := q.QueryRowContext(
+				ctx,
+				query,
+				d.WebhookID,
+				d.EventID,
+				d.EventType,
+				d.Payload,
+			).Scan(
+				&d.ID,
+				&d.Status,
+				&d.NextAttemptAt,
+				&d.CreatedAt,
+			)
+			// A redelivered event was already queued for this webhook.
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *webhookDeliveryRepository) ClaimDue(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]*domain.WebhookDelivery, error) {
+
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+func (r *webhookDeliveryRepository) MarkSucceeded(
+	ctx context.Context,
+	id string,
+	responseCode int,
+) error {
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'succeeded',
+		    attempts = attempts + 1,
+		    response_code = $1,
+		    last_error = NULL,
+		    delivered_at = now()
+		WHERE id = $2
+	`
+
+	_, err := dbFrom(ctx, r.db).ExecContext(ctx, query, responseCode, id)
+	return err
+}
+
+func (r *webhookDeliveryRepository) MarkFailed(
+	ctx context.Context,
+	id string,
+	responseCode *int,
+	lastErr string,
+	retryIn *time.Duration,
+) error {
+
+	if retryIn == nil {
+		query := `
+			UPDATE webhook_deliveries
+			SET status = 'failed',
+			    attempts = attempts + 1,
+			    response_code = $1,
+			    last_error = $2
+			WHERE id = $3
+		`
+
+		_, err := dbFrom(ctx, r.db).ExecContext(ctx, query, responseCode, lastErr, id)
+		return err
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+		    response_code = $1,
+		    last_error = $2,
+		    next_attempt_at = now() + make_interval(secs => $3)
+		WHERE id = $4
+	`
+
+	_, err := dbFrom(ctx, r.db).ExecContext(ctx, query, responseCode, lastErr, retryIn.Seconds(), id)
+	return err
+}
+
+func (r *webhookDeliveryRepository) ListByWebhook(
+	ctx context.Context,
+	webhookID string,
+	limit int,
+	offset int,
+) ([]*domain.WebhookDelivery, error) {
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := dbFrom(ctx, r.db).QueryContext(ctx, query, webhookID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+func scanDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		if err := rows.Scan(
+			&d.ID,
+			&d.WebhookID,
+			&d.EventID,
+			&d.EventType,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastError,
+			&d.ResponseCode,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
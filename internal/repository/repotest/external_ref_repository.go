@@ -0,0 +1,74 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExternalRefRepository runs the conformance suite of external
+// references. newRepos returns an empty reference repository and the task
+// repository its links point into; it is called once per subtest.
+func TestExternalRefRepository(
+	t *testing.T,
+	newRepos func(t *testing.T) (domain.ExternalRefRepository, domain.TaskRepository),
+) {
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, refs domain.ExternalRefRepository, tasks domain.TaskRepository)
+	}{
+		{"PutAndGet", testExternalRefPutAndGet},
+		{"PutReplaces", testExternalRefPutReplaces},
+		{"NotFound", testExternalRefNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, tasks := newRepos(t)
+			tt.run(t, refs, tasks)
+		})
+	}
+}
+
+func createRefTask(t *testing.T, tasks domain.TaskRepository) string {
+	task, err := tasks.Create(context.Background(), &domain.Task{Title: "imported", Status: domain.StatusTodo})
+	require.NoError(t, err)
+	return task.ID
+}
+
+func testExternalRefPutAndGet(t *testing.T, refs domain.ExternalRefRepository, tasks domain.TaskRepository) {
+	ctx := context.Background()
+	taskID := createRefTask(t, tasks)
+
+	require.NoError(t, refs.Put(ctx, &domain.ExternalRef{Source: "jira", ExternalID: "10001", TaskID: taskID}))
+
+	found, err := refs.Get(ctx, "jira", "10001")
+	require.NoError(t, err)
+	assert.Equal(t, &domain.ExternalRef{Source: "jira", ExternalID: "10001", TaskID: taskID}, found)
+
+	// The same ID from another tracker is another item.
+	_, err = refs.Get(ctx, "trello", "10001")
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "Get: %v", err)
+}
+
+func testExternalRefPutReplaces(t *testing.T, refs domain.ExternalRefRepository, tasks domain.TaskRepository) {
+	ctx := context.Background()
+	first, second := createRefTask(t, tasks), createRefTask(t, tasks)
+
+	require.NoError(t, refs.Put(ctx, &domain.ExternalRef{Source: "github", ExternalID: "42", TaskID: first}))
+	require.NoError(t, refs.Put(ctx, &domain.ExternalRef{Source: "github", ExternalID: "42", TaskID: second}))
+
+	found, err := refs.Get(ctx, "github", "42")
+	require.NoError(t, err)
+	assert.Equal(t, second, found.TaskID)
+}
+
+func testExternalRefNotFound(t *testing.T, refs domain.ExternalRefRepository, tasks domain.TaskRepository) {
+	_, err := refs.Get(context.Background(), "jira", "missing")
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "Get: %v", err)
+}
@@ -0,0 +1,129 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImportJobRepository runs the conformance suite of import jobs.
+// newRepo returns an empty repository and is called once per subtest.
+func TestImportJobRepository(t *testing.T, newRepo func(t *testing.T) domain.ImportJobRepository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo domain.ImportJobRepository)
+	}{
+		{"CreateAndGet", testImportJobCreateAndGet},
+		{"Update", testImportJobUpdate},
+		{"NotFound", testImportJobNotFound},
+		{"FailStale", testImportJobFailStale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+func testImportJobCreateAndGet(t *testing.T, repo domain.ImportJobRepository) {
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, &domain.ImportJob{
+		CreatedBy: "alice",
+		Status:    domain.ImportPending,
+		Result:    domain.ImportResult{DryRun: true, Rows: 3},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.ID)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	found, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", found.CreatedBy)
+	assert.Equal(t, domain.ImportPending, found.Status)
+	assert.Equal(t, domain.ImportResult{DryRun: true, Rows: 3}, found.Result)
+	assert.Nil(t, found.Error)
+	assert.Nil(t, found.FinishedAt)
+}
+
+func testImportJobUpdate(t *testing.T, repo domain.ImportJobRepository) {
+	ctx := context.Background()
+
+	job, err := repo.Create(ctx, &domain.ImportJob{Status: domain.ImportRunning, Result: domain.ImportResult{Rows: 2}})
+	require.NoError(t, err)
+
+	finished := time.Now().UTC().Truncate(time.Second)
+	msg := "database down"
+
+	job.Status = domain.ImportFailed
+	job.Result.Invalid = 1
+	job.Result.Errors = []domain.ImportRowError{{Line: 3, Message: "title cannot be empty"}}
+	job.Error = &msg
+	job.FinishedAt = &finished
+	require.NoError(t, repo.Update(ctx, job))
+
+	found, err := repo.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImportFailed, found.Status)
+	assert.Equal(t, job.Result, found.Result)
+	require.NotNil(t, found.Error)
+	assert.Equal(t, msg, *found.Error)
+	require.NotNil(t, found.FinishedAt)
+	assert.True(t, finished.Equal(*found.FinishedAt))
+}
+
+func testImportJobNotFound(t *testing.T, repo domain.ImportJobRepository) {
+	ctx := context.Background()
+
+	_, err := repo.GetByID(ctx, missingID)
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "GetByID: %v", err)
+
+	err = repo.Update(ctx, &domain.ImportJob{ID: missingID, Status: domain.ImportFailed})
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "Update: %v", err)
+
+	// Job ids come from URLs, so they may be anything.
+	_, err = repo.GetByID(ctx, "not-a-uuid")
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "GetByID of an invalid id: %v", err)
+}
+
+func testImportJobFailStale(t *testing.T, repo domain.ImportJobRepository) {
+	ctx := context.Background()
+
+	var jobs []*domain.ImportJob
+	for _, status := range []domain.ImportStatus{domain.ImportPending, domain.ImportRunning, domain.ImportSucceeded} {
+		job, err := repo.Create(ctx, &domain.ImportJob{Status: status})
+		require.NoError(t, err)
+		jobs = append(jobs, job)
+	}
+	require.NoError(t, repo.Touch(ctx, jobs[0].ID))
+
+	failed, err := repo.FailStale(ctx, time.Hour, "abandoned")
+	require.NoError(t, err)
+	assert.Zero(t, failed, "jobs updated within olderThan stay")
+
+	time.Sleep(10 * time.Millisecond)
+
+	failed, err = repo.FailStale(ctx, 5*time.Millisecond, "abandoned")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, failed)
+
+	for i, job := range jobs {
+		found, err := repo.GetByID(ctx, job.ID)
+		require.NoError(t, err)
+
+		if i == 2 {
+			assert.Equal(t, domain.ImportSucceeded, found.Status, "finished jobs stay")
+			continue
+		}
+		assert.Equal(t, domain.ImportFailed, found.Status)
+		require.NotNil(t, found.Error)
+		assert.Equal(t, "abandoned", *found.Error)
+		assert.NotNil(t, found.FinishedAt)
+	}
+}
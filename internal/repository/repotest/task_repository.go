@@ -0,0 +1,365 @@
+// Package repotest holds conformance tests every domain.TaskRepository and
+// domain.ImportJobRepository implementation has to pass, so the backends
+// stay interchangeable.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// missingID is a well-formed id no repository hands out.
+const missingID = "00000000-0000-4000-8000-000000000000"
+
+// TestTaskRepository runs the conformance suite. newRepo returns an empty
+// repository and is called once per subtest.
+func TestTaskRepository(t *testing.T, newRepo func(t *testing.T) domain.TaskRepository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo domain.TaskRepository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"NotFound", testNotFound},
+		{"GetTasks", testGetTasks},
+		{"Update", testUpdate},
+		{"Delete", testDelete},
+		{"ListOrder", testListOrder},
+		{"ListFilters", testListFilters},
+		{"ListPagination", testListPagination},
+		{"Stream", testStream},
+		{"CreateTasks", testCreateTasks},
+		{"ReturnsCopies", testReturnsCopies},
+		{"ConcurrentCreates", testConcurrentCreates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+func create(t *testing.T, repo domain.TaskRepository, task domain.Task) *domain.Task {
+	t.Helper()
+
+	created, err := repo.Create(context.Background(), &task)
+	require.NoError(t, err)
+	return created
+}
+
+func titles(tasks []*domain.Task) []string {
+	out := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		out = append(out, task.Title)
+	}
+	return out
+}
+
+func testCreateAndGet(t *testing.T, repo domain.TaskRepository) {
+	before := time.Now().Add(-time.Second)
+
+	created := create(t, repo, domain.Task{
+		Title:     "write tests",
+		Status:    domain.StatusInProgress,
+		Assignees: []string{"alice", "bob"},
+	})
+
+	require.NotEmpty(t, created.ID)
+	assert.True(t, created.CreatedAt.After(before), "created_at %s", created.CreatedAt)
+	assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+	assert.NotNil(t, created.Watchers, "missing users are an empty list")
+
+	found, err := repo.GetByID(context.Background(), created.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, created.ID, found.ID)
+	assert.Equal(t, "write tests", found.Title)
+	assert.Equal(t, domain.StatusInProgress, found.Status)
+	assert.Equal(t, []string{"alice", "bob"}, found.Assignees)
+	assert.Empty(t, found.Watchers)
+	assert.True(t, created.CreatedAt.Equal(found.CreatedAt), "%s != %s", created.CreatedAt, found.CreatedAt)
+	assert.True(t, created.UpdatedAt.Equal(found.UpdatedAt), "%s != %s", created.UpdatedAt, found.UpdatedAt)
+}
+
+func testNotFound(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	for _, id := range []string{missingID, "999", ""} {
+		task, err := repo.GetByID(ctx, id)
+		assert.Nil(t, task)
+		assert.ErrorIs(t, err, domain.ErrNotFound, "GetByID(%q)", id)
+
+		err = repo.Update(ctx, &domain.Task{ID: id, Title: "x", Status: domain.StatusDone})
+		assert.ErrorIs(t, err, domain.ErrNotFound, "Update(%q)", id)
+
+		err = repo.Delete(ctx, id)
+		assert.ErrorIs(t, err, domain.ErrNotFound, "Delete(%q)", id)
+	}
+}
+
+func testGetTasks(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	first := create(t, repo, domain.Task{Title: "first", Status: domain.StatusTodo, Assignees: []string{"alice"}})
+	second := create(t, repo, domain.Task{Title: "second", Status: domain.StatusDone})
+	create(t, repo, domain.Task{Title: "third", Status: domain.StatusTodo})
+
+	// Whether or not the repository is a domain.TaskBatchGetter, unknown
+	// and malformed ids are left out and duplicates are returned once.
+	tasks, err := domain.GetTasks(ctx, repo, []string{second.ID, missingID, first.ID, "999", second.ID})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"first", "second"}, titles(tasks))
+
+	for _, task := range tasks {
+		if task.ID == first.ID {
+			assert.Equal(t, []string{"alice"}, task.Assignees)
+			assert.Empty(t, task.Watchers)
+			assert.True(t, first.CreatedAt.Equal(task.CreatedAt), "%s != %s", first.CreatedAt, task.CreatedAt)
+		}
+	}
+
+	tasks, err = domain.GetTasks(ctx, repo, nil)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func testUpdate(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	created := create(t, repo, domain.Task{Title: "draft", Status: domain.StatusTodo})
+	createdAt := created.CreatedAt
+
+	time.Sleep(2 * time.Millisecond)
+
+	created.Title = "final"
+	created.Status = domain.StatusDone
+	created.Assignees = []string{"carol"}
+	created.Watchers = []string{"dave"}
+	require.NoError(t, repo.Update(ctx, created))
+
+	assert.True(t, created.UpdatedAt.After(createdAt), "updated_at moves forward")
+
+	found, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, "final", found.Title)
+	assert.Equal(t, domain.StatusDone, found.Status)
+	assert.Equal(t, []string{"carol"}, found.Assignees)
+	assert.Equal(t, []string{"dave"}, found.Watchers)
+	assert.True(t, createdAt.Equal(found.CreatedAt), "created_at is kept")
+	assert.True(t, created.UpdatedAt.Equal(found.UpdatedAt), "%s != %s", created.UpdatedAt, found.UpdatedAt)
+
+	found.Assignees = nil
+	require.NoError(t, repo.Update(ctx, found))
+
+	found, err = repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, found.Assignees)
+}
+
+func testDelete(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	kept := create(t, repo, domain.Task{Title: "kept", Status: domain.StatusTodo})
+	deleted := create(t, repo, domain.Task{Title: "deleted", Status: domain.StatusTodo})
+
+	require.NoError(t, repo.Delete(ctx, deleted.ID))
+
+	_, err := repo.GetByID(ctx, deleted.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	assert.ErrorIs(t, repo.Delete(ctx, deleted.ID), domain.ErrNotFound)
+
+	_, err = repo.GetByID(ctx, kept.ID)
+	assert.NoError(t, err)
+}
+
+func testListOrder(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	tasks, err := repo.List(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	for i := range 3 {
+		create(t, repo, domain.Task{Title: fmt.Sprintf("task %d", i), Status: domain.StatusTodo})
+		// Keep creation times apart on clocks with coarse resolution.
+		time.Sleep(time.Millisecond)
+	}
+
+	tasks, err = repo.List(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task 2", "task 1", "task 0"}, titles(tasks), "newest first")
+}
+
+func testListFilters(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	create(t, repo, domain.Task{Title: "alice todo", Status: domain.StatusTodo, Assignees: []string{"alice"}})
+	create(t, repo, domain.Task{Title: "bob done", Status: domain.StatusDone, Assignees: []string{"bob"}, Watchers: []string{"alice"}})
+	create(t, repo, domain.Task{Title: "both todo", Status: domain.StatusTodo, Assignees: []string{"alice", "bob"}})
+	create(t, repo, domain.Task{Title: "nobody", Status: domain.StatusInProgress})
+
+	list := func(filter domain.TaskFilter) []string {
+		tasks, err := repo.List(ctx, filter)
+		require.NoError(t, err)
+		return titles(tasks)
+	}
+
+	todo := domain.StatusTodo
+	alice, bob, eve := "alice", "bob", "eve"
+
+	assert.ElementsMatch(t, []string{"alice todo", "both todo"}, list(domain.TaskFilter{Status: &todo}))
+	assert.ElementsMatch(t, []string{"alice todo", "both todo"}, list(domain.TaskFilter{Assignee: &alice}))
+	assert.ElementsMatch(t, []string{"bob done"}, list(domain.TaskFilter{Watcher: &alice}))
+	assert.ElementsMatch(t, []string{"alice todo", "bob done", "both todo"}, list(domain.TaskFilter{Participant: &alice}))
+	assert.ElementsMatch(t, []string{"both todo"}, list(domain.TaskFilter{Status: &todo, Assignee: &bob}))
+	assert.Empty(t, list(domain.TaskFilter{Participant: &eve}))
+
+	// A user name must match exactly, not as a substring.
+	ali := "ali"
+	assert.Empty(t, list(domain.TaskFilter{Assignee: &ali}))
+}
+
+func testListPagination(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	for i := range 5 {
+		create(t, repo, domain.Task{Title: fmt.Sprintf("task %d", i), Status: domain.StatusTodo})
+		time.Sleep(time.Millisecond)
+	}
+
+	page := func(limit, offset int) []string {
+		tasks, err := repo.List(ctx, domain.TaskFilter{Limit: limit, Offset: offset})
+		require.NoError(t, err)
+		return titles(tasks)
+	}
+
+	assert.Equal(t, []string{"task 4", "task 3"}, page(2, 0))
+	assert.Equal(t, []string{"task 2", "task 1"}, page(2, 2))
+	assert.Equal(t, []string{"task 0"}, page(2, 4))
+	assert.Empty(t, page(2, 5))
+	assert.Equal(t, []string{"task 1", "task 0"}, page(0, 3), "offset without limit")
+}
+
+func testStream(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	for i := range 7 {
+		status := domain.StatusTodo
+		if i%2 == 1 {
+			status = domain.StatusDone
+		}
+		create(t, repo, domain.Task{Title: fmt.Sprintf("task %d", i), Status: status})
+		time.Sleep(time.Millisecond)
+	}
+
+	stream := func(filter domain.TaskFilter) []string {
+		var out []string
+		err := domain.StreamTasks(ctx, repo, filter, func(task *domain.Task) error {
+			out = append(out, task.Title)
+			return nil
+		})
+		require.NoError(t, err)
+		return out
+	}
+
+	todo := domain.StatusTodo
+
+	// Streams list the same tasks as List, whether or not the repository
+	// is a domain.TaskStreamer.
+	assert.Equal(t, []string{"task 6", "task 5", "task 4", "task 3", "task 2", "task 1", "task 0"}, stream(domain.TaskFilter{}))
+	assert.Equal(t, []string{"task 6", "task 4", "task 2", "task 0"}, stream(domain.TaskFilter{Status: &todo}))
+	assert.Equal(t, []string{"task 4", "task 2"}, stream(domain.TaskFilter{Status: &todo, Limit: 2, Offset: 1}))
+
+	errStop := errors.New("stop")
+	n := 0
+	err := domain.StreamTasks(ctx, repo, domain.TaskFilter{}, func(*domain.Task) error {
+		n++
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, n, "an error of fn stops the stream")
+}
+
+func testReturnsCopies(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	created := create(t, repo, domain.Task{Title: "original", Status: domain.StatusTodo, Assignees: []string{"alice"}})
+
+	found, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+
+	// Changing tasks handed out must not change what is stored.
+	created.Title = "changed"
+	found.Assignees[0] = "mallory"
+
+	found, err = repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "original", found.Title)
+	assert.Equal(t, []string{"alice"}, found.Assignees)
+}
+
+func testConcurrentCreates(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	const workers, perWorker = 8, 5
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWorker {
+				task := &domain.Task{Title: fmt.Sprintf("w%d-%d", w, i), Status: domain.StatusTodo}
+				if _, err := repo.Create(ctx, task); err != nil {
+					errs <- err
+					continue
+				}
+				if _, err := repo.GetByID(ctx, task.ID); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	tasks, err := repo.List(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	assert.Len(t, tasks, workers*perWorker)
+}
+
+func testCreateTasks(t *testing.T, repo domain.TaskRepository) {
+	ctx := context.Background()
+
+	created, err := domain.CreateTasks(ctx, repo, []*domain.Task{
+		{Title: "first", Status: domain.StatusTodo},
+		{Title: "second", Status: domain.StatusDone, Assignees: []string{"alice"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+
+	for _, task := range created {
+		found, err := repo.GetByID(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, task.Title, found.Title)
+		assert.Equal(t, task.Status, found.Status)
+		assert.Equal(t, task.Assignees, found.Assignees)
+	}
+}
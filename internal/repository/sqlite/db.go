@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS tasks (
+	id         TEXT PRIMARY KEY,
+	title      TEXT NOT NULL,
+	status     TEXT NOT NULL,
+	assignees  TEXT NOT NULL DEFAULT '[]',
+	watchers   TEXT NOT NULL DEFAULT '[]',
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks(created_at);
+
+CREATE TABLE IF NOT EXISTS task_external_refs (
+	source      TEXT NOT NULL,
+	external_id TEXT NOT NULL,
+	task_id     TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	PRIMARY KEY (source, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_external_refs_task_id ON task_external_refs(task_id);
+`
+
+// Open opens the SQLite database at path, creating the file and the task
+// schema when missing. ":memory:" keeps the database in memory for the
+// lifetime of the returned handle.
+//
+// SQLite allows a single writer, so the pool holds one connection; this
+// also keeps an in-memory database from being opened once per connection.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "busy_timeout(5000)")
+	pragmas.Add("_pragma", "journal_mode(WAL)")
+	pragmas.Add("_pragma", "foreign_keys(1)")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+pragmas.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
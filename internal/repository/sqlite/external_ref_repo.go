@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"graph-task-service/internal/domain"
+)
+
+type externalRefRepository struct {
+	db *sql.DB
+}
+
+// NewExternalRefRepository stores the links of imported items to their
+// tasks. Deleting a task deletes its links.
+func NewExternalRefRepository(db *sql.DB) domain.ExternalRefRepository {
+	return &externalRefRepository{db: db}
+}
+
+func (r *externalRefRepository) Get(
+	ctx context.Context,
+	source, externalID string,
+) (*domain.ExternalRef, error) {
+
+	ref := domain.ExternalRef{Source: source, ExternalID: externalID}
+
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT task_id FROM task_external_refs WHERE source = ? AND external_id = ?`,
+		source,
+		externalID,
+	).Scan(&ref.TaskID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ref, nil
+}
+
+// Lock does nothing: SQLite repositories do not join units of work.
+func (r *externalRefRepository) Lock(
+	ctx context.Context,
+	source string,
+) error {
+
+	return nil
+}
+
+func (r *externalRefRepository) Put(
+	ctx context.Context,
+	ref *domain.ExternalRef,
+) error {
+
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO task_external_refs (source, external_id, task_id) VALUES (?, ?, ?)
+		ON CONFLICT (source, external_id) DO UPDATE SET task_id = excluded.task_id`,
+		ref.Source,
+		ref.ExternalID,
+		ref.TaskID,
+	)
+	return err
+}
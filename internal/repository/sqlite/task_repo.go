@@ -0,0 +1,338 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type taskRepository struct {
+	db *sql.DB
+}
+
+// NewTaskRepository stores tasks in SQLite with the semantics of the
+// Postgres repository: newest first, domain.ErrNotFound for unknown ids and
+// timestamps at microsecond precision. Assignees and watchers are JSON
+// arrays. Task events are not recorded. It also implements
+// domain.TaskCounter and domain.TaskBatchGetter.
+func NewTaskRepository(db *sql.DB) domain.TaskRepository {
+	return &taskRepository{db: db}
+}
+
+const taskColumns = `id, title, status, assignees, watchers, created_at, updated_at`
+
+func (r *taskRepository) Create(
+	ctx context.Context,
+	task *domain.Task,
+) (*domain.Task, error) {
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	assignees, err := encodeUsers(task.Assignees)
+	if err != nil {
+		return nil, err
+	}
+
+	watchers, err := encodeUsers(task.Watchers)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewString()
+
+	_, err = r.db.ExecContext(
+		ctx,
+		`INSERT INTO tasks (`+taskColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		id,
+		task.Title,
+		task.Status,
+		assignees,
+		watchers,
+		now.UnixMicro(),
+		now.UnixMicro(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	task.ID = id
+	task.Assignees = orEmpty(task.Assignees)
+	task.Watchers = orEmpty(task.Watchers)
+	task.CreatedAt = now
+	task.UpdatedAt = now
+
+	return task, nil
+}
+
+func (r *taskRepository) GetByID(
+	ctx context.Context,
+	id string,
+) (*domain.Task, error) {
+
+	task, err := scanTask(r.db.QueryRowContext(
+		ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE id = ?`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (r *taskRepository) GetByIDs(
+	ctx context.Context,
+	ids []string,
+) ([]*domain.Task, error) {
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE id IN (?`+strings.Repeat(`, ?`, len(ids)-1)+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*domain.Task
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (r *taskRepository) List(
+	ctx context.Context,
+	filter domain.TaskFilter,
+) ([]*domain.Task, error) {
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE 1=1`
+	args := []any{}
+
+	if filter.Status != nil {
+		query += ` AND status = ?`
+		args = append(args, *filter.Status)
+	}
+
+	if filter.Assignee != nil {
+		query += ` AND EXISTS (SELECT 1 FROM json_each(assignees) WHERE value = ?)`
+		args = append(args, *filter.Assignee)
+	}
+
+	if filter.Watcher != nil {
+		query += ` AND EXISTS (SELECT 1 FROM json_each(watchers) WHERE value = ?)`
+		args = append(args, *filter.Watcher)
+	}
+
+	if filter.Participant != nil {
+		query += ` AND (EXISTS (SELECT 1 FROM json_each(assignees) WHERE value = ?)
+			OR EXISTS (SELECT 1 FROM json_each(watchers) WHERE value = ?))`
+		args = append(args, *filter.Participant, *filter.Participant)
+	}
+
+	// rowid breaks ties between tasks created in the same microsecond.
+	query += ` ORDER BY created_at DESC, rowid DESC`
+
+	// SQLite needs a LIMIT for OFFSET; -1 means no limit.
+	limit := -1
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	query += ` LIMIT ? OFFSET ?`
+	args = append(args, limit, max(filter.Offset, 0))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*domain.Task
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (r *taskRepository) Update(
+	ctx context.Context,
+	task *domain.Task,
+) error {
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	assignees, err := encodeUsers(task.Assignees)
+	if err != nil {
+		return err
+	}
+
+	watchers, err := encodeUsers(task.Watchers)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(
+		ctx,
+		`UPDATE tasks
+		SET title = ?, status = ?, assignees = ?, watchers = ?, updated_at = ?
+		WHERE id = ?`,
+		task.Title,
+		task.Status,
+		assignees,
+		watchers,
+		now.UnixMicro(),
+		task.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := requireRow(res); err != nil {
+		return err
+	}
+
+	task.UpdatedAt = now
+
+	return nil
+}
+
+func (r *taskRepository) Delete(
+	ctx context.Context,
+	id string,
+) error {
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(res)
+}
+
+func (r *taskRepository) CountByStatus(
+	ctx context.Context,
+) (map[domain.TaskStatus]int, error) {
+
+	rows, err := r.db.QueryContext(ctx, `SELECT status, count(*) FROM tasks GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.TaskStatus]int)
+
+	for rows.Next() {
+		var (
+			status domain.TaskStatus
+			count  int
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// requireRow reports domain.ErrNotFound when a statement touched no row.
+func requireRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row scanner) (*domain.Task, error) {
+	var (
+		task                 domain.Task
+		assignees, watchers  string
+		createdAt, updatedAt int64
+	)
+
+	if err := row.Scan(
+		&task.ID,
+		&task.Title,
+		&task.Status,
+		&assignees,
+		&watchers,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(assignees), &task.Assignees); err != nil {
+		return nil, fmt.Errorf("decode assignees of task %s: %w", task.ID, err)
+	}
+	if err := json.Unmarshal([]byte(watchers), &task.Watchers); err != nil {
+		return nil, fmt.Errorf("decode watchers of task %s: %w", task.ID, err)
+	}
+
+	task.CreatedAt = time.UnixMicro(createdAt).UTC()
+	task.UpdatedAt = time.UnixMicro(updatedAt).UTC()
+
+	return &task, nil
+}
+
+func encodeUsers(users []string) (string, error) {
+	b, err := json.Marshal(orEmpty(users))
+	return string(b), err
+}
+
+// orEmpty keeps nil slices from being stored as JSON null.
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
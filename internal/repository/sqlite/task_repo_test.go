@@ -0,0 +1,83 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/repository/repotest"
+	"graph-task-service/internal/repository/sqlite"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openDB(t *testing.T, path string) *sql.DB {
+	db, err := sqlite.Open(context.Background(), path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func openTestDB(t *testing.T, path string) domain.TaskRepository {
+	return sqlite.NewTaskRepository(openDB(t, path))
+}
+
+func TestTaskRepository_Conformance(t *testing.T) {
+	repotest.TestTaskRepository(t, func(t *testing.T) domain.TaskRepository {
+		return openTestDB(t, ":memory:")
+	})
+}
+
+func TestExternalRefRepository_Conformance(t *testing.T) {
+	repotest.TestExternalRefRepository(t, func(t *testing.T) (domain.ExternalRefRepository, domain.TaskRepository) {
+		db := openDB(t, ":memory:")
+		return sqlite.NewExternalRefRepository(db), sqlite.NewTaskRepository(db)
+	})
+}
+
+func TestExternalRefRepository_DeletedWithTask(t *testing.T) {
+	db := openDB(t, ":memory:")
+	tasks, refs := sqlite.NewTaskRepository(db), sqlite.NewExternalRefRepository(db)
+	ctx := context.Background()
+
+	task, err := tasks.Create(ctx, &domain.Task{Title: "imported", Status: domain.StatusTodo})
+	require.NoError(t, err)
+	require.NoError(t, refs.Put(ctx, &domain.ExternalRef{Source: "jira", ExternalID: "1", TaskID: task.ID}))
+	require.NoError(t, tasks.Delete(ctx, task.ID))
+
+	_, err = refs.Get(ctx, "jira", "1")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestTaskRepository_PersistsAcrossOpens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	ctx := context.Background()
+
+	created, err := openTestDB(t, path).Create(ctx, &domain.Task{
+		Title:    "survive restart",
+		Status:   domain.StatusTodo,
+		Watchers: []string{"alice"},
+	})
+	require.NoError(t, err)
+
+	found, err := openTestDB(t, path).GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, found)
+}
+
+func TestTaskRepository_CountByStatus(t *testing.T) {
+	repo := openTestDB(t, ":memory:")
+	ctx := context.Background()
+
+	for _, status := range []domain.TaskStatus{domain.StatusTodo, domain.StatusDone, domain.StatusDone} {
+		_, err := repo.Create(ctx, &domain.Task{Title: "t", Status: status})
+		require.NoError(t, err)
+	}
+
+	counts, err := repo.(domain.TaskCounter).CountByStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[domain.TaskStatus]int{domain.StatusTodo: 1, domain.StatusDone: 2}, counts)
+}
@@ -1,8 +1,11 @@
 package router
 
 import (
+	"graph-task-service/internal/handler/graphql"
 	"graph-task-service/internal/handler/http"
-	"os"
+	"graph-task-service/internal/middelware"
+	"graph-task-service/internal/observability"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 
@@ -12,31 +15,132 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// Options are the router settings taken from the service configuration.
+type Options struct {
+	MaxBodyBytes int64
+	// MaxImportBytes replaces MaxBodyBytes for uploads of task imports.
+	MaxImportBytes int64
+	// UserHeader carries the caller identity; see middelware.Authenticate.
+	UserHeader string
+	// RequireUser rejects anonymous API requests. Probes, metrics and the
+	// Swagger UI stay open.
+	RequireUser   bool
+	EnableSwagger bool
+}
+
 func New(
 	taskHandler *http.TaskHandler,
+	importHandler *http.ImportHandler,
+	webhookHandler *http.WebhookHandler,
+	streamHandler *http.StreamHandler,
+	graphqlHandler *graphql.Handler,
+	healthHandler *http.HealthHandler,
+	idempotency gin.HandlerFunc,
+	metrics *observability.Metrics,
+	logger *slog.Logger,
+	opts Options,
 ) *gin.Engine {
 
 	r := gin.New()
 
-	r.Use(gin.Recovery())
+	r.Use(middelware.RequestID())
+	r.Use(middelware.Tracing())
+	r.Use(middelware.Logger(logger))
+	r.Use(middelware.Recovery())
+	r.Use(middelware.Metrics(metrics))
+	r.Use(middelware.AuthenticateHeader(opts.UserHeader))
+
+	// Cache-Control policies. Task reads may be stored by the client but are
+	// revalidated with their ETag on every use; they differ per user.
+	// Everything else, including writes and webhook secrets, is never stored.
+	var (
+		revalidate = middelware.CacheControl("private, no-cache", opts.UserHeader)
+		noStore    = middelware.CacheControl("no-store")
+	)
+
+	r.GET("/livez", noStore, healthHandler.Livez)
+	r.GET("/readyz", noStore, healthHandler.Readyz)
+	// /health predates the probes and is kept as an alias of /livez.
+	r.GET("/health", noStore, healthHandler.Livez)
+
+	r.GET("/metrics", noStore, gin.WrapH(metrics.Handler()))
+
+	api := r.Group("")
+	if opts.RequireUser {
+		api.Use(middelware.RequireUser())
+	}
 
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// Imports upload whole files, so they get a body limit of their own.
+	uploads := api.Group("", middelware.BodyLimit(opts.MaxImportBytes))
+	api = api.Group("", middelware.BodyLimit(opts.MaxBodyBytes))
 
-	tasks := r.Group("/tasks")
+	// Without Postgres there are no idempotency keys, webhooks or event
+	// streams; the task API is served on its own.
+	var writeGuards []gin.HandlerFunc
+	if idempotency != nil {
+		writeGuards = append(writeGuards, idempotency)
+	}
+
+	tasks := api.Group("/tasks", writeGuards...)
 	{
-		tasks.POST("", taskHandler.Create)
-		tasks.GET("", taskHandler.List)
+		tasks.POST("", noStore, traced("TaskHandler.Create", taskHandler.Create))
+		tasks.GET("", revalidate, traced("TaskHandler.List", taskHandler.List))
+
+		tasks.GET("/export", noStore, traced("TaskHandler.Export", taskHandler.Export))
+		tasks.GET("/:id", revalidate, traced("TaskHandler.GetByID", taskHandler.GetByID))
+		tasks.PATCH("/:id/status", noStore, traced("TaskHandler.UpdateStatus", taskHandler.UpdateStatus))
+		tasks.DELETE("/:id", noStore, traced("TaskHandler.Delete", taskHandler.Delete))
 
-		tasks.GET("/:id", taskHandler.GetByID)
-		tasks.PATCH("/:id/status", taskHandler.UpdateStatus)
-		tasks.DELETE("/:id", taskHandler.Delete)
+		tasks.POST("/:id/assignees", noStore, traced("TaskHandler.AddAssignee", taskHandler.AddAssignee))
+		tasks.DELETE("/:id/assignees/:user", noStore, traced("TaskHandler.RemoveAssignee", taskHandler.RemoveAssignee))
+		tasks.POST("/:id/watchers", noStore, traced("TaskHandler.AddWatcher", taskHandler.AddWatcher))
+		tasks.DELETE("/:id/watchers/:user", noStore, traced("TaskHandler.RemoveWatcher", taskHandler.RemoveWatcher))
+	}
+
+	imports := uploads.Group("/tasks/import", append(writeGuards, noStore)...)
+	{
+		imports.POST("", traced("ImportHandler.Import", importHandler.Import))
+		imports.GET("/jobs/:id", traced("ImportHandler.GetJob", importHandler.GetJob))
 	}
 
-	if os.Getenv("ENABLE_SWAGGER") == "true" {
+	if webhookHandler != nil {
+		webhooks := api.Group("/webhooks", append(writeGuards, noStore)...)
+		webhooks.POST("", webhookHandler.Create)
+		webhooks.GET("", webhookHandler.List)
+
+		webhooks.GET("/:id", webhookHandler.GetByID)
+		webhooks.PUT("/:id", webhookHandler.Update)
+		webhooks.DELETE("/:id", webhookHandler.Delete)
+		webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+	}
+
+	if streamHandler != nil {
+		events := api.Group("/events", noStore)
+		events.GET("/stream", streamHandler.SSE)
+		events.GET("/ws", streamHandler.WebSocket)
+	}
+
+	// GraphQL goes through the write guards like the task API, so mutations
+	// may carry an Idempotency-Key too.
+	if graphqlHandler != nil {
+		gql := api.Group("/graphql", append(writeGuards, noStore)...)
+		serve := traced("GraphQLHandler.ServeHTTP", gin.WrapH(graphqlHandler))
+		gql.GET("", serve)
+		gql.POST("", serve)
+	}
+
+	me := api.Group("/me")
+	{
+		me.GET("/tasks", revalidate, traced("TaskHandler.ListMine", taskHandler.ListMine))
+	}
+
+	if opts.EnableSwagger {
 		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
 
 	return r
 }
+
+func traced(name string, h gin.HandlerFunc) gin.HandlerFunc {
+	return observability.TraceHandler(name, h)
+}
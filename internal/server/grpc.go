@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// ServeGRPC serves srv on ln until ctx is cancelled, then shuts it down
+// like Serve: it keeps serving for ShutdownDelay, then gives running calls
+// ShutdownTimeout to finish before their connections are closed.
+//
+// onShutdown runs before the server stops, to end calls that never finish on
+// their own, such as streams of events.
+func ServeGRPC(
+	ctx context.Context,
+	srv *grpc.Server,
+	ln net.Listener,
+	cfg Config,
+	onShutdown ...func(),
+) error {
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	if cfg.ShutdownDelay > 0 {
+		time.Sleep(cfg.ShutdownDelay)
+	}
+
+	for _, f := range onShutdown {
+		f()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(cfg.ShutdownTimeout):
+		slog.Warn("grpc calls still running after the shutdown timeout, closing connections")
+		srv.Stop()
+		<-stopped
+	}
+
+	// Serve returns nil once the server has been stopped.
+	return <-errc
+}
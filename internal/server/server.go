@@ -0,0 +1,122 @@
+// Package server runs the HTTP and gRPC servers with timeouts and a
+// graceful shutdown.
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Addr string
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	// ShutdownDelay keeps serving after the shutdown signal so load
+	// balancers notice the failing readiness probe before connections close.
+	ShutdownDelay time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may take to finish.
+	ShutdownTimeout time.Duration
+}
+
+type Server struct {
+	http *http.Server
+	cfg  Config
+
+	mu             sync.Mutex
+	beforeShutdown []func()
+}
+
+func New(handler http.Handler, cfg Config) *Server {
+	return &Server{
+		http: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+			ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		},
+		cfg: cfg,
+	}
+}
+
+// BeforeShutdown registers f to run as soon as shutdown starts, before the
+// delay and before connections are drained.
+func (s *Server) BeforeShutdown(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.beforeShutdown = append(s.beforeShutdown, f)
+}
+
+// RegisterOnShutdown registers f to run when the server stops accepting
+// connections. Use it to end long-lived responses such as event streams,
+// which would otherwise hold the shutdown until its timeout.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.http.RegisterOnShutdown(f)
+}
+
+// Run listens on the configured address and serves until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve serves on ln until ctx is cancelled, then shuts down gracefully.
+// It returns once in-flight requests have finished or ShutdownTimeout has
+// passed, whichever comes first.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.http.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down", "delay", s.cfg.ShutdownDelay, "timeout", s.cfg.ShutdownTimeout)
+
+	s.mu.Lock()
+	hooks := s.beforeShutdown
+	s.mu.Unlock()
+
+	for _, f := range hooks {
+		f()
+	}
+
+	if s.cfg.ShutdownDelay > 0 {
+		time.Sleep(s.cfg.ShutdownDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		// Connections still open past the deadline are cut.
+		s.http.Close()
+		return err
+	}
+
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/importer"
+	"graph-task-service/internal/logging"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrImportJobNotFound = errors.New("import job not found")
+	ErrImportQueueFull   = errors.New("too many imports are waiting, try again later")
+)
+
+const (
+	// importBatchSize is how many tasks are stored per unit of work.
+	importBatchSize = 500
+	// maxImportErrors is how many rejected rows a result lists.
+	maxImportErrors = 100
+	// importHeartbeat is how often an instance touches the jobs it holds;
+	// jobs not touched for importStaleAfter belong to a stopped instance.
+	importHeartbeat  = 30 * time.Second
+	importStaleAfter = 3 * importHeartbeat
+)
+
+type ImportService interface {
+	// ImportTasks validates every row with the rules of CreateTask. Unless
+	// dryRun is set or a row is invalid, it then creates the tasks in units
+	// of work of importBatchSize. When one fails, the tasks of the earlier
+	// ones stay and the result returned with the error counts them.
+	ImportTasks(ctx context.Context, rows []importer.Row, dryRun bool) (*domain.ImportResult, error)
+	// StartImport runs ImportTasks in the background for user and returns
+	// its job. It fails with ErrImportQueueFull when too many jobs wait.
+	StartImport(ctx context.Context, user string, rows []importer.Row, dryRun bool) (*domain.ImportJob, error)
+	// GetImportJob returns the job id started by user.
+	GetImportJob(ctx context.Context, user string, id string) (*domain.ImportJob, error)
+	// FailAbandoned fails the jobs left pending or running by instances that
+	// stopped, and returns how many there were.
+	FailAbandoned(ctx context.Context) (int64, error)
+	// Close waits for the background imports. Those still running when ctx
+	// ends are cancelled and fail.
+	Close(ctx context.Context) error
+}
+
+type importService struct {
+	tasks domain.TaskRepository
+	jobs  domain.ImportJobRepository
+	tx    domain.TxManager
+
+	// slots holds a token for every import running in the background,
+	// queue one for every import started and not finished.
+	slots  chan struct{}
+	queue  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewImportService returns the import service. At most workers imports run
+// in the background at once and queue more wait as pending; further ones are
+// refused. Without tx the tasks of a batch are not created atomically.
+func NewImportService(
+	tasks domain.TaskRepository,
+	jobs domain.ImportJobRepository,
+	tx domain.TxManager,
+	workers int,
+	queue int,
+) ImportService {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	workers = max(workers, 1)
+
+	return &importService{
+		tasks:  tasks,
+		jobs:   jobs,
+		tx:     tx,
+		slots:  make(chan struct{}, workers),
+		queue:  make(chan struct{}, workers+max(queue, 0)),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (s *importService) ImportTasks(
+	ctx context.Context,
+	rows []importer.Row,
+	dryRun bool,
+) (*domain.ImportResult, error) {
+
+	result := &domain.ImportResult{DryRun: dryRun, Rows: len(rows)}
+	tasks := make([]*domain.Task, 0, len(rows))
+
+	for _, row := range rows {
+		task, err := rowTask(row)
+		if err != nil {
+			result.Invalid++
+			if len(result.Errors) < maxImportErrors {
+				result.Errors = append(result.Errors, domain.ImportRowError{Line: row.Line, Message: err.Error()})
+			}
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	if dryRun || result.Invalid > 0 {
+		return result, nil
+	}
+
+	// Committing batch by batch keeps a large import from holding one
+	// transaction open for all of its tasks.
+	for batch := range slices.Chunk(tasks, importBatchSize) {
+		err := withinTx(ctx, s.tx, func(ctx context.Context) error {
+			_, err := domain.CreateTasks(ctx, s.tasks, batch)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Created += len(batch)
+	}
+
+	logging.FromContext(ctx).Info("tasks imported", "count", result.Created)
+
+	return result, nil
+}
+
+// rowTask validates row like the arguments of CreateTask.
+func rowTask(row importer.Row) (*domain.Task, error) {
+	if row.Err != nil {
+		return nil, row.Err
+	}
+
+	var status *domain.TaskStatus
+	if row.Status != "" {
+		s := domain.TaskStatus(row.Status)
+		status = &s
+	}
+
+	return newTask(row.Title, row.Assignees, row.Watchers, status)
+}
+
+func (s *importService) StartImport(
+	ctx context.Context,
+	user string,
+	rows []importer.Row,
+	dryRun bool,
+) (*domain.ImportJob, error) {
+
+	// Every waiting job holds its parsed file in memory.
+	select {
+	case s.queue <- struct{}{}:
+	default:
+		return nil, ErrImportQueueFull
+	}
+
+	job, err := s.jobs.Create(ctx, &domain.ImportJob{
+		CreatedBy: user,
+		Status:    domain.ImportPending,
+		Result:    domain.ImportResult{DryRun: dryRun, Rows: len(rows)},
+	})
+	if err != nil {
+		<-s.queue
+		return nil, err
+	}
+
+	// The job outlives the request but keeps its logger and trace; it is
+	// cancelled by Close only.
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(s.ctx, cancel)
+
+	running := *job
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.queue }()
+		defer cancel()
+		defer stop()
+
+		defer s.keepAlive(jobCtx, job.ID)()
+
+		s.run(jobCtx, &running, rows, dryRun)
+	}()
+
+	logging.FromContext(ctx).Info("import started", "job_id", job.ID, "rows", len(rows), "dry_run", dryRun)
+
+	return job, nil
+}
+
+func (s *importService) run(
+	ctx context.Context,
+	job *domain.ImportJob,
+	rows []importer.Row,
+	dryRun bool,
+) {
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		s.finish(ctx, job, nil, ctx.Err())
+		return
+	}
+
+	job.Status = domain.ImportRunning
+	s.save(ctx, job)
+
+	result, err := s.ImportTasks(ctx, rows, dryRun)
+	s.finish(ctx, job, result, err)
+}
+
+// keepAlive touches the job id every importHeartbeat until the returned
+// function is called, so other instances do not take it for abandoned.
+func (s *importService) keepAlive(ctx context.Context, id string) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(importHeartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.jobs.Touch(ctx, id); err != nil && ctx.Err() == nil {
+					logging.FromContext(ctx).ErrorContext(ctx, "touch import job", "job_id", id, "error", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// finish records the outcome of job.
+func (s *importService) finish(
+	ctx context.Context,
+	job *domain.ImportJob,
+	result *domain.ImportResult,
+	err error,
+) {
+
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+
+	switch {
+	case err != nil:
+		msg := err.Error()
+		job.Status = domain.ImportFailed
+		job.Error = &msg
+		if result != nil {
+			job.Result = *result
+		}
+	case result.Invalid > 0 && !result.DryRun:
+		job.Status = domain.ImportFailed
+		job.Result = *result
+	default:
+		job.Status = domain.ImportSucceeded
+		job.Result = *result
+	}
+
+	logging.FromContext(ctx).Info("import finished", "job_id", job.ID, "status", job.Status, "created", job.Result.Created)
+
+	s.save(ctx, job)
+}
+
+func (s *importService) save(ctx context.Context, job *domain.ImportJob) {
+	// The job is recorded even when it was cancelled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := s.jobs.Update(ctx, job); err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "save import job", "job_id", job.ID, "status", job.Status, "error", err)
+	}
+}
+
+func (s *importService) GetImportJob(
+	ctx context.Context,
+	user string,
+	id string,
+) (*domain.ImportJob, error) {
+
+	job, err := s.jobs.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, ErrImportJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Other users are not told the job exists.
+	if job.CreatedBy != user {
+		return nil, ErrImportJobNotFound
+	}
+
+	return job, nil
+}
+
+func (s *importService) FailAbandoned(ctx context.Context) (int64, error) {
+	return s.jobs.FailStale(ctx, importStaleAfter, "the instance running the import stopped")
+}
+
+func (s *importService) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
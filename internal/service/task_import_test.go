@@ -0,0 +1,275 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/importer"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var importRows = []importer.Row{
+	{Line: 2, Title: "first"},
+	{Line: 3, Title: "second", Status: "done", Assignees: []string{"alice", "alice"}},
+}
+
+var invalidRows = []importer.Row{
+	{Line: 2, Title: "fine"},
+	{Line: 3, Title: ""},
+	{Line: 4, Title: "x", Status: "blocked"},
+	{Line: 5, Title: "x", Watchers: []string{""}},
+	{Line: 6, Err: errors.New("bare quote in field")},
+}
+
+func listAll(t *testing.T, repo domain.TaskRepository) []*domain.Task {
+	t.Helper()
+
+	tasks, err := repo.List(context.Background(), domain.TaskFilter{Limit: 100})
+	require.NoError(t, err)
+	return tasks
+}
+
+func TestImportTasks_CreatesTheTasks(t *testing.T) {
+	repo := memory.NewTaskRepository()
+	tx := &fakeTx{}
+	svc := service.NewImportService(repo, memory.NewImportJobRepository(), tx, 1, 1)
+
+	result, err := svc.ImportTasks(context.Background(), importRows, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, &domain.ImportResult{Rows: 2, Created: 2}, result)
+	assert.Equal(t, 1, tx.committed)
+
+	tasks := listAll(t, repo)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, domain.StatusDone, tasks[0].Status)
+	assert.Equal(t, []string{"alice"}, tasks[0].Assignees, "users are normalised like in CreateTask")
+	assert.Equal(t, domain.StatusTodo, tasks[1].Status)
+}
+
+func titledRows(n int) []importer.Row {
+	rows := make([]importer.Row, n)
+	for i := range rows {
+		rows[i] = importer.Row{Line: i + 2, Title: "task"}
+	}
+	return rows
+}
+
+func TestImportTasks_CommitsInBatches(t *testing.T) {
+	tx := &fakeTx{}
+	svc := service.NewImportService(memory.NewTaskRepository(), memory.NewImportJobRepository(), tx, 1, 1)
+
+	result, err := svc.ImportTasks(context.Background(), titledRows(1001), false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1001, result.Created)
+	assert.Equal(t, 3, tx.committed)
+}
+
+// failingRepo fails every Create after the first ok ones.
+type failingRepo struct {
+	domain.TaskRepository
+	ok int
+}
+
+func (r *failingRepo) Create(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	if r.ok == 0 {
+		return nil, errors.New("database down")
+	}
+	r.ok--
+	return r.TaskRepository.Create(ctx, task)
+}
+
+func TestImportTasks_KeepsTheBatchesBeforeAFailure(t *testing.T) {
+	repo := &failingRepo{TaskRepository: memory.NewTaskRepository(), ok: 700}
+	svc := service.NewImportService(repo, memory.NewImportJobRepository(), nil, 1, 1)
+
+	result, err := svc.ImportTasks(context.Background(), titledRows(1001), false)
+	require.EqualError(t, err, "database down")
+
+	require.NotNil(t, result)
+	assert.Equal(t, 500, result.Created)
+}
+
+func TestImportTasks_InvalidRowsCreateNothing(t *testing.T) {
+	for _, dryRun := range []bool{true, false} {
+		repo := memory.NewTaskRepository()
+		svc := service.NewImportService(repo, memory.NewImportJobRepository(), nil, 1, 1)
+
+		result, err := svc.ImportTasks(context.Background(), invalidRows, dryRun)
+		require.NoError(t, err)
+
+		assert.Equal(t, &domain.ImportResult{
+			DryRun:  dryRun,
+			Rows:    5,
+			Invalid: 4,
+			Errors: []domain.ImportRowError{
+				{Line: 3, Message: service.ErrEmptyTitle.Error()},
+				{Line: 4, Message: service.ErrInvalidStatus.Error()},
+				{Line: 5, Message: service.ErrEmptyUser.Error()},
+				{Line: 6, Message: "bare quote in field"},
+			},
+		}, result)
+		assert.Empty(t, listAll(t, repo))
+	}
+}
+
+func TestImportTasks_DryRunCreatesNothing(t *testing.T) {
+	repo := memory.NewTaskRepository()
+	svc := service.NewImportService(repo, memory.NewImportJobRepository(), nil, 1, 1)
+
+	result, err := svc.ImportTasks(context.Background(), importRows, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, &domain.ImportResult{DryRun: true, Rows: 2}, result)
+	assert.Empty(t, listAll(t, repo))
+}
+
+func TestImportTasks_ListsTheFirstErrors(t *testing.T) {
+	rows := make([]importer.Row, 250)
+	for i := range rows {
+		rows[i].Line = i + 2
+	}
+
+	svc := service.NewImportService(memory.NewTaskRepository(), memory.NewImportJobRepository(), nil, 1, 1)
+
+	result, err := svc.ImportTasks(context.Background(), rows, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, 250, result.Invalid)
+	assert.Len(t, result.Errors, 100)
+	assert.Equal(t, 101, result.Errors[99].Line)
+}
+
+func TestStartImport(t *testing.T) {
+	repo := memory.NewTaskRepository()
+	svc := service.NewImportService(repo, memory.NewImportJobRepository(), nil, 1, 1)
+	ctx := context.Background()
+
+	valid, err := svc.StartImport(ctx, "alice", importRows, false)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImportPending, valid.Status)
+
+	invalid, err := svc.StartImport(ctx, "alice", invalidRows, false)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Close(ctx))
+
+	job, err := svc.GetImportJob(ctx, "alice", valid.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImportSucceeded, job.Status)
+	assert.Equal(t, 2, job.Result.Created)
+	assert.NotNil(t, job.FinishedAt)
+
+	job, err = svc.GetImportJob(ctx, "alice", invalid.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImportFailed, job.Status)
+	assert.Equal(t, 4, job.Result.Invalid)
+	assert.Len(t, job.Result.Errors, 4)
+	assert.Nil(t, job.Error)
+
+	assert.Len(t, listAll(t, repo), 2)
+}
+
+// blockingRepo holds every Create until its context ends.
+type blockingRepo struct {
+	domain.TaskRepository
+	started chan struct{}
+}
+
+func (r *blockingRepo) Create(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	r.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestImportService_CloseCancelsRunningImports(t *testing.T) {
+	repo := &blockingRepo{TaskRepository: memory.NewTaskRepository(), started: make(chan struct{}, 1)}
+	svc := service.NewImportService(repo, memory.NewImportJobRepository(), nil, 1, 1)
+	ctx := context.Background()
+
+	running, err := svc.StartImport(ctx, "alice", importRows, false)
+	require.NoError(t, err)
+	<-repo.started
+
+	// The only worker is busy, so this one stays pending.
+	pending, err := svc.StartImport(ctx, "alice", importRows, false)
+	require.NoError(t, err)
+
+	closeCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, svc.Close(closeCtx), context.Canceled)
+
+	for _, id := range []string{running.ID, pending.ID} {
+		job, err := svc.GetImportJob(ctx, "alice", id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ImportFailed, job.Status)
+		require.NotNil(t, job.Error)
+		assert.Contains(t, *job.Error, "context canceled")
+	}
+}
+
+func TestStartImport_RefusesJobsBeyondTheQueue(t *testing.T) {
+	repo := &blockingRepo{TaskRepository: memory.NewTaskRepository(), started: make(chan struct{}, 1)}
+	svc := service.NewImportService(repo, memory.NewImportJobRepository(), nil, 1, 1)
+	ctx := context.Background()
+
+	_, err := svc.StartImport(ctx, "alice", importRows, false)
+	require.NoError(t, err)
+	<-repo.started
+
+	_, err = svc.StartImport(ctx, "alice", importRows, false)
+	require.NoError(t, err)
+
+	_, err = svc.StartImport(ctx, "alice", importRows, false)
+	assert.ErrorIs(t, err, service.ErrImportQueueFull)
+
+	closeCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, svc.Close(closeCtx), context.Canceled)
+}
+
+func TestGetImportJob_OnlyForItsUser(t *testing.T) {
+	svc := service.NewImportService(memory.NewTaskRepository(), memory.NewImportJobRepository(), nil, 1, 1)
+	ctx := context.Background()
+
+	job, err := svc.StartImport(ctx, "alice", importRows, true)
+	require.NoError(t, err)
+	require.NoError(t, svc.Close(ctx))
+
+	_, err = svc.GetImportJob(ctx, "bob", job.ID)
+	assert.ErrorIs(t, err, service.ErrImportJobNotFound)
+
+	_, err = svc.GetImportJob(ctx, "", job.ID)
+	assert.ErrorIs(t, err, service.ErrImportJobNotFound)
+}
+
+func TestImportService_FailAbandoned(t *testing.T) {
+	jobs := memory.NewImportJobRepository()
+	svc := service.NewImportService(memory.NewTaskRepository(), jobs, nil, 1, 1)
+	ctx := context.Background()
+
+	stale, err := jobs.Create(ctx, &domain.ImportJob{Status: domain.ImportRunning, CreatedBy: "alice"})
+	require.NoError(t, err)
+
+	// Jobs are abandoned only once their instance stopped touching them.
+	n, err := svc.FailAbandoned(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	job, err := svc.GetImportJob(ctx, "alice", stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImportRunning, job.Status)
+}
+
+func TestGetImportJob_NotFound(t *testing.T) {
+	svc := service.NewImportService(memory.NewTaskRepository(), memory.NewImportJobRepository(), nil, 1, 1)
+
+	_, err := svc.GetImportJob(context.Background(), "alice", "missing")
+	assert.ErrorIs(t, err, service.ErrImportJobNotFound)
+}
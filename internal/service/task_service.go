@@ -4,56 +4,81 @@ import (
 	"context"
 	"errors"
 	"graph-task-service/internal/domain"
+	"graph-task-service/internal/logging"
+	"slices"
 )
 
 var (
 	ErrTaskNotFound  = errors.New("task not found")
 	ErrInvalidStatus = errors.New("invalid task status")
 	ErrEmptyTitle    = errors.New("title cannot be empty")
+	ErrEmptyUser     = errors.New("user cannot be empty")
 )
 
 type TaskService interface {
-	CreateTask(ctx context.Context, title string, assignee *string, status *domain.TaskStatus) (*domain.Task, error)
+	CreateTask(ctx context.Context, title string, assignees []string, watchers []string, status *domain.TaskStatus) (*domain.Task, error)
 	GetTask(ctx context.Context, id string) (*domain.Task, error)
+	// GetTasks returns the tasks with ids that exist, each once and in no
+	// particular order; see domain.GetTasks.
+	GetTasks(ctx context.Context, ids []string) ([]*domain.Task, error)
 	ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error)
+	// StreamTasks calls fn for every task matching filter without loading
+	// all of them; see domain.StreamTasks.
+	StreamTasks(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error
 	UpdateStatus(ctx context.Context, id string, status domain.TaskStatus) (*domain.Task, error)
 	DeleteTask(ctx context.Context, id string) error
+	AddAssignee(ctx context.Context, id string, user string) (*domain.Task, error)
+	RemoveAssignee(ctx context.Context, id string, user string) (*domain.Task, error)
+	AddWatcher(ctx context.Context, id string, user string) (*domain.Task, error)
+	RemoveWatcher(ctx context.Context, id string, user string) (*domain.Task, error)
 }
 
 type taskService struct {
 	repo domain.TaskRepository
+	tx   domain.TxManager
 }
 
-func NewTaskService(repo domain.TaskRepository) TaskService {
-	return &taskService{repo: repo}
+// NewTaskService returns the task service. Operations that read a task and
+// write it back run as one unit of work of tx; with a nil tx they run
+// without a surrounding transaction.
+func NewTaskService(repo domain.TaskRepository, tx domain.TxManager) TaskService {
+	return &taskService{repo: repo, tx: tx}
+}
+
+// withinTx runs fn as one unit of work.
+func (s *taskService) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTx(ctx, s.tx, fn)
+}
+
+// withinTx runs fn as one unit of work of tx, or on its own when tx is nil.
+func withinTx(ctx context.Context, tx domain.TxManager, fn func(ctx context.Context) error) error {
+	if tx == nil {
+		return fn(ctx)
+	}
+	return tx.WithinTx(ctx, fn)
 }
 
 func (s *taskService) CreateTask(
 	ctx context.Context,
 	title string,
-	assignee *string,
+	assignees []string,
+	watchers []string,
 	status *domain.TaskStatus,
 ) (*domain.Task, error) {
 
-	if title == "" {
-		return nil, ErrEmptyTitle
+	task, err := newTask(title, assignees, watchers, status)
+	if err != nil {
+		return nil, err
 	}
 
-	finalStatus := domain.StatusTodo
-	if status != nil {
-		if !domain.IsValidStatus(*status) {
-			return nil, ErrInvalidStatus
-		}
-		finalStatus = *status
+	created, err := s.repo.Create(ctx, task)
+	if err != nil {
+		return nil, err
 	}
 
-	task := &domain.Task{
-		Title:    title,
-		Status:   finalStatus,
-		Assignee: assignee,
-	}
+	logging.FromContext(ctx).Info("task created", "task_id", created.ID, "status", created.Status)
 
-	return s.repo.Create(ctx, task)
+	return created, nil
 }
 
 func (s *taskService) UpdateStatus(
@@ -66,15 +91,102 @@ func (s *taskService) UpdateStatus(
 		return nil, ErrInvalidStatus
 	}
 
-	task, err := s.repo.GetByID(ctx, id)
+	var (
+		task     *domain.Task
+		previous domain.TaskStatus
+	)
+
+	err := s.withinTx(ctx, func(ctx context.Context) error {
+		var err error
+		task, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		previous = task.Status
+		task.Status = status
+
+		return s.repo.Update(ctx, task)
+	})
 	if err != nil {
-		return nil, err
+		return nil, taskNotFound(err)
 	}
 
-	task.Status = status
+	logging.FromContext(ctx).Info("task status updated", "task_id", id, "from", previous, "to", status)
 
-	if err := s.repo.Update(ctx, task); err != nil {
-		return nil, err
+	return task, nil
+}
+
+func (s *taskService) AddAssignee(
+	ctx context.Context,
+	id string,
+	user string,
+) (*domain.Task, error) {
+
+	return s.updateUsers(ctx, id, user, func(t *domain.Task) {
+		t.Assignees = appendUnique(t.Assignees, user)
+	})
+}
+
+func (s *taskService) RemoveAssignee(
+	ctx context.Context,
+	id string,
+	user string,
+) (*domain.Task, error) {
+
+	return s.updateUsers(ctx, id, user, func(t *domain.Task) {
+		t.Assignees = remove(t.Assignees, user)
+	})
+}
+
+func (s *taskService) AddWatcher(
+	ctx context.Context,
+	id string,
+	user string,
+) (*domain.Task, error) {
+
+	return s.updateUsers(ctx, id, user, func(t *domain.Task) {
+		t.Watchers = appendUnique(t.Watchers, user)
+	})
+}
+
+func (s *taskService) RemoveWatcher(
+	ctx context.Context,
+	id string,
+	user string,
+) (*domain.Task, error) {
+
+	return s.updateUsers(ctx, id, user, func(t *domain.Task) {
+		t.Watchers = remove(t.Watchers, user)
+	})
+}
+
+func (s *taskService) updateUsers(
+	ctx context.Context,
+	id string,
+	user string,
+	apply func(t *domain.Task),
+) (*domain.Task, error) {
+
+	if user == "" {
+		return nil, ErrEmptyUser
+	}
+
+	var task *domain.Task
+
+	err := s.withinTx(ctx, func(ctx context.Context) error {
+		var err error
+		task, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		apply(task)
+
+		return s.repo.Update(ctx, task)
+	})
+	if err != nil {
+		return nil, taskNotFound(err)
 	}
 
 	return task, nil
@@ -85,7 +197,13 @@ func (s *taskService) DeleteTask(
 	id string,
 ) error {
 
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return taskNotFound(err)
+	}
+
+	logging.FromContext(ctx).Info("task deleted", "task_id", id)
+
+	return nil
 }
 
 func (s *taskService) GetTask(
@@ -93,7 +211,20 @@ func (s *taskService) GetTask(
 	id string,
 ) (*domain.Task, error) {
 
-	return s.repo.GetByID(ctx, id)
+	task, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, taskNotFound(err)
+	}
+
+	return task, nil
+}
+
+func (s *taskService) GetTasks(
+	ctx context.Context,
+	ids []string,
+) ([]*domain.Task, error) {
+
+	return domain.GetTasks(ctx, s.repo, ids)
 }
 
 func (s *taskService) ListTasks(
@@ -110,3 +241,83 @@ func (s *taskService) ListTasks(
 
 	return s.repo.List(ctx, filter)
 }
+
+func (s *taskService) StreamTasks(
+	ctx context.Context,
+	filter domain.TaskFilter,
+	fn func(*domain.Task) error,
+) error {
+
+	return domain.StreamTasks(ctx, s.repo, filter, fn)
+}
+
+// newTask validates a task to be created. A nil status defaults to todo.
+func newTask(
+	title string,
+	assignees []string,
+	watchers []string,
+	status *domain.TaskStatus,
+) (*domain.Task, error) {
+
+	if title == "" {
+		return nil, ErrEmptyTitle
+	}
+
+	finalStatus := domain.StatusTodo
+	if status != nil {
+		if !domain.IsValidStatus(*status) {
+			return nil, ErrInvalidStatus
+		}
+		finalStatus = *status
+	}
+
+	users, err := normalizeUsers(assignees)
+	if err != nil {
+		return nil, err
+	}
+
+	watching, err := normalizeUsers(watchers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Task{
+		Title:     title,
+		Status:    finalStatus,
+		Assignees: users,
+		Watchers:  watching,
+	}, nil
+}
+
+// taskNotFound translates the repository's not-found error for handlers.
+func taskNotFound(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrTaskNotFound
+	}
+	return err
+}
+
+// normalizeUsers rejects empty names and drops duplicates while keeping order.
+func normalizeUsers(users []string) ([]string, error) {
+	var out []string
+	for _, u := range users {
+		if u == "" {
+			return nil, ErrEmptyUser
+		}
+		out = appendUnique(out, u)
+	}
+	return out, nil
+}
+
+func appendUnique(users []string, user string) []string {
+	if slices.Contains(users, user) {
+		return users
+	}
+	return append(users, user)
+}
+
+func remove(users []string, user string) []string {
+	return slices.DeleteFunc(users, func(u string) bool {
+		return u == user
+	})
+}
@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"errors"
 	"graph-task-service/internal/domain"
 	"graph-task-service/internal/service"
 	"testing"
@@ -43,9 +44,40 @@ func (m *mockTaskRepo) Delete(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+type txKey struct{}
+
+// fakeTx records the outcome of every unit of work and marks the context
+// it hands to fn, so tests can tell which repository calls ran inside one.
+type fakeTx struct {
+	committed  int
+	rolledBack int
+}
+
+func (f *fakeTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return f.WithinTxLevel(ctx, domain.IsolationDefault, fn)
+}
+
+func (f *fakeTx) WithinTxLevel(
+	ctx context.Context,
+	_ domain.IsolationLevel,
+	fn func(ctx context.Context) error,
+) error {
+
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		f.rolledBack++
+		return err
+	}
+	f.committed++
+	return nil
+}
+
+func inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+}
+
 func TestCreateTask_Success(t *testing.T) {
 	repo := new(mockTaskRepo)
-	svc := service.NewTaskService(repo)
+	svc := service.NewTaskService(repo, nil)
 
 	repo.On(
 		"Create",
@@ -56,7 +88,7 @@ func TestCreateTask_Success(t *testing.T) {
 		}),
 	).Return(&domain.Task{Title: "test"}, nil)
 
-	task, err := svc.CreateTask(context.Background(), "test", nil, nil)
+	task, err := svc.CreateTask(context.Background(), "test", nil, nil, nil)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "test", task.Title)
@@ -65,7 +97,7 @@ func TestCreateTask_Success(t *testing.T) {
 
 func TestGetTask_Success(t *testing.T) {
 	repo := new(mockTaskRepo)
-	svc := service.NewTaskService(repo)
+	svc := service.NewTaskService(repo, nil)
 
 	expected := &domain.Task{ID: "1", Title: "test"}
 
@@ -84,7 +116,7 @@ func TestGetTask_Success(t *testing.T) {
 
 func TestGetTask_NotFound(t *testing.T) {
 	repo := new(mockTaskRepo)
-	svc := service.NewTaskService(repo)
+	svc := service.NewTaskService(repo, nil)
 
 	repo.On(
 		"GetByID",
@@ -101,7 +133,7 @@ func TestGetTask_NotFound(t *testing.T) {
 
 func TestListTasks_Success(t *testing.T) {
 	repo := new(mockTaskRepo)
-	svc := service.NewTaskService(repo)
+	svc := service.NewTaskService(repo, nil)
 
 	filter := domain.TaskFilter{}
 
@@ -126,7 +158,7 @@ func TestListTasks_Success(t *testing.T) {
 
 func TestUpdateStatus_Success(t *testing.T) {
 	repo := new(mockTaskRepo)
-	svc := service.NewTaskService(repo)
+	svc := service.NewTaskService(repo, nil)
 
 	task := &domain.Task{
 		ID:     "1",
@@ -161,7 +193,7 @@ func TestUpdateStatus_Success(t *testing.T) {
 
 func TestUpdateStatus_InvalidStatus(t *testing.T) {
 	repo := new(mockTaskRepo)
-	svc := service.NewTaskService(repo)
+	svc := service.NewTaskService(repo, nil)
 
 	task, err := svc.UpdateStatus(
 		context.Background(),
@@ -177,7 +209,7 @@ func TestUpdateStatus_InvalidStatus(t *testing.T) {
 
 func TestDeleteTask_Success(t *testing.T) {
 	repo := new(mockTaskRepo)
-	svc := service.NewTaskService(repo)
+	svc := service.NewTaskService(repo, nil)
 
 	repo.On(
 		"Delete",
@@ -190,3 +222,106 @@ func TestDeleteTask_Success(t *testing.T) {
 	assert.NoError(t, err)
 	repo.AssertExpectations(t)
 }
+
+func TestCreateTask_DeduplicatesAssignees(t *testing.T) {
+	repo := new(mockTaskRepo)
+	svc := service.NewTaskService(repo, nil)
+
+	repo.On(
+		"Create",
+		mock.Anything,
+		mock.MatchedBy(func(t *domain.Task) bool {
+			return assert.ObjectsAreEqual([]string{"a", "b"}, t.Assignees)
+		}),
+	).Return(&domain.Task{Title: "test", Assignees: []string{"a", "b"}}, nil)
+
+	_, err := svc.CreateTask(context.Background(), "test", []string{"a", "b", "a"}, nil, nil)
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateTask_EmptyWatcher(t *testing.T) {
+	repo := new(mockTaskRepo)
+	svc := service.NewTaskService(repo, nil)
+
+	task, err := svc.CreateTask(context.Background(), "test", nil, []string{""}, nil)
+
+	assert.Nil(t, task)
+	assert.ErrorIs(t, err, service.ErrEmptyUser)
+	repo.AssertExpectations(t)
+}
+
+func TestAddAssignee_IsIdempotent(t *testing.T) {
+	repo := new(mockTaskRepo)
+	svc := service.NewTaskService(repo, nil)
+
+	task := &domain.Task{ID: "1", Assignees: []string{"abo"}}
+
+	repo.On("GetByID", mock.Anything, "1").Return(task, nil)
+	repo.On(
+		"Update",
+		mock.Anything,
+		mock.MatchedBy(func(t *domain.Task) bool {
+			return len(t.Assignees) == 1
+		}),
+	).Return(nil)
+
+	updated, err := svc.AddAssignee(context.Background(), "1", "abo")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abo"}, updated.Assignees)
+	repo.AssertExpectations(t)
+}
+
+func TestRemoveWatcher_Success(t *testing.T) {
+	repo := new(mockTaskRepo)
+	svc := service.NewTaskService(repo, nil)
+
+	task := &domain.Task{ID: "1", Watchers: []string{"a", "b"}}
+
+	repo.On("GetByID", mock.Anything, "1").Return(task, nil)
+	repo.On("Update", mock.Anything, task).Return(nil)
+
+	updated, err := svc.RemoveWatcher(context.Background(), "1", "a")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, updated.Watchers)
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateStatus_RunsInOneUnitOfWork(t *testing.T) {
+	repo := new(mockTaskRepo)
+	tx := &fakeTx{}
+	svc := service.NewTaskService(repo, tx)
+
+	task := &domain.Task{ID: "1", Status: domain.StatusTodo}
+
+	repo.On("GetByID", mock.MatchedBy(inTx), "1").Return(task, nil)
+	repo.On("Update", mock.MatchedBy(inTx), task).Return(nil)
+
+	_, err := svc.UpdateStatus(context.Background(), "1", domain.StatusDone)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tx.committed)
+	repo.AssertExpectations(t)
+}
+
+func TestAddWatcher_RollsBackWhenUpdateFails(t *testing.T) {
+	repo := new(mockTaskRepo)
+	tx := &fakeTx{}
+	svc := service.NewTaskService(repo, tx)
+
+	failure := errors.New("update failed")
+
+	repo.On("GetByID", mock.MatchedBy(inTx), "1").Return(&domain.Task{ID: "1"}, nil)
+	repo.On("Update", mock.MatchedBy(inTx), mock.Anything).Return(failure)
+
+	task, err := svc.AddWatcher(context.Background(), "1", "bob")
+
+	assert.Nil(t, task)
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, 1, tx.rolledBack)
+	assert.Zero(t, tx.committed)
+	repo.AssertExpectations(t)
+}
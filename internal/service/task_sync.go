@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/importer"
+	"graph-task-service/internal/logging"
+	"slices"
+)
+
+type SyncService interface {
+	// SyncTasks imports the items of another tracker, named by source. An
+	// item imported before updates its task; a new one creates a task
+	// linked to the item. Items are validated with the rules of CreateTask,
+	// and when one is invalid nothing changes. A dry run reports what would
+	// change.
+	SyncTasks(ctx context.Context, source string, rows []importer.Row, dryRun bool) (*domain.SyncResult, error)
+}
+
+type syncService struct {
+	tasks domain.TaskRepository
+	refs  domain.ExternalRefRepository
+	tx    domain.TxManager
+}
+
+// NewSyncService returns the sync service. Without tx a failed sync keeps
+// the items synced before the failure.
+func NewSyncService(
+	tasks domain.TaskRepository,
+	refs domain.ExternalRefRepository,
+	tx domain.TxManager,
+) SyncService {
+
+	return &syncService{tasks: tasks, refs: refs, tx: tx}
+}
+
+type syncItem struct {
+	externalID string
+	task       *domain.Task
+}
+
+func (s *syncService) SyncTasks(
+	ctx context.Context,
+	source string,
+	rows []importer.Row,
+	dryRun bool,
+) (*domain.SyncResult, error) {
+
+	result := &domain.SyncResult{DryRun: dryRun, Items: len(rows)}
+	items := make([]syncItem, 0, len(rows))
+	seen := make(map[string]int, len(rows))
+
+	for _, row := range rows {
+		task, err := rowTask(row)
+		if err == nil && row.ExternalID == "" {
+			err = errors.New("item has no id")
+		}
+		if err == nil && seen[row.ExternalID] > 0 {
+			err = fmt.Errorf("item %s is listed twice, first as item %d", row.ExternalID, seen[row.ExternalID])
+		}
+		if err != nil {
+			result.Invalid++
+			if len(result.Errors) < maxImportErrors {
+				result.Errors = append(result.Errors, domain.ImportRowError{Line: row.Line, Message: err.Error()})
+			}
+			continue
+		}
+
+		seen[row.ExternalID] = row.Line
+		items = append(items, syncItem{externalID: row.ExternalID, task: task})
+	}
+
+	if result.Invalid > 0 {
+		return result, nil
+	}
+
+	sync := func(ctx context.Context) error {
+		// The counts of a failed attempt must not add up with a retried one.
+		result.Created, result.Updated, result.Unchanged = 0, 0, 0
+
+		// A concurrent sync of the same source would miss the links this
+		// one creates and import new items twice.
+		if !dryRun {
+			if err := s.refs.Lock(ctx, source); err != nil {
+				return err
+			}
+		}
+
+		for _, item := range items {
+			if err := s.syncItem(ctx, source, item, dryRun, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if dryRun {
+		err = sync(ctx)
+	} else {
+		err = withinTx(ctx, s.tx, sync)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		logging.FromContext(ctx).Info(
+			"tasks synced",
+			"source", source,
+			"created", result.Created,
+			"updated", result.Updated,
+			"unchanged", result.Unchanged,
+		)
+	}
+
+	return result, nil
+}
+
+// syncItem creates or updates the task of item and counts the outcome.
+func (s *syncService) syncItem(
+	ctx context.Context,
+	source string,
+	item syncItem,
+	dryRun bool,
+	result *domain.SyncResult,
+) error {
+
+	var existing *domain.Task
+
+	ref, err := s.refs.Get(ctx, source, item.externalID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+	case err != nil:
+		return err
+	default:
+		existing, err = s.tasks.GetByID(ctx, ref.TaskID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+	}
+
+	// An item whose task was deleted is imported again.
+	if existing == nil {
+		result.Created++
+		if dryRun {
+			return nil
+		}
+
+		created, err := s.tasks.Create(ctx, item.task)
+		if err != nil {
+			return err
+		}
+
+		return s.refs.Put(ctx, &domain.ExternalRef{
+			Source:     source,
+			ExternalID: item.externalID,
+			TaskID:     created.ID,
+		})
+	}
+
+	if sameTaskFields(existing, item.task) {
+		result.Unchanged++
+		return nil
+	}
+
+	result.Updated++
+	if dryRun {
+		return nil
+	}
+
+	existing.Title = item.task.Title
+	existing.Status = item.task.Status
+	existing.Assignees = item.task.Assignees
+	existing.Watchers = item.task.Watchers
+
+	return s.tasks.Update(ctx, existing)
+}
+
+// sameTaskFields reports whether a and b agree on the fields an import sets.
+func sameTaskFields(a, b *domain.Task) bool {
+	return a.Title == b.Title &&
+		a.Status == b.Status &&
+		slices.Equal(a.Assignees, b.Assignees) &&
+		slices.Equal(a.Watchers, b.Watchers)
+}
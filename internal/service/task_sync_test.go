@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"context"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/importer"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var syncRows = []importer.Row{
+	{Line: 1, ExternalID: "10001", Title: "Login fails", Status: "todo", Assignees: []string{"alice"}},
+	{Line: 2, ExternalID: "10002", Title: "Update docs", Status: "done"},
+}
+
+func TestSyncTasks_UpdatesInsteadOfDuplicating(t *testing.T) {
+	repo := memory.NewTaskRepository()
+	tx := &fakeTx{}
+	svc := service.NewSyncService(repo, memory.NewExternalRefRepository(), tx)
+	ctx := context.Background()
+
+	result, err := svc.SyncTasks(ctx, "jira", syncRows, false)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.SyncResult{Items: 2, Created: 2}, result)
+	assert.Equal(t, 1, tx.committed)
+
+	changed := []importer.Row{
+		{Line: 1, ExternalID: "10001", Title: "Login fails", Status: "in_progress", Assignees: []string{"alice"}},
+		syncRows[1],
+		{Line: 3, ExternalID: "10003", Title: "New issue"},
+	}
+
+	result, err = svc.SyncTasks(ctx, "jira", changed, false)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.SyncResult{Items: 3, Created: 1, Updated: 1, Unchanged: 1}, result)
+
+	tasks := listAll(t, repo)
+	require.Len(t, tasks, 3)
+
+	statuses := map[string]domain.TaskStatus{}
+	for _, task := range tasks {
+		statuses[task.Title] = task.Status
+	}
+	assert.Equal(t, map[string]domain.TaskStatus{
+		"Login fails": domain.StatusInProgress,
+		"Update docs": domain.StatusDone,
+		"New issue":   domain.StatusTodo,
+	}, statuses)
+
+	// The same IDs from another tracker are other items.
+	result, err = svc.SyncTasks(ctx, "trello", syncRows, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Created)
+}
+
+// lockingRefs records the sources locked inside a unit of work.
+type lockingRefs struct {
+	domain.ExternalRefRepository
+	locked []string
+}
+
+func (r *lockingRefs) Lock(ctx context.Context, source string) error {
+	if ctx.Value(txKey{}) != nil {
+		r.locked = append(r.locked, source)
+	}
+	return r.ExternalRefRepository.Lock(ctx, source)
+}
+
+func TestSyncTasks_LocksTheSource(t *testing.T) {
+	refs := &lockingRefs{ExternalRefRepository: memory.NewExternalRefRepository()}
+	svc := service.NewSyncService(memory.NewTaskRepository(), refs, &fakeTx{})
+	ctx := context.Background()
+
+	_, err := svc.SyncTasks(ctx, "jira", syncRows, true)
+	require.NoError(t, err)
+	assert.Empty(t, refs.locked, "dry runs change nothing and need no lock")
+
+	_, err = svc.SyncTasks(ctx, "jira", syncRows, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jira"}, refs.locked)
+}
+
+func TestSyncTasks_RecreatesDeletedTasks(t *testing.T) {
+	repo := memory.NewTaskRepository()
+	svc := service.NewSyncService(repo, memory.NewExternalRefRepository(), nil)
+	ctx := context.Background()
+
+	_, err := svc.SyncTasks(ctx, "github", syncRows[:1], false)
+	require.NoError(t, err)
+
+	tasks := listAll(t, repo)
+	require.Len(t, tasks, 1)
+	require.NoError(t, repo.Delete(ctx, tasks[0].ID))
+
+	result, err := svc.SyncTasks(ctx, "github", syncRows[:1], false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Len(t, listAll(t, repo), 1)
+}
+
+func TestSyncTasks_DryRunChangesNothing(t *testing.T) {
+	repo := memory.NewTaskRepository()
+	svc := service.NewSyncService(repo, memory.NewExternalRefRepository(), nil)
+	ctx := context.Background()
+
+	_, err := svc.SyncTasks(ctx, "jira", syncRows[:1], false)
+	require.NoError(t, err)
+
+	renamed := []importer.Row{{Line: 1, ExternalID: "10001", Title: "Login broken"}, syncRows[1]}
+
+	result, err := svc.SyncTasks(ctx, "jira", renamed, true)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.SyncResult{DryRun: true, Items: 2, Created: 1, Updated: 1}, result)
+
+	tasks := listAll(t, repo)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Login fails", tasks[0].Title)
+}
+
+func TestSyncTasks_InvalidItemsChangeNothing(t *testing.T) {
+	repo := memory.NewTaskRepository()
+	svc := service.NewSyncService(repo, memory.NewExternalRefRepository(), nil)
+
+	rows := []importer.Row{
+		syncRows[0],
+		{Line: 2, ExternalID: "10001", Title: "duplicate"},
+		{Line: 3, Title: "no id"},
+		{Line: 4, ExternalID: "10004", Title: ""},
+	}
+
+	result, err := svc.SyncTasks(context.Background(), "jira", rows, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.Invalid)
+	assert.Equal(t, []domain.ImportRowError{
+		{Line: 2, Message: "item 10001 is listed twice, first as item 1"},
+		{Line: 3, Message: "item has no id"},
+		{Line: 4, Message: service.ErrEmptyTitle.Error()},
+	}, result.Errors)
+	assert.Empty(t, listAll(t, repo))
+}
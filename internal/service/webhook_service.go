@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"graph-task-service/internal/domain"
+	"net/url"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrInvalidURL       = errors.New("url must be an absolute http or https url")
+	ErrInvalidEventType = errors.New("invalid event type")
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, url string, events []domain.EventType, secret *string) (*domain.Webhook, error)
+	GetWebhook(ctx context.Context, id string) (*domain.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, id string, url string, events []domain.EventType, active bool) (*domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, webhookID string, limit int, offset int) ([]*domain.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	webhooks   domain.WebhookRepository
+	deliveries domain.WebhookDeliveryRepository
+}
+
+func NewWebhookService(
+	webhooks domain.WebhookRepository,
+	deliveries domain.WebhookDeliveryRepository,
+) WebhookService {
+	return &webhookService{webhooks: webhooks, deliveries: deliveries}
+}
+
+func (s *webhookService) CreateWebhook(
+	ctx context.Context,
+	rawURL string,
+	events []domain.EventType,
+	secret *string,
+) (*domain.Webhook, error) {
+
+	if err := validateWebhook(rawURL, events); err != nil {
+		return nil, err
+	}
+
+	finalSecret := ""
+	if secret != nil && *secret != "" {
+		finalSecret = *secret
+	} else {
+		generated, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		finalSecret = generated
+	}
+
+	webhook := &domain.Webhook{
+		URL:    rawURL,
+		Secret: finalSecret,
+		Events: events,
+		Active: true,
+	}
+
+	return s.webhooks.Create(ctx, webhook)
+}
+
+func (s *webhookService) GetWebhook(
+	ctx context.Context,
+	id string,
+) (*domain.Webhook, error) {
+
+	webhook, err := s.webhooks.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+
+	return webhook, err
+}
+
+func (s *webhookService) ListWebhooks(
+	ctx context.Context,
+) ([]*domain.Webhook, error) {
+
+	return s.webhooks.List(ctx)
+}
+
+func (s *webhookService) UpdateWebhook(
+	ctx context.Context,
+	id string,
+	rawURL string,
+	events []domain.EventType,
+	active bool,
+) (*domain.Webhook, error) {
+
+	if err := validateWebhook(rawURL, events); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.URL = rawURL
+	webhook.Events = events
+	webhook.Active = active
+
+	if err := s.webhooks.Update(ctx, webhook); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *webhookService) DeleteWebhook(
+	ctx context.Context,
+	id string,
+) error {
+
+	err := s.webhooks.Delete(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+
+	return err
+}
+
+func (s *webhookService) ListDeliveries(
+	ctx context.Context,
+	webhookID string,
+	limit int,
+	offset int,
+) ([]*domain.WebhookDelivery, error) {
+
+	if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	return s.deliveries.ListByWebhook(ctx, webhookID, limit, offset)
+}
+
+func validateWebhook(rawURL string, events []domain.EventType) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+
+	for _, e := range events {
+		if !domain.IsValidEventType(e) {
+			return ErrInvalidEventType
+		}
+	}
+
+	return nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,81 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"time"
+)
+
+const replayPageSize = 500
+
+// ErrDropped ends Follow when the client fell more than the hub buffer
+// behind or the hub closed. The client is expected to reconnect with the
+// sequence of the last event it got.
+var ErrDropped = errors.New("event stream dropped")
+
+// Follow sends the events matching filter that were committed after
+// lastSeq, replayed from feed, then the live events of h, until ctx ends,
+// the subscription is dropped or send fails. The subscription is opened
+// before the replay so nothing committed in between is lost; as feeds hand
+// out events in seq order, overlapping events are skipped by seq. Every
+// tick of heartbeat calls keepAlive; a nil heartbeat never ticks.
+func (h *Hub) Follow(
+	ctx context.Context,
+	feed domain.TaskEventFeed,
+	filter domain.TaskFilter,
+	lastSeq int64,
+	heartbeat <-chan time.Time,
+	send func(*domain.SequencedEvent) error,
+	keepAlive func() error,
+) error {
+
+	sub := h.Subscribe(filter)
+	defer h.Unsubscribe(sub)
+
+	sent := lastSeq
+
+	if lastSeq > 0 {
+		for {
+			events, err := feed.Since(ctx, sent, replayPageSize)
+			if err != nil {
+				return err
+			}
+
+			for _, e := range events {
+				sent = e.Seq
+				if !Matches(filter, e) {
+					continue
+				}
+				if err := send(e); err != nil {
+					return err
+				}
+			}
+
+			if len(events) < replayPageSize {
+				break
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.Done():
+			return ErrDropped
+		case <-heartbeat:
+			if err := keepAlive(); err != nil {
+				return err
+			}
+		case e := <-sub.Events():
+			if e.Seq <= sent {
+				continue
+			}
+			sent = e.Seq
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/stream"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replayFeed replays events and broadcasts live on hub while it does, as
+// events committed during a replay are.
+type replayFeed struct {
+	hub    *stream.Hub
+	events []*domain.SequencedEvent
+	live   []*domain.SequencedEvent
+}
+
+func (f *replayFeed) Listen(ctx context.Context, handle func(*domain.SequencedEvent)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (f *replayFeed) Since(ctx context.Context, seq int64, limit int) ([]*domain.SequencedEvent, error) {
+	for _, e := range f.live {
+		f.hub.Broadcast(e)
+	}
+	f.live = nil
+
+	var out []*domain.SequencedEvent
+	for _, e := range f.events {
+		if e.Seq > seq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func TestFollow_SkipsLiveEventsTheReplaySent(t *testing.T) {
+	hub := stream.NewHub(8)
+
+	// 5 and 6 are broadcast while they are replayed.
+	feed := &replayFeed{
+		hub:    hub,
+		events: []*domain.SequencedEvent{event(5, domain.StatusTodo), event(6, domain.StatusTodo)},
+		live: []*domain.SequencedEvent{
+			event(4, domain.StatusTodo),
+			event(5, domain.StatusTodo),
+			event(6, domain.StatusTodo),
+			event(8, domain.StatusTodo),
+		},
+	}
+
+	errDone := errors.New("done")
+	var sent []int64
+
+	err := hub.Follow(context.Background(), feed, domain.TaskFilter{}, 4, nil, func(e *domain.SequencedEvent) error {
+		sent = append(sent, e.Seq)
+		if len(sent) == 3 {
+			return errDone
+		}
+		return nil
+	}, nil)
+
+	require.ErrorIs(t, err, errDone)
+	assert.Equal(t, []int64{5, 6, 8}, sent)
+}
@@ -0,0 +1,111 @@
+package stream
+
+import (
+	"graph-task-service/internal/domain"
+	"sync"
+)
+
+// Hub fans task events out to the streaming clients of this replica.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	buffer int
+	closed bool
+}
+
+func NewHub(buffer int) *Hub {
+	return &Hub{
+		subs:   make(map[*Subscription]struct{}),
+		buffer: buffer,
+	}
+}
+
+// Subscription receives the events that match its filter. When the client
+// falls more than the hub buffer behind, the subscription is dropped and
+// Done is closed; the client is expected to reconnect with its last event ID.
+type Subscription struct {
+	filter domain.TaskFilter
+	events chan *domain.SequencedEvent
+	done   chan struct{}
+}
+
+func (s *Subscription) Events() <-chan *domain.SequencedEvent {
+	return s.events
+}
+
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (h *Hub) Subscribe(filter domain.TaskFilter) *Subscription {
+	sub := &Subscription{
+		filter: filter,
+		events: make(chan *domain.SequencedEvent, h.buffer),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(sub.done)
+		return sub
+	}
+	h.subs[sub] = struct{}{}
+
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.drop(sub)
+}
+
+// Broadcast delivers event to every matching subscription without blocking.
+func (h *Hub) Broadcast(event *domain.SequencedEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		if !Matches(sub.filter, event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			h.drop(sub)
+		}
+	}
+}
+
+// Close ends every subscription, and any later one right away, so streaming
+// clients disconnect when the server shuts down.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subs {
+		h.drop(sub)
+	}
+}
+
+func (h *Hub) drop(sub *Subscription) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.done)
+}
+
+// Matches applies filter to the task carried by event. Deleted events carry
+// the task as it was before deletion.
+func Matches(filter domain.TaskFilter, event *domain.SequencedEvent) bool {
+	if event.Event.Task == nil {
+		return true
+	}
+	return filter.Matches(event.Event.Task)
+}
@@ -0,0 +1,67 @@
+package stream_test
+
+import (
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/stream"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func event(seq int64, status domain.TaskStatus, assignees ...string) *domain.SequencedEvent {
+	return &domain.SequencedEvent{
+		Seq: seq,
+		Event: domain.TaskEvent{
+			Type: domain.EventTaskCreated,
+			Task: &domain.Task{Status: status, Assignees: assignees},
+		},
+	}
+}
+
+func TestHub_DeliversMatchingEvents(t *testing.T) {
+	hub := stream.NewHub(4)
+
+	done := domain.StatusDone
+	sub := hub.Subscribe(domain.TaskFilter{Status: &done})
+	defer hub.Unsubscribe(sub)
+
+	hub.Broadcast(event(1, domain.StatusTodo))
+	hub.Broadcast(event(2, domain.StatusDone))
+
+	got := <-sub.Events()
+	assert.Equal(t, int64(2), got.Seq)
+	assert.Empty(t, sub.Events())
+}
+
+func TestHub_DropsSlowSubscribers(t *testing.T) {
+	hub := stream.NewHub(1)
+	sub := hub.Subscribe(domain.TaskFilter{})
+
+	hub.Broadcast(event(1, domain.StatusTodo))
+	hub.Broadcast(event(2, domain.StatusTodo))
+
+	select {
+	case <-sub.Done():
+	default:
+		t.Fatal("expected subscription to be dropped")
+	}
+
+	// Unsubscribing a dropped subscription is a no-op.
+	hub.Unsubscribe(sub)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := stream.NewHub(4)
+	sub := hub.Subscribe(domain.TaskFilter{})
+
+	hub.Close()
+
+	_, open := <-sub.Done()
+	assert.False(t, open)
+
+	late := hub.Subscribe(domain.TaskFilter{})
+	_, open = <-late.Done()
+	assert.False(t, open)
+
+	hub.Unsubscribe(late)
+}
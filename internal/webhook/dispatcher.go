@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"graph-task-service/internal/domain"
+)
+
+// Dispatcher fans task events out into the delivery queue, one delivery per
+// subscribed webhook. Actual HTTP calls happen later in the Worker.
+type Dispatcher struct {
+	webhooks   domain.WebhookRepository
+	deliveries domain.WebhookDeliveryRepository
+}
+
+func NewDispatcher(
+	webhooks domain.WebhookRepository,
+	deliveries domain.WebhookDeliveryRepository,
+) *Dispatcher {
+	return &Dispatcher{webhooks: webhooks, deliveries: deliveries}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, event domain.TaskEvent) error {
+	hooks, err := d.webhooks.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for _, h := range hooks {
+		if !h.Subscribes(event.Type) {
+			continue
+		}
+
+		deliveries = append(deliveries, &domain.WebhookDelivery{
+			WebhookID: h.ID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   payload,
+		})
+	}
+
+	return d.deliveries.Enqueue(ctx, deliveries)
+}
@@ -0,0 +1,318 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/webhook"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWebhooks and memoryDeliveries stand in for the Postgres repositories.
+type memoryWebhooks struct {
+	hooks []*domain.Webhook
+}
+
+func (m *memoryWebhooks) Create(ctx context.Context, w *domain.Webhook) (*domain.Webhook, error) {
+	w.ID = strconv.Itoa(len(m.hooks) + 1)
+	m.hooks = append(m.hooks, w)
+	return w, nil
+}
+
+func (m *memoryWebhooks) GetByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	for _, w := range m.hooks {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *memoryWebhooks) List(ctx context.Context) ([]*domain.Webhook, error) {
+	return m.hooks, nil
+}
+
+func (m *memoryWebhooks) Update(ctx context.Context, w *domain.Webhook) error { return nil }
+
+func (m *memoryWebhooks) Delete(ctx context.Context, id string) error { return nil }
+
+type memoryDeliveries struct {
+	mu         sync.Mutex
+	deliveries []*domain.WebhookDelivery
+	retryIn    []time.Duration
+	// failMark fails recording the attempts of the delivery with this ID.
+	failMark string
+}
+
+func (m *memoryDeliveries) Enqueue(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, d := range deliveries {
+		d.ID = strconv.Itoa(len(m.deliveries) + 1)
+		d.Status = domain.DeliveryPending
+		m.deliveries = append(m.deliveries, d)
+	}
+	return nil
+}
+
+// ClaimDue ignores scheduling so tests can drive retries without sleeping.
+func (m *memoryDeliveries) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []*domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.Status == domain.DeliveryPending && len(due) < limit {
+			copied := *d
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryDeliveries) find(id string) *domain.WebhookDelivery {
+	for _, d := range m.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+func (m *memoryDeliveries) MarkSucceeded(ctx context.Context, id string, responseCode int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if id == m.failMark {
+		return errors.New("connection reset")
+	}
+
+	d := m.find(id)
+	d.Status = domain.DeliverySucceeded
+	d.Attempts++
+	d.ResponseCode = &responseCode
+	return nil
+}
+
+func (m *memoryDeliveries) MarkFailed(ctx context.Context, id string, responseCode *int, lastErr string, retryIn *time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if id == m.failMark {
+		return errors.New("connection reset")
+	}
+
+	d := m.find(id)
+	d.Attempts++
+	d.ResponseCode = responseCode
+	d.LastError = &lastErr
+	if retryIn == nil {
+		d.Status = domain.DeliveryFailed
+	} else {
+		m.retryIn = append(m.retryIn, *retryIn)
+	}
+	return nil
+}
+
+func (m *memoryDeliveries) ListByWebhook(ctx context.Context, webhookID string, limit int, offset int) ([]*domain.WebhookDelivery, error) {
+	return m.deliveries, nil
+}
+
+// receiver is a local webhook endpoint that verifies signatures and answers
+// with the queued status codes, then 200 once the queue is empty.
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	statuses []int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !webhook.Verify(r.secret, req.Header.Get(webhook.TimestampHeader), body, req.Header.Get(webhook.SignatureHeader), time.Minute) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func setup(t *testing.T, statuses ...int) (*receiver, *memoryWebhooks, *memoryDeliveries, *webhook.Worker) {
+	t.Helper()
+
+	recv := &receiver{secret: "s3cret", statuses: statuses}
+	srv := httptest.NewServer(recv)
+	t.Cleanup(srv.Close)
+
+	hooks := &memoryWebhooks{}
+	_, err := hooks.Create(context.Background(), &domain.Webhook{
+		URL:    srv.URL,
+		Secret: recv.secret,
+		Events: []domain.EventType{domain.EventTaskCreated},
+		Active: true,
+	})
+	require.NoError(t, err)
+
+	deliveries := &memoryDeliveries{}
+
+	cfg := webhook.DefaultWorkerConfig()
+	cfg.MaxAttempts = 3
+	cfg.BaseBackoff = time.Second
+
+	return recv, hooks, deliveries, webhook.NewWorker(hooks, deliveries, cfg)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"type":"task.created"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	sig := webhook.Sign("key", now, body)
+
+	assert.True(t, webhook.Verify("key", now, body, sig, time.Minute))
+	assert.False(t, webhook.Verify("other", now, body, sig, time.Minute))
+	assert.False(t, webhook.Verify("key", now, []byte(`{}`), sig, time.Minute))
+
+	// A captured request replayed with another timestamp, or much later.
+	later := strconv.FormatInt(time.Now().Unix()+1, 10)
+	assert.False(t, webhook.Verify("key", later, body, sig, time.Minute))
+
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	assert.False(t, webhook.Verify("key", old, body, webhook.Sign("key", old, body), time.Minute))
+}
+
+func TestDispatcher_SkipsUnsubscribedEvents(t *testing.T) {
+	_, hooks, deliveries, _ := setup(t)
+	dispatcher := webhook.NewDispatcher(hooks, deliveries)
+
+	ctx := context.Background()
+	require.NoError(t, dispatcher.Publish(ctx, domain.TaskEvent{ID: "e1", Type: domain.EventTaskDeleted}))
+	require.NoError(t, dispatcher.Publish(ctx, domain.TaskEvent{ID: "e2", Type: domain.EventTaskCreated}))
+
+	require.Len(t, deliveries.deliveries, 1)
+	assert.Equal(t, "e2", deliveries.deliveries[0].EventID)
+}
+
+func TestWorker_DeliversSignedPayload(t *testing.T) {
+	recv, hooks, deliveries, worker := setup(t)
+	dispatcher := webhook.NewDispatcher(hooks, deliveries)
+
+	ctx := context.Background()
+	event := domain.TaskEvent{
+		ID:     "e1",
+		Type:   domain.EventTaskCreated,
+		TaskID: "t1",
+		Task:   &domain.Task{ID: "t1", Title: "ship it"},
+	}
+	require.NoError(t, dispatcher.Publish(ctx, event))
+
+	n, err := worker.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.Len(t, recv.bodies, 1)
+	var got domain.TaskEvent
+	require.NoError(t, json.Unmarshal(recv.bodies[0], &got))
+	assert.Equal(t, "ship it", got.Task.Title)
+	assert.Equal(t, "task.created", recv.headers[0].Get(webhook.EventHeader))
+	assert.Equal(t, domain.DeliverySucceeded, deliveries.deliveries[0].Status)
+}
+
+func TestWorker_ClaimsWhatFitsInTheLease(t *testing.T) {
+	_, hooks, deliveries, _ := setup(t)
+	dispatcher := webhook.NewDispatcher(hooks, deliveries)
+
+	ctx := context.Background()
+	for i := range 5 {
+		require.NoError(t, dispatcher.Publish(ctx, domain.TaskEvent{ID: strconv.Itoa(i), Type: domain.EventTaskCreated}))
+	}
+
+	cfg := webhook.DefaultWorkerConfig()
+	cfg.Lease = 25 * time.Second
+	cfg.Timeout = 10 * time.Second
+
+	n, err := webhook.NewWorker(hooks, deliveries, cfg).ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestWorker_ContinuesAfterAFailedDelivery(t *testing.T) {
+	recv, hooks, deliveries, worker := setup(t)
+	dispatcher := webhook.NewDispatcher(hooks, deliveries)
+
+	ctx := context.Background()
+	require.NoError(t, dispatcher.Publish(ctx, domain.TaskEvent{ID: "e1", Type: domain.EventTaskCreated}))
+	require.NoError(t, dispatcher.Publish(ctx, domain.TaskEvent{ID: "e2", Type: domain.EventTaskCreated}))
+	deliveries.failMark = deliveries.deliveries[0].ID
+
+	n, err := worker.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.Len(t, recv.bodies, 2)
+	assert.Equal(t, domain.DeliveryPending, deliveries.deliveries[0].Status, "retried once its lease ends")
+	assert.Equal(t, domain.DeliverySucceeded, deliveries.deliveries[1].Status)
+}
+
+func TestWorker_RetriesWithExponentialBackoff(t *testing.T) {
+	recv, hooks, deliveries, worker := setup(t, http.StatusInternalServerError, http.StatusBadGateway)
+	dispatcher := webhook.NewDispatcher(hooks, deliveries)
+
+	ctx := context.Background()
+	require.NoError(t, dispatcher.Publish(ctx, domain.TaskEvent{ID: "e1", Type: domain.EventTaskCreated}))
+
+	for i := 0; i < 3; i++ {
+		_, err := worker.ProcessDue(ctx)
+		require.NoError(t, err)
+	}
+
+	d := deliveries.deliveries[0]
+	assert.Equal(t, domain.DeliverySucceeded, d.Status)
+	assert.Equal(t, 3, d.Attempts)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, deliveries.retryIn)
+	assert.Len(t, recv.bodies, 3)
+}
+
+func TestWorker_GivesUpAfterMaxAttempts(t *testing.T) {
+	_, hooks, deliveries, worker := setup(t,
+		http.StatusInternalServerError,
+		http.StatusInternalServerError,
+		http.StatusInternalServerError,
+	)
+	dispatcher := webhook.NewDispatcher(hooks, deliveries)
+
+	ctx := context.Background()
+	require.NoError(t, dispatcher.Publish(ctx, domain.TaskEvent{ID: "e1", Type: domain.EventTaskCreated}))
+
+	for i := 0; i < 5; i++ {
+		_, err := worker.ProcessDue(ctx)
+		require.NoError(t, err)
+	}
+
+	d := deliveries.deliveries[0]
+	assert.Equal(t, domain.DeliveryFailed, d.Status)
+	assert.Equal(t, 3, d.Attempts)
+	require.NotNil(t, d.ResponseCode)
+	assert.Equal(t, http.StatusInternalServerError, *d.ResponseCode)
+}
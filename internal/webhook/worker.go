@@ -0,0 +1,193 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type WorkerConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// Lease is how long a claimed delivery stays invisible to other workers.
+	// A claim takes no more deliveries than can time out within it, so none
+	// is claimed again while it is still being sent.
+	Lease   time.Duration
+	Timeout time.Duration
+}
+
+func DefaultWorkerConfig() WorkerConfig {
+	return WorkerConfig{
+		PollInterval: 2 * time.Second,
+		BatchSize:    20,
+		MaxAttempts:  8,
+		BaseBackoff:  5 * time.Second,
+		MaxBackoff:   time.Hour,
+		Lease:        4 * time.Minute,
+		Timeout:      10 * time.Second,
+	}
+}
+
+// Worker sends queued deliveries, retrying failures with exponential backoff.
+// Several workers may run against the same queue; ClaimDue keeps them apart.
+type Worker struct {
+	webhooks   domain.WebhookRepository
+	deliveries domain.WebhookDeliveryRepository
+	client     *http.Client
+	cfg        WorkerConfig
+}
+
+func NewWorker(
+	webhooks domain.WebhookRepository,
+	deliveries domain.WebhookDeliveryRepository,
+	cfg WorkerConfig,
+) *Worker {
+	return &Worker{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		cfg:        cfg,
+	}
+}
+
+// Run processes the queue every PollInterval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.ProcessDue(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "webhook worker", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue makes one attempt for every delivery that is currently due and
+// returns how many were attempted. A delivery whose attempt cannot be
+// recorded is logged and skipped; it is claimed again once its lease ends.
+func (w *Worker) ProcessDue(ctx context.Context) (int, error) {
+	due, err := w.deliveries.ClaimDue(ctx, w.claimSize(), w.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	hooks := make(map[string]*domain.Webhook)
+
+	for _, d := range due {
+		hook, ok := hooks[d.WebhookID]
+		if !ok {
+			hook, err = w.webhooks.GetByID(ctx, d.WebhookID)
+			if err != nil && !errors.Is(err, domain.ErrNotFound) {
+				slog.ErrorContext(ctx, "webhook worker: load webhook", "delivery_id", d.ID, "error", err)
+				continue
+			}
+			hooks[d.WebhookID] = hook
+		}
+
+		if err := w.attempt(ctx, hook, d); err != nil {
+			slog.ErrorContext(ctx, "webhook worker: record attempt", "delivery_id", d.ID, "error", err)
+		}
+	}
+
+	return len(due), nil
+}
+
+// claimSize is BatchSize capped to the deliveries that can all time out
+// within Lease, and at least one.
+func (w *Worker) claimSize() int {
+	if w.cfg.Timeout <= 0 {
+		return w.cfg.BatchSize
+	}
+	return max(min(w.cfg.BatchSize, int((w.cfg.Lease-1)/w.cfg.Timeout)), 1)
+}
+
+func (w *Worker) attempt(
+	ctx context.Context,
+	hook *domain.Webhook,
+	d *domain.WebhookDelivery,
+) error {
+
+	if hook == nil || !hook.Active {
+		return w.deliveries.MarkFailed(ctx, d.ID, nil, "webhook deleted or inactive", nil)
+	}
+
+	code, err := w.send(ctx, hook, d)
+	if err == nil {
+		return w.deliveries.MarkSucceeded(ctx, d.ID, code)
+	}
+
+	var responseCode *int
+	if code != 0 {
+		responseCode = &code
+	}
+
+	var retryIn *time.Duration
+	if d.Attempts+1 < w.cfg.MaxAttempts {
+		backoff := w.backoff(d.Attempts)
+		retryIn = &backoff
+	}
+
+	return w.deliveries.MarkFailed(ctx, d.ID, responseCode, err.Error(), retryIn)
+}
+
+func (w *Worker) send(
+	ctx context.Context,
+	hook *domain.Webhook,
+	d *domain.WebhookDelivery,
+) (int, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "graph-task-service-webhooks")
+	req.Header.Set(EventHeader, string(d.EventType))
+	req.Header.Set(DeliveryHeader, d.ID)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, timestamp, d.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff doubles BaseBackoff for every previous attempt, capped at MaxBackoff.
+func (w *Worker) backoff(previousAttempts int) time.Duration {
+	delay := w.cfg.BaseBackoff
+	for i := 0; i < previousAttempts; i++ {
+		delay *= 2
+		if delay >= w.cfg.MaxBackoff {
+			return w.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,11 @@
+DROP INDEX idx_tasks_watchers;
+DROP INDEX idx_tasks_assignees;
+
+ALTER TABLE tasks ADD COLUMN assignee TEXT;
+
+UPDATE tasks SET assignee = assignees[1] WHERE cardinality(assignees) > 0;
+
+CREATE INDEX idx_tasks_assignee ON tasks(assignee);
+
+ALTER TABLE tasks DROP COLUMN watchers;
+ALTER TABLE tasks DROP COLUMN assignees;
@@ -0,0 +1,10 @@
+ALTER TABLE tasks ADD COLUMN assignees TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tasks ADD COLUMN watchers TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE tasks SET assignees = ARRAY[assignee] WHERE assignee IS NOT NULL;
+
+DROP INDEX idx_tasks_assignee;
+ALTER TABLE tasks DROP COLUMN assignee;
+
+CREATE INDEX idx_tasks_assignees ON tasks USING GIN (assignees);
+CREATE INDEX idx_tasks_watchers ON tasks USING GIN (watchers);
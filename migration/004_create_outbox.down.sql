@@ -0,0 +1,3 @@
+DROP INDEX idx_webhook_deliveries_event;
+
+DROP TABLE outbox_events;
@@ -0,0 +1,3 @@
+DROP INDEX idx_outbox_events_seq;
+
+ALTER TABLE outbox_events DROP COLUMN seq;
@@ -0,0 +1,3 @@
+ALTER TABLE outbox_events ADD COLUMN seq BIGSERIAL;
+
+CREATE UNIQUE INDEX idx_outbox_events_seq ON outbox_events(seq);
@@ -0,0 +1,8 @@
+CREATE TABLE task_external_refs (
+    source TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    PRIMARY KEY (source, external_id)
+);
+
+CREATE INDEX idx_task_external_refs_task_id ON task_external_refs(task_id);
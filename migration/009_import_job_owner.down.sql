@@ -0,0 +1,3 @@
+DROP INDEX idx_import_jobs_unfinished;
+
+ALTER TABLE import_jobs DROP COLUMN created_by;
@@ -0,0 +1,3 @@
+ALTER TABLE import_jobs ADD COLUMN created_by TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_import_jobs_unfinished ON import_jobs(updated_at) WHERE status IN ('pending', 'running');
@@ -0,0 +1,10 @@
+// Package migration embeds the numbered SQL migrations of the service.
+//
+// Every change is a pair of files, NNN_name.up.sql and NNN_name.down.sql.
+// Applied files must never be edited: the runner compares checksums.
+package migration
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,327 @@
+// Package client is a Go client for the REST API of the task service. It
+// mirrors the routes of the Swagger spec in docs/: tasks, imports, exports
+// and webhooks. The event streams are plain Server-Sent Events and
+// WebSockets and are left to the libraries for those.
+//
+// Every call takes a context, and failed calls are retried when that is
+// safe; see RetryPolicy.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy bounds how often a request that failed transiently is sent
+// again. The wait starts at Backoff and doubles on each retry, unless the
+// server asks for longer with Retry-After.
+//
+// A write that failed with a network error may have reached the server, so
+// it is only sent again with RetryWrites. Set it for servers backed by
+// Postgres, which answer a repeated Idempotency-Key with the original
+// response; the others would run the write twice.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	RetryWrites bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, Backoff: 200 * time.Millisecond}
+}
+
+// Options configure a Client. The zero value talks to the server
+// anonymously with http.DefaultClient and DefaultRetryPolicy.
+type Options struct {
+	HTTPClient *http.Client
+	// Token is sent as a bearer token, for gateways in front of the API.
+	Token string
+	// User is the caller identity sent in UserHeader, X-User unless set.
+	User       string
+	UserHeader string
+	// Retry replaces DefaultRetryPolicy; MaxAttempts 1 disables retries.
+	Retry     *RetryPolicy
+	UserAgent string
+}
+
+type Client struct {
+	base       string
+	http       *http.Client
+	opts       Options
+	retry      RetryPolicy
+	userHeader string
+}
+
+// New returns a client of the API served at baseURL, e.g.
+// https://tasks.example.com.
+func New(baseURL string, opts Options) (*Client, error) {
+	base, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+
+	c := &Client{
+		base:       base.String(),
+		http:       opts.HTTPClient,
+		opts:       opts,
+		retry:      DefaultRetryPolicy(),
+		userHeader: opts.UserHeader,
+	}
+	if c.http == nil {
+		c.http = http.DefaultClient
+	}
+	if opts.Retry != nil {
+		c.retry = *opts.Retry
+	}
+	if c.userHeader == "" {
+		c.userHeader = "X-User"
+	}
+
+	return c, nil
+}
+
+// APIError is an error response of the API.
+type APIError struct {
+	StatusCode int
+	Message    string
+	// RequestID identifies the request in the server logs.
+	RequestID string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("task api: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsNotFound reports whether err is a 404 response, e.g. for an unknown
+// task.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// request is one API call. body is sent again on every attempt.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        io.ReadSeeker
+	contentType string
+	// accept lists further successful status codes whose body is decoded
+	// into out like a 2xx response.
+	accept []int
+}
+
+func (c *Client) newJSONRequest(method, path string, body any) (*request, error) {
+	req := &request{method: method, path: path}
+	if body == nil {
+		return req, nil
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req.body = bytes.NewReader(data)
+	req.contentType = "application/json"
+
+	return req, nil
+}
+
+// do sends req, retrying as the retry policy allows, and decodes the
+// response body into out unless out is nil.
+func (c *Client) do(ctx context.Context, req *request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("task api: decode response: %w", err)
+	}
+	return nil
+}
+
+// send sends req and returns a successful response, whose body the caller
+// must close.
+//
+// Writes carry an Idempotency-Key that is the same on every attempt, so a
+// retried write whose first attempt reached the server is answered with the
+// original response instead of running twice. Servers without Postgres do
+// not keep idempotency keys, so writes that failed with a network error are
+// only retried with RetryPolicy.RetryWrites.
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	u := c.base + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	var key string
+	if req.method != http.MethodGet {
+		key = newIdempotencyKey()
+	}
+
+	backoff := c.retry.Backoff
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, req, u, key)
+
+		retryIn := backoff
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || (key != "" && !c.retry.RetryWrites) {
+				return nil, err
+			}
+		case resp.StatusCode < 300 || containsStatus(req.accept, resp.StatusCode):
+			return resp, nil
+		default:
+			err = readAPIError(resp)
+			if !retryable(resp) {
+				return nil, err
+			}
+			if after, ok := retryAfter(resp); ok {
+				retryIn = max(retryIn, after)
+			}
+		}
+
+		if attempt >= c.retry.MaxAttempts || !rewind(req.body) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(retryIn):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, req *request, u, key string) (*http.Response, error) {
+	var body io.Reader
+	if req.body != nil {
+		body = req.body
+	}
+
+	r, err := http.NewRequestWithContext(ctx, req.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.contentType != "" {
+		r.Header.Set("Content-Type", req.contentType)
+	}
+	if key != "" {
+		r.Header.Set("Idempotency-Key", key)
+	}
+	if c.opts.Token != "" {
+		r.Header.Set("Authorization", "Bearer "+c.opts.Token)
+	}
+	if c.opts.User != "" {
+		r.Header.Set(c.userHeader, c.opts.User)
+	}
+	if c.opts.UserAgent != "" {
+		r.Header.Set("User-Agent", c.opts.UserAgent)
+	}
+
+	return c.http.Do(r)
+}
+
+// retryable reports whether the server may answer the same request
+// differently later: it was throttled, or a gateway or the service was
+// briefly unavailable. A 409 is retried only when it carries Retry-After,
+// which the server sets when a retry races its original request; other
+// conflicts are final.
+func retryable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusConflict:
+		return resp.Header.Get("Retry-After") != ""
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	s := resp.Header.Get("Retry-After")
+	if s == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(s); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(s); err == nil {
+		return time.Until(at), true
+	}
+	return 0, false
+}
+
+// rewind prepares body to be sent again.
+func rewind(body io.ReadSeeker) bool {
+	if body == nil {
+		return true
+	}
+	_, err := body.Seek(0, io.SeekStart)
+	return err == nil
+}
+
+func readAPIError(resp *http.Response) error {
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &body) != nil || body.Error == "" {
+		body.Error = strings.TrimSpace(string(data))
+	}
+
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    body.Error,
+		RequestID:  resp.Header.Get("X-Request-ID"),
+	}
+}
+
+func containsStatus(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// escape escapes a path segment such as a task ID.
+func escape(segment string) string {
+	return url.PathEscape(segment)
+}
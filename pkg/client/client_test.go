@@ -0,0 +1,247 @@
+package client_test
+
+import (
+	"context"
+	"graph-task-service/pkg/client"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer fails the first failures requests with status, or drops their
+// connection if status is 0, and then answers with an empty task list,
+// recording what it received.
+type flakyServer struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	header   http.Header
+	keys     []string
+	bodies   []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = append(s.keys, r.Header.Get("Idempotency-Key"))
+	s.bodies = append(s.bodies, string(body))
+
+	if len(s.keys) <= s.failures {
+		if s.status == 0 {
+			panic(http.ErrAbortHandler)
+		}
+		for k, v := range s.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(s.status)
+		w.Write([]byte(`{"error":"try again"}`))
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		w.Write([]byte(`[]`))
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(`{"id":"1","title":"t","status":"todo","assignees":[],"watchers":[],` +
+		`"created_at":"2026-01-02T03:04:05Z","updated_at":"2026-01-02T03:04:05Z"}`))
+}
+
+func (s *flakyServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.keys)
+}
+
+func newFlakyClient(t *testing.T, s *flakyServer, policy client.RetryPolicy) *client.Client {
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(srv.URL, client.Options{Retry: &policy})
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew_RejectsInvalidURL(t *testing.T) {
+	for _, raw := range []string{"", "localhost:8080", "ftp://tasks.example.com", "http://%zz"} {
+		_, err := client.New(raw, client.Options{})
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestClient_RetriesTransientFailures(t *testing.T) {
+	s := &flakyServer{failures: 2, status: http.StatusServiceUnavailable}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	tasks, err := c.ListTasks(context.Background(), client.ListTasksParams{})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+	assert.Equal(t, 3, s.requests())
+}
+
+func TestClient_RetriedWriteKeepsIdempotencyKeyAndBody(t *testing.T) {
+	s := &flakyServer{failures: 1, status: http.StatusBadGateway}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond})
+
+	task, err := c.CreateTask(context.Background(), client.CreateTaskRequest{Title: "t"})
+	require.NoError(t, err)
+	assert.Equal(t, "1", task.ID)
+
+	require.Len(t, s.keys, 2)
+	assert.NotEmpty(t, s.keys[0])
+	assert.Equal(t, s.keys[0], s.keys[1])
+	assert.Equal(t, `{"title":"t"}`, s.bodies[0])
+	assert.Equal(t, s.bodies[0], s.bodies[1])
+}
+
+func TestClient_DoesNotRetryWritesAfterNetworkErrors(t *testing.T) {
+	s := &flakyServer{failures: 1}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	_, err := c.CreateTask(context.Background(), client.CreateTaskRequest{Title: "t"})
+	require.Error(t, err)
+	assert.Equal(t, 1, s.requests())
+}
+
+func TestClient_RetriesWritesAfterNetworkErrorsWhenAllowed(t *testing.T) {
+	s := &flakyServer{failures: 1}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, RetryWrites: true})
+
+	_, err := c.CreateTask(context.Background(), client.CreateTaskRequest{Title: "t"})
+	require.NoError(t, err)
+	require.Len(t, s.keys, 2)
+	assert.Equal(t, s.keys[0], s.keys[1])
+}
+
+func TestClient_RetriesReadsAfterNetworkErrors(t *testing.T) {
+	s := &flakyServer{failures: 1}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	_, err := c.ListTasks(context.Background(), client.ListTasksParams{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.requests())
+}
+
+func TestClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	s := &flakyServer{failures: 5, status: http.StatusTooManyRequests}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond})
+
+	_, err := c.ListTasks(context.Background(), client.ListTasksParams{})
+
+	var apiErr *client.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, "try again", apiErr.Message)
+	assert.Equal(t, 2, s.requests())
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	s := &flakyServer{failures: 1, status: http.StatusBadRequest}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	_, err := c.CreateTask(context.Background(), client.CreateTaskRequest{Title: "t"})
+	require.Error(t, err)
+	assert.Equal(t, 1, s.requests())
+}
+
+func TestClient_RetriesConflictsOfARunningRequest(t *testing.T) {
+	s := &flakyServer{
+		failures: 1,
+		status:   http.StatusConflict,
+		header:   http.Header{"Retry-After": {"0"}},
+	}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond})
+
+	_, err := c.CreateTask(context.Background(), client.CreateTaskRequest{Title: "t"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.requests())
+}
+
+func TestClient_DoesNotRetryOtherConflicts(t *testing.T) {
+	s := &flakyServer{failures: 1, status: http.StatusConflict}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	_, err := c.CreateTask(context.Background(), client.CreateTaskRequest{Title: "t"})
+
+	var apiErr *client.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+	assert.Equal(t, 1, s.requests())
+}
+
+func TestClient_HonorsRetryAfter(t *testing.T) {
+	s := &flakyServer{
+		failures: 1,
+		status:   http.StatusServiceUnavailable,
+		header:   http.Header{"Retry-After": {"1"}},
+	}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond})
+
+	start := time.Now()
+	_, err := c.ListTasks(context.Background(), client.ListTasksParams{})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestClient_StopsRetryingWhenContextEnds(t *testing.T) {
+	s := &flakyServer{failures: 5, status: http.StatusServiceUnavailable}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 5, Backoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.ListTasks(ctx, client.ListTasksParams{})
+	require.Error(t, err)
+	assert.Equal(t, 1, s.requests())
+}
+
+func TestClient_StopsPaginationAtFirstError(t *testing.T) {
+	s := &flakyServer{failures: 5, status: http.StatusInternalServerError}
+	c := newFlakyClient(t, s, client.RetryPolicy{MaxAttempts: 1})
+
+	var errs []error
+	for task, err := range c.Tasks(context.Background(), client.ListTasksParams{}) {
+		assert.Empty(t, task.ID)
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+
+	var apiErr *client.APIError
+	require.ErrorAs(t, errs[0], &apiErr)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+}
+
+func TestClient_SendsIdentity(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(srv.URL+"/", client.Options{
+		Token:      "t0ken",
+		User:       "alice",
+		UserHeader: "X-Forwarded-User",
+		UserAgent:  "tests",
+	})
+	require.NoError(t, err)
+
+	_, err = c.ListTasks(context.Background(), client.ListTasksParams{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer t0ken", got.Get("Authorization"))
+	assert.Equal(t, "alice", got.Get("X-Forwarded-User"))
+	assert.Equal(t, "tests", got.Get("User-Agent"))
+	assert.Empty(t, got.Get("Idempotency-Key"), "reads carry no idempotency key")
+}
@@ -0,0 +1,473 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"graph-task-service/internal/domain"
+	"graph-task-service/internal/handler/http"
+	"graph-task-service/internal/health"
+	"graph-task-service/internal/observability"
+	"graph-task-service/internal/repository/memory"
+	"graph-task-service/internal/router"
+	"graph-task-service/internal/service"
+	"graph-task-service/pkg/client"
+	"io"
+	"log/slog"
+	nethttp "net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The contract tests run the client against the real router, so a route or
+// payload that drifts from the client breaks them. Every API route must be
+// called by at least one of them.
+
+// webhookStore keeps webhooks and their deliveries in memory; the service
+// has no memory repositories for them.
+type webhookStore struct {
+	mu         sync.Mutex
+	webhooks   map[string]*domain.Webhook
+	deliveries []*domain.WebhookDelivery
+	seq        int
+}
+
+func newWebhookStore() *webhookStore {
+	return &webhookStore{webhooks: map[string]*domain.Webhook{}}
+}
+
+func (s *webhookStore) Create(ctx context.Context, w *domain.Webhook) (*domain.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	created := *w
+	created.ID = fmt.Sprintf("wh-%d", s.seq)
+	created.CreatedAt = time.Now().UTC()
+	created.UpdatedAt = created.CreatedAt
+	s.webhooks[created.ID] = &created
+
+	out := created
+	return &out, nil
+}
+
+func (s *webhookStore) GetByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.webhooks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	out := *w
+	return &out, nil
+}
+
+func (s *webhookStore) List(ctx context.Context) ([]*domain.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]*domain.Webhook, 0, len(s.webhooks))
+	for _, w := range s.webhooks {
+		out := *w
+		list = append(list, &out)
+	}
+	slices.SortFunc(list, func(a, b *domain.Webhook) int { return strings.Compare(a.ID, b.ID) })
+	return list, nil
+}
+
+func (s *webhookStore) Update(ctx context.Context, w *domain.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[w.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	updated := *w
+	updated.UpdatedAt = time.Now().UTC()
+	s.webhooks[w.ID] = &updated
+	return nil
+}
+
+func (s *webhookStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(s.webhooks, id)
+	return nil
+}
+
+func (s *webhookStore) Enqueue(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries = append(s.deliveries, deliveries...)
+	return nil
+}
+
+func (s *webhookStore) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (s *webhookStore) MarkSucceeded(ctx context.Context, id string, responseCode int) error {
+	return nil
+}
+
+func (s *webhookStore) MarkFailed(
+	ctx context.Context,
+	id string,
+	responseCode *int,
+	lastErr string,
+	retryIn *time.Duration,
+) error {
+
+	return nil
+}
+
+func (s *webhookStore) ListByWebhook(ctx context.Context, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var list []*domain.WebhookDelivery
+	for _, d := range s.deliveries {
+		if d.WebhookID == webhookID {
+			list = append(list, d)
+		}
+	}
+	if offset >= len(list) {
+		return nil, nil
+	}
+	return list[offset:min(offset+limit, len(list))], nil
+}
+
+// routeRecorder notes which routes of the router the client called.
+type routeRecorder struct {
+	next  nethttp.Handler
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *routeRecorder) ServeHTTP(w nethttp.ResponseWriter, req *nethttp.Request) {
+	r.mu.Lock()
+	r.calls = append(r.calls, req.Method+" "+req.URL.Path)
+	r.mu.Unlock()
+
+	r.next.ServeHTTP(w, req)
+}
+
+// called reports whether a request matched route, such as GET /tasks/:id.
+// A literal segment is preferred over a parameter, as in the router, so
+// GET /tasks/export does not count as GET /tasks/:id.
+func (r *routeRecorder) called(route gin.RouteInfo, routes gin.RoutesInfo) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, call := range r.calls {
+		method, path, _ := strings.Cut(call, " ")
+		if method != route.Method || !matchRoute(route.Path, path) {
+			continue
+		}
+
+		best := true
+		for _, other := range routes {
+			if other.Method == method && other.Path != route.Path &&
+				matchRoute(other.Path, path) && literals(other.Path) > literals(route.Path) {
+				best = false
+			}
+		}
+		if best {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRoute(route, path string) bool {
+	want := strings.Split(route, "/")
+	got := strings.Split(path, "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if !strings.HasPrefix(want[i], ":") && want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func literals(route string) int {
+	n := 0
+	for _, s := range strings.Split(route, "/") {
+		if !strings.HasPrefix(s, ":") {
+			n++
+		}
+	}
+	return n
+}
+
+// uncovered lists the API routes nobody called; probes, metrics and the
+// event streams are not part of the client.
+func uncovered(engine *gin.Engine, rec *routeRecorder) []string {
+	routes := engine.Routes()
+
+	var missing []string
+	for _, route := range routes {
+		switch {
+		case route.Path == "/livez", route.Path == "/readyz", route.Path == "/health",
+			route.Path == "/metrics", strings.HasPrefix(route.Path, "/events/"):
+			continue
+		}
+		if !rec.called(route, routes) {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	return missing
+}
+
+func newContractServer(t *testing.T) (*gin.Engine, *routeRecorder, *webhookStore, string) {
+	gin.SetMode(gin.TestMode)
+
+	tasks := memory.NewTaskRepository()
+	imports := service.NewImportService(tasks, memory.NewImportJobRepository(), nil, 1, 1)
+	t.Cleanup(func() { imports.Close(context.Background()) })
+	webhooks := newWebhookStore()
+
+	engine := router.New(
+		http.NewTaskHandler(service.NewTaskService(tasks, nil)),
+		http.NewImportHandler(imports),
+		http.NewWebhookHandler(service.NewWebhookService(webhooks, webhooks)),
+		nil,
+		nil,
+		http.NewHealthHandler(health.NewRegistry(time.Second)),
+		nil,
+		observability.NewMetrics(),
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		router.Options{MaxBodyBytes: 1 << 20, MaxImportBytes: 1 << 20, UserHeader: "X-User"},
+	)
+
+	rec := &routeRecorder{next: engine}
+	srv := httptest.NewServer(rec)
+	t.Cleanup(srv.Close)
+
+	return engine, rec, webhooks, srv.URL
+}
+
+func TestContract(t *testing.T) {
+	engine, rec, webhooks, url := newContractServer(t)
+	ctx := context.Background()
+
+	c, err := client.New(url, client.Options{User: "alice"})
+	require.NoError(t, err)
+
+	t.Run("tasks", func(t *testing.T) {
+		task, err := c.CreateTask(ctx, client.CreateTaskRequest{
+			Title:     "Write the client",
+			Status:    client.StatusInProgress,
+			Assignees: []string{"alice"},
+			Watchers:  []string{"bob"},
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, task.ID)
+		assert.Equal(t, "Write the client", task.Title)
+		assert.Equal(t, client.StatusInProgress, task.Status)
+		assert.Equal(t, []string{"alice"}, task.Assignees)
+		assert.Equal(t, []string{"bob"}, task.Watchers)
+		assert.False(t, task.CreatedAt.IsZero())
+
+		got, err := c.GetTask(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, task, got)
+
+		got, err = c.UpdateTaskStatus(ctx, task.ID, client.StatusDone)
+		require.NoError(t, err)
+		assert.Equal(t, client.StatusDone, got.Status)
+
+		got, err = c.AddAssignee(ctx, task.ID, "carol")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice", "carol"}, got.Assignees)
+
+		got, err = c.RemoveAssignee(ctx, task.ID, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"carol"}, got.Assignees)
+
+		got, err = c.AddWatcher(ctx, task.ID, "dave")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bob", "dave"}, got.Watchers)
+
+		got, err = c.RemoveWatcher(ctx, task.ID, "bob")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dave"}, got.Watchers)
+
+		require.NoError(t, c.DeleteTask(ctx, task.ID))
+
+		_, err = c.GetTask(ctx, task.ID)
+		assert.True(t, client.IsNotFound(err), "got %v", err)
+
+		var apiErr *client.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.NotEmpty(t, apiErr.RequestID)
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		_, err := c.CreateTask(ctx, client.CreateTaskRequest{Title: "x", Status: "someday"})
+
+		var apiErr *client.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, nethttp.StatusBadRequest, apiErr.StatusCode)
+		assert.NotEmpty(t, apiErr.Message)
+	})
+
+	t.Run("lists and iterators", func(t *testing.T) {
+		for i := range 5 {
+			_, err := c.CreateTask(ctx, client.CreateTaskRequest{
+				Title:    fmt.Sprintf("page %d", i),
+				Watchers: []string{"alice"},
+			})
+			require.NoError(t, err)
+		}
+
+		page, err := c.ListTasks(ctx, client.ListTasksParams{Watcher: "alice", Limit: 2, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, "page 3", page[0].Title)
+
+		var titles []string
+		for task, err := range c.Tasks(ctx, client.ListTasksParams{Watcher: "alice", Limit: 2}) {
+			require.NoError(t, err)
+			titles = append(titles, task.Title)
+		}
+		assert.Equal(t, []string{"page 4", "page 3", "page 2", "page 1", "page 0"}, titles)
+
+		mine, err := c.ListMyTasks(ctx, client.ListTasksParams{})
+		require.NoError(t, err)
+		assert.Len(t, mine, 5)
+
+		n := 0
+		for _, err := range c.MyTasks(ctx, client.ListTasksParams{Limit: 2}) {
+			require.NoError(t, err)
+			if n++; n == 3 {
+				break
+			}
+		}
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		body, err := c.ExportTasks(ctx, client.ExportParams{
+			Format:      client.ExportCSV,
+			Columns:     []string{"title", "status"},
+			Participant: "alice",
+			Limit:       1,
+		})
+		require.NoError(t, err)
+		defer body.Close()
+
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "title,status\npage 4,todo\n", strings.TrimPrefix(string(data), "\ufeff"))
+	})
+
+	t.Run("imports", func(t *testing.T) {
+		csv := "title,status\nImported,done\n"
+
+		result, err := c.ImportTasks(ctx, strings.NewReader(csv), client.ImportParams{Format: client.ImportCSV, DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, client.ImportResult{DryRun: true, Rows: 1, Errors: []client.ImportRowError{}}, *result)
+
+		result, err = c.ImportTasks(ctx, io.MultiReader(strings.NewReader(csv)), client.ImportParams{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Created)
+
+		jsonl := `{"Summary":"Mapped"}` + "\n" + `{"Summary":""}` + "\n"
+		result, err = c.ImportTasks(ctx, strings.NewReader(jsonl), client.ImportParams{
+			Format:  client.ImportJSONL,
+			Mapping: map[string]string{"title": "Summary"},
+		})
+		require.NoError(t, err, "invalid rows are a result, not an error")
+		assert.Equal(t, 1, result.Invalid)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, 2, result.Errors[0].Line)
+
+		job, err := c.StartImport(ctx, strings.NewReader(csv), client.ImportParams{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, job.ID)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		job, err = c.WaitImportJob(waitCtx, job.ID, 10*time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, client.ImportSucceeded, job.Status)
+		assert.Equal(t, 1, job.Result.Created)
+		assert.NotNil(t, job.FinishedAt)
+
+		_, err = c.GetImportJob(ctx, "missing")
+		assert.True(t, client.IsNotFound(err), "got %v", err)
+	})
+
+	t.Run("webhooks", func(t *testing.T) {
+		hook, err := c.CreateWebhook(ctx, client.CreateWebhookRequest{
+			URL:    "https://hooks.example.com/tasks",
+			Events: []string{"task.created"},
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, hook.Secret)
+		assert.True(t, hook.Active)
+
+		got, err := c.GetWebhook(ctx, hook.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Secret)
+		assert.Equal(t, []string{"task.created"}, got.Events)
+
+		list, err := c.ListWebhooks(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, hook.ID, list[0].ID)
+
+		got, err = c.UpdateWebhook(ctx, hook.ID, client.UpdateWebhookRequest{
+			URL:    "https://hooks.example.com/v2",
+			Events: []string{"task.deleted"},
+			Active: false,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "https://hooks.example.com/v2", got.URL)
+		assert.False(t, got.Active)
+
+		code := 500
+		require.NoError(t, webhooks.Enqueue(ctx, []*domain.WebhookDelivery{{
+			ID:            "d-1",
+			WebhookID:     hook.ID,
+			EventID:       "e-1",
+			EventType:     domain.EventTaskCreated,
+			Status:        domain.DeliveryPending,
+			Attempts:      1,
+			NextAttemptAt: time.Now().UTC(),
+			ResponseCode:  &code,
+			CreatedAt:     time.Now().UTC(),
+		}}))
+
+		deliveries, err := c.ListWebhookDeliveries(ctx, hook.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, "task.created", deliveries[0].EventType)
+		assert.Equal(t, &code, deliveries[0].ResponseCode)
+
+		require.NoError(t, c.DeleteWebhook(ctx, hook.ID))
+
+		_, err = c.GetWebhook(ctx, hook.ID)
+		assert.True(t, client.IsNotFound(err), "got %v", err)
+	})
+
+	assert.Empty(t, uncovered(engine, rec), "routes without a contract test")
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+type ImportFormat string
+
+const (
+	ImportCSV   ImportFormat = "csv"
+	ImportJSONL ImportFormat = "jsonl"
+)
+
+func (f ImportFormat) contentType() string {
+	if f == ImportJSONL {
+		return "application/jsonl"
+	}
+	return "text/csv"
+}
+
+// ImportParams describe an import file.
+type ImportParams struct {
+	Format ImportFormat
+	// Mapping names the column or key of a field when it differs from the
+	// field name, e.g. {"title": "Summary"}.
+	Mapping map[string]string
+	// DryRun only validates the rows.
+	DryRun bool
+}
+
+func (p ImportParams) query(async bool) url.Values {
+	q := url.Values{}
+	q.Set("format", string(p.Format))
+
+	if len(p.Mapping) > 0 {
+		pairs := make([]string, 0, len(p.Mapping))
+		for field, column := range p.Mapping {
+			pairs = append(pairs, field+":"+column)
+		}
+		slices.Sort(pairs)
+		q.Set("mapping", strings.Join(pairs, ","))
+	}
+	if p.DryRun {
+		q.Set("dry_run", "true")
+	}
+	if async {
+		q.Set("async", "true")
+	}
+	return q
+}
+
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportResult struct {
+	DryRun  bool `json:"dry_run"`
+	Rows    int  `json:"rows"`
+	Invalid int  `json:"invalid"`
+	Created int  `json:"created"`
+	// Errors lists the first rejected rows; Invalid counts all of them.
+	Errors []ImportRowError `json:"errors"`
+}
+
+type ImportJobStatus string
+
+const (
+	ImportPending   ImportJobStatus = "pending"
+	ImportRunning   ImportJobStatus = "running"
+	ImportSucceeded ImportJobStatus = "succeeded"
+	ImportFailed    ImportJobStatus = "failed"
+)
+
+type ImportJob struct {
+	ID     string          `json:"id"`
+	Status ImportJobStatus `json:"status"`
+	Result ImportResult    `json:"result"`
+	// Error is why the import failed, unless rows were invalid.
+	Error      *string    `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Done reports whether the job has finished.
+func (j *ImportJob) Done() bool {
+	return j.Status == ImportSucceeded || j.Status == ImportFailed
+}
+
+// ImportTasks creates the tasks of a CSV or JSON Lines file. When a row is
+// invalid no task is created and the result lists why, without an error.
+func (c *Client) ImportTasks(ctx context.Context, file io.Reader, params ImportParams) (*ImportResult, error) {
+	req, err := importRequest(file, params, false)
+	if err != nil {
+		return nil, err
+	}
+	req.accept = []int{http.StatusUnprocessableEntity}
+
+	var result ImportResult
+	if err := c.do(ctx, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StartImport runs ImportTasks in the background; follow the job with
+// GetImportJob or WaitImportJob.
+func (c *Client) StartImport(ctx context.Context, file io.Reader, params ImportParams) (*ImportJob, error) {
+	req, err := importRequest(file, params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var job ImportJob
+	if err := c.do(ctx, req, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (c *Client) GetImportJob(ctx context.Context, id string) (*ImportJob, error) {
+	var job ImportJob
+	if err := c.do(ctx, &request{method: http.MethodGet, path: "/tasks/import/jobs/" + escape(id)}, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WaitImportJob polls the job every interval until it has finished or ctx
+// ends.
+func (c *Client) WaitImportJob(ctx context.Context, id string, interval time.Duration) (*ImportJob, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.GetImportJob(ctx, id)
+		if err != nil || job.Done() {
+			return job, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// importRequest buffers file unless it can be rewound, so retries resend it.
+func importRequest(file io.Reader, params ImportParams, async bool) (*request, error) {
+	if params.Format == "" {
+		params.Format = ImportCSV
+	}
+
+	body, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	return &request{
+		method:      http.MethodPost,
+		path:        "/tasks/import",
+		query:       params.query(async),
+		body:        body,
+		contentType: params.Format.contentType(),
+	}, nil
+}
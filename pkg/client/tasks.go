@@ -0,0 +1,244 @@
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusTodo       Status = "todo"
+	StatusInProgress Status = "in_progress"
+	StatusDone       Status = "done"
+)
+
+// Task is the TaskResponse of the API.
+type Task struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Status    Status    `json:"status"`
+	Assignees []string  `json:"assignees"`
+	Watchers  []string  `json:"watchers"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateTaskRequest struct {
+	Title string `json:"title"`
+	// Status defaults to StatusTodo.
+	Status    Status   `json:"status,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+	Watchers  []string `json:"watchers,omitempty"`
+}
+
+// ListTasksParams filters and pages the task lists, newest first. Zero
+// fields are left out; the server then returns 20 tasks.
+type ListTasksParams struct {
+	Status   Status
+	Assignee string
+	Watcher  string
+	Limit    int
+	Offset   int
+}
+
+func (p ListTasksParams) query() url.Values {
+	q := url.Values{}
+	if p.Status != "" {
+		q.Set("status", string(p.Status))
+	}
+	if p.Assignee != "" {
+		q.Set("assignee", p.Assignee)
+	}
+	if p.Watcher != "" {
+		q.Set("watcher", p.Watcher)
+	}
+	if p.Limit > 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset > 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	return q
+}
+
+func (c *Client) CreateTask(ctx context.Context, req CreateTaskRequest) (*Task, error) {
+	r, err := c.newJSONRequest(http.MethodPost, "/tasks", req)
+	if err != nil {
+		return nil, err
+	}
+
+	var task Task
+	if err := c.do(ctx, r, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetTask returns the task with id; IsNotFound reports an unknown one.
+func (c *Client) GetTask(ctx context.Context, id string) (*Task, error) {
+	return c.taskCall(ctx, http.MethodGet, "/tasks/"+escape(id), nil)
+}
+
+func (c *Client) ListTasks(ctx context.Context, params ListTasksParams) ([]Task, error) {
+	return c.listTasks(ctx, "/tasks", params)
+}
+
+// ListMyTasks lists the tasks the user of the client is assigned to or
+// watching. Assignee and Watcher of params are ignored.
+func (c *Client) ListMyTasks(ctx context.Context, params ListTasksParams) ([]Task, error) {
+	return c.listTasks(ctx, "/me/tasks", params)
+}
+
+// Tasks iterates over every task matching params, starting at its Offset
+// and fetching params.Limit tasks per request, 100 unless set. Iteration
+// stops at the first error, which is yielded with a zero Task.
+func (c *Client) Tasks(ctx context.Context, params ListTasksParams) iter.Seq2[Task, error] {
+	return c.paginate(ctx, "/tasks", params)
+}
+
+// MyTasks iterates over the tasks of the client's user like Tasks.
+func (c *Client) MyTasks(ctx context.Context, params ListTasksParams) iter.Seq2[Task, error] {
+	return c.paginate(ctx, "/me/tasks", params)
+}
+
+func (c *Client) listTasks(ctx context.Context, path string, params ListTasksParams) ([]Task, error) {
+	tasks := []Task{}
+	if err := c.do(ctx, &request{method: http.MethodGet, path: path, query: params.query()}, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+const defaultPageSize = 100
+
+func (c *Client) paginate(ctx context.Context, path string, params ListTasksParams) iter.Seq2[Task, error] {
+	if params.Limit <= 0 {
+		params.Limit = defaultPageSize
+	}
+
+	return func(yield func(Task, error) bool) {
+		for {
+			page, err := c.listTasks(ctx, path, params)
+			if err != nil {
+				yield(Task{}, err)
+				return
+			}
+
+			for _, t := range page {
+				if !yield(t, nil) {
+					return
+				}
+			}
+
+			if len(page) < params.Limit {
+				return
+			}
+			params.Offset += len(page)
+		}
+	}
+}
+
+func (c *Client) UpdateTaskStatus(ctx context.Context, id string, status Status) (*Task, error) {
+	return c.taskCall(ctx, http.MethodPatch, "/tasks/"+escape(id)+"/status", map[string]Status{"status": status})
+}
+
+func (c *Client) DeleteTask(ctx context.Context, id string) error {
+	return c.do(ctx, &request{method: http.MethodDelete, path: "/tasks/" + escape(id)}, nil)
+}
+
+func (c *Client) AddAssignee(ctx context.Context, id, user string) (*Task, error) {
+	return c.taskCall(ctx, http.MethodPost, "/tasks/"+escape(id)+"/assignees", map[string]string{"user": user})
+}
+
+func (c *Client) RemoveAssignee(ctx context.Context, id, user string) (*Task, error) {
+	return c.taskCall(ctx, http.MethodDelete, "/tasks/"+escape(id)+"/assignees/"+escape(user), nil)
+}
+
+func (c *Client) AddWatcher(ctx context.Context, id, user string) (*Task, error) {
+	return c.taskCall(ctx, http.MethodPost, "/tasks/"+escape(id)+"/watchers", map[string]string{"user": user})
+}
+
+func (c *Client) RemoveWatcher(ctx context.Context, id, user string) (*Task, error) {
+	return c.taskCall(ctx, http.MethodDelete, "/tasks/"+escape(id)+"/watchers/"+escape(user), nil)
+}
+
+// taskCall sends body, unless nil, to a route that answers with a task.
+func (c *Client) taskCall(ctx context.Context, method, path string, body any) (*Task, error) {
+	r, err := c.newJSONRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var task Task
+	if err := c.do(ctx, r, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+type ExportFormat string
+
+const (
+	ExportCSV   ExportFormat = "csv"
+	ExportJSONL ExportFormat = "jsonl"
+	ExportXLSX  ExportFormat = "xlsx"
+)
+
+// ExportParams select the tasks and layout of an export. Unlike the task
+// lists, an export without Limit holds every matching task.
+type ExportParams struct {
+	// Format defaults to ExportCSV.
+	Format ExportFormat
+	// Columns picks and orders the fields; all of them by default.
+	Columns []string
+	// Location is the time zone of timestamps, UTC by default.
+	Location    *time.Location
+	Status      Status
+	Assignee    string
+	Watcher     string
+	Participant string
+	Limit       int
+	Offset      int
+}
+
+func (p ExportParams) query() url.Values {
+	q := ListTasksParams{
+		Status:   p.Status,
+		Assignee: p.Assignee,
+		Watcher:  p.Watcher,
+		Limit:    p.Limit,
+		Offset:   p.Offset,
+	}.query()
+
+	if p.Format != "" {
+		q.Set("format", string(p.Format))
+	}
+	if len(p.Columns) > 0 {
+		q.Set("columns", strings.Join(p.Columns, ","))
+	}
+	if p.Location != nil {
+		q.Set("tz", p.Location.String())
+	}
+	if p.Participant != "" {
+		q.Set("participant", p.Participant)
+	}
+	return q
+}
+
+// ExportTasks streams an export file. The caller must close it. The
+// server cuts the stream short when it fails midway, which surfaces as an
+// error reading the body.
+func (c *Client) ExportTasks(ctx context.Context, params ExportParams) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, &request{method: http.MethodGet, path: "/tasks/export", query: params.query()})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
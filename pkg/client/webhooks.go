@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Webhook struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+	// Secret signs the deliveries; it is only returned by CreateWebhook.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL string `json:"url"`
+	// Events limits deliveries to the listed types, e.g. task.created;
+	// empty means every event.
+	Events []string `json:"events,omitempty"`
+	// Secret is generated by the server when empty.
+	Secret string `json:"secret,omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+}
+
+type WebhookDelivery struct {
+	ID            string     `json:"id"`
+	EventID       string     `json:"event_id"`
+	EventType     string     `json:"event_type"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     *string    `json:"last_error,omitempty"`
+	ResponseCode  *int       `json:"response_code,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+// The webhook routes are served with Postgres only; other servers answer
+// them with 404.
+
+func (c *Client) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*Webhook, error) {
+	return c.webhookCall(ctx, http.MethodPost, "/webhooks", req)
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	webhooks := []Webhook{}
+	if err := c.do(ctx, &request{method: http.MethodGet, path: "/webhooks"}, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
+	return c.webhookCall(ctx, http.MethodGet, "/webhooks/"+escape(id), nil)
+}
+
+// UpdateWebhook replaces the URL, events and state of a webhook.
+func (c *Client) UpdateWebhook(ctx context.Context, id string, req UpdateWebhookRequest) (*Webhook, error) {
+	return c.webhookCall(ctx, http.MethodPut, "/webhooks/"+escape(id), req)
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	return c.do(ctx, &request{method: http.MethodDelete, path: "/webhooks/" + escape(id)}, nil)
+}
+
+// ListWebhookDeliveries lists deliveries of a webhook, newest first; a zero
+// limit returns 20.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id string, limit, offset int) ([]WebhookDelivery, error) {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		q.Set("offset", strconv.Itoa(offset))
+	}
+
+	deliveries := []WebhookDelivery{}
+	req := &request{method: http.MethodGet, path: "/webhooks/" + escape(id) + "/deliveries", query: q}
+	if err := c.do(ctx, req, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (c *Client) webhookCall(ctx context.Context, method, path string, body any) (*Webhook, error) {
+	r, err := c.newJSONRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var webhook Webhook
+	if err := c.do(ctx, r, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}